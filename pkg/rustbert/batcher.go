@@ -0,0 +1,202 @@
+package rustbert
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxBatchSize is the batch size a Batcher uses when BatcherOptions.MaxBatchSize is 0.
+const DefaultMaxBatchSize = 32
+
+// BatcherOptions configures a Batcher.
+type BatcherOptions struct {
+	// MaxBatchSize is the largest number of inputs sent in one batch call.
+	// 0 means DefaultMaxBatchSize.
+	MaxBatchSize int
+	// MaxLatency is how long the first input of a batch waits for more inputs to
+	// arrive. 0 sends whatever is queued at once without waiting.
+	MaxLatency time.Duration
+}
+
+// Batcher collects single-input calls made concurrently by many goroutines and runs
+// them through one batch call, typically a model's PredictBatchContext method:
+//
+//	b := rustbert.NewBatcher(model.PredictBatchContext, rustbert.BatcherOptions{
+//		MaxBatchSize: 32,
+//		MaxLatency:   5 * time.Millisecond,
+//	})
+//	defer b.Close()
+//
+//	result, err := b.Predict(r.Context(), text) // from each HTTP handler
+//
+// Batches run one at a time; inputs arriving meanwhile make up the next batch. A
+// Batcher is safe for concurrent use.
+type Batcher[In, Out any] struct {
+	batch        func(ctx context.Context, inputs []In) ([]Out, error)
+	maxBatchSize int
+	maxLatency   time.Duration
+
+	requests chan *batchRequest[In, Out]
+	closed   chan struct{}
+	stopped  chan struct{}
+	once     sync.Once
+}
+
+type batchRequest[In, Out any] struct {
+	ctx   context.Context
+	input In
+	done  chan batchResult[Out]
+}
+
+type batchResult[Out any] struct {
+	value Out
+	err   error
+}
+
+// NewBatcher returns a Batcher sending inputs to batch, which must return one output
+// per input, in input order.
+func NewBatcher[In, Out any](batch func(ctx context.Context, inputs []In) ([]Out, error), opts BatcherOptions) *Batcher[In, Out] {
+	maxBatchSize := opts.MaxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = DefaultMaxBatchSize
+	}
+
+	b := &Batcher[In, Out]{
+		batch:        batch,
+		maxBatchSize: maxBatchSize,
+		maxLatency:   opts.MaxLatency,
+		requests:     make(chan *batchRequest[In, Out]),
+		closed:       make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Predict queues input for the next batch and waits for its output. When ctx is done
+// first, Predict returns ctx.Err() straight away; the batch call itself is cancelled
+// once every caller waiting on it has given up. Predict returns ErrClosed after Close.
+func (b *Batcher[In, Out]) Predict(ctx context.Context, input In) (Out, error) {
+	var zero Out
+	req := &batchRequest[In, Out]{
+		ctx:   ctx,
+		input: input,
+		done:  make(chan batchResult[Out], 1),
+	}
+
+	select {
+	case b.requests <- req:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case <-b.closed:
+		return zero, ErrClosed
+	}
+
+	select {
+	case r := <-req.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// Close stops accepting inputs and waits for the batch in flight, if any. It does
+// not close the underlying model. Calling Close again is a no-op.
+func (b *Batcher[In, Out]) Close() {
+	b.once.Do(func() {
+		close(b.closed)
+	})
+	<-b.stopped
+}
+
+func (b *Batcher[In, Out]) run() {
+	defer close(b.stopped)
+
+	for {
+		var first *batchRequest[In, Out]
+		select {
+		case first = <-b.requests:
+		case <-b.closed:
+			return
+		}
+		b.dispatch(b.collect(first))
+	}
+}
+
+// collect gathers requests to batch with first, until the batch is full, MaxLatency
+// has passed or, without MaxLatency, no request is immediately ready.
+func (b *Batcher[In, Out]) collect(first *batchRequest[In, Out]) []*batchRequest[In, Out] {
+	reqs := []*batchRequest[In, Out]{first}
+
+	var deadline <-chan time.Time
+	if b.maxLatency > 0 {
+		timer := time.NewTimer(b.maxLatency)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for len(reqs) < b.maxBatchSize {
+		if deadline == nil {
+			select {
+			case req := <-b.requests:
+				reqs = append(reqs, req)
+				continue
+			default:
+				return reqs
+			}
+		}
+		select {
+		case req := <-b.requests:
+			reqs = append(reqs, req)
+		case <-deadline:
+			return reqs
+		case <-b.closed:
+			return reqs
+		}
+	}
+	return reqs
+}
+
+// dispatch runs one batch call for reqs and hands each request its output. Requests
+// whose context is already done are dropped.
+func (b *Batcher[In, Out]) dispatch(reqs []*batchRequest[In, Out]) {
+	live := reqs[:0]
+	for _, req := range reqs {
+		if req.ctx.Err() == nil {
+			live = append(live, req)
+		}
+	}
+	if len(live) == 0 {
+		return
+	}
+
+	// Cancel the batch once all of its callers are gone
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	remaining := int32(len(live))
+	inputs := make([]In, len(live))
+	for i, req := range live {
+		inputs[i] = req.input
+		stop := context.AfterFunc(req.ctx, func() {
+			if atomic.AddInt32(&remaining, -1) == 0 {
+				cancel()
+			}
+		})
+		defer stop()
+	}
+
+	outputs, err := b.batch(ctx, inputs)
+	if err == nil && len(outputs) != len(inputs) {
+		err = fmt.Errorf("rustbert: batch returned %d outputs for %d inputs", len(outputs), len(inputs))
+	}
+	for i, req := range live {
+		if err != nil {
+			req.done <- batchResult[Out]{err: err}
+		} else {
+			req.done <- batchResult[Out]{value: outputs[i]}
+		}
+	}
+}
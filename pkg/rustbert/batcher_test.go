@@ -0,0 +1,117 @@
+package rustbert
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatcher(t *testing.T) {
+	var mu sync.Mutex
+	var sizes []int
+	upper := func(ctx context.Context, inputs []string) ([]string, error) {
+		mu.Lock()
+		sizes = append(sizes, len(inputs))
+		mu.Unlock()
+		outputs := make([]string, len(inputs))
+		for i, input := range inputs {
+			outputs[i] = strings.ToUpper(input)
+		}
+		return outputs, nil
+	}
+
+	b := NewBatcher(upper, BatcherOptions{MaxBatchSize: 4, MaxLatency: 20 * time.Millisecond})
+	defer b.Close()
+
+	inputs := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	var wg sync.WaitGroup
+	for _, input := range inputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := b.Predict(context.Background(), input)
+			if err != nil || got != strings.ToUpper(input) {
+				t.Errorf("Predict(%q) = %q, %v; want %q", input, got, err, strings.ToUpper(input))
+			}
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	total := 0
+	for _, size := range sizes {
+		if size > 4 {
+			t.Errorf("batch of %d inputs exceeds MaxBatchSize", size)
+		}
+		total += size
+	}
+	if total != len(inputs) {
+		t.Errorf("batched %d inputs, want %d", total, len(inputs))
+	}
+	if len(sizes) >= len(inputs) {
+		t.Errorf("%d batch calls for %d concurrent inputs, want fewer", len(sizes), len(inputs))
+	}
+}
+
+func TestBatcherErrors(t *testing.T) {
+	failing := func(ctx context.Context, inputs []int) ([]int, error) {
+		return nil, ErrInference
+	}
+	b := NewBatcher(failing, BatcherOptions{})
+	if _, err := b.Predict(context.Background(), 1); !errors.Is(err, ErrInference) {
+		t.Errorf("Predict with failing batch: got %v, want ErrInference", err)
+	}
+	b.Close()
+	if _, err := b.Predict(context.Background(), 1); !errors.Is(err, ErrClosed) {
+		t.Errorf("Predict after Close: got %v, want ErrClosed", err)
+	}
+	b.Close()
+
+	short := func(ctx context.Context, inputs []int) ([]int, error) {
+		return inputs[:len(inputs)-1], nil
+	}
+	b = NewBatcher(short, BatcherOptions{})
+	defer b.Close()
+	if _, err := b.Predict(context.Background(), 1); err == nil {
+		t.Error("Predict with a batch returning too few outputs succeeded")
+	}
+}
+
+func TestBatcherCancellation(t *testing.T) {
+	started := make(chan struct{})
+	slow := func(ctx context.Context, inputs []int) ([]int, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	b := NewBatcher(slow, BatcherOptions{})
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() {
+		_, err := b.Predict(ctx, 1)
+		errc <- err
+	}()
+	<-started
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("Predict with cancelled context: got %v, want context.Canceled", err)
+	}
+
+	// The batch call was cancelled with its only caller, so Close does not hang
+	done := make(chan struct{})
+	go func() {
+		b.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("batch call was not cancelled after its caller gave up")
+	}
+}
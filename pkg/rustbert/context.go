@@ -0,0 +1,110 @@
+package rustbert
+
+/*
+typedef struct CancelToken CancelToken;
+
+typedef CancelToken* (*new_cancel_token_t)();
+typedef void (*cancel_token_cancel_t)(CancelToken*);
+typedef void (*free_cancel_token_t)(CancelToken*);
+
+CancelToken* call_new_cancel_token(void* f) {
+    return ((new_cancel_token_t)f)();
+}
+
+void call_cancel_token_cancel(void* f, CancelToken* t) {
+    ((cancel_token_cancel_t)f)(t);
+}
+
+void call_free_cancel_token(void* f, CancelToken* t) {
+    ((free_cancel_token_t)f)(t);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"errors"
+	"unsafe"
+)
+
+var (
+	fnNewCancelToken    unsafe.Pointer
+	fnCancelTokenCancel unsafe.Pointer
+	fnFreeCancelToken   unsafe.Pointer
+)
+
+// withContext runs call with the native pointer of h and a token to hand to a
+// cancellable binding function.
+//
+// A context that can never be done runs call inline with a NULL token. Otherwise call
+// runs on its own goroutine: if ctx is done first, the token is cancelled and ctx.Err()
+// returned straight away. The binding checks the token before each batch it runs and
+// every few decoding steps of greedy and sampled text generation, so the abandoned
+// native call stops shortly after; the goroutine holds h until it has, which keeps
+// Close from freeing the model underneath it. Beam search, T5 text generation,
+// summarization, translation and conversation cannot resume a partial output: once
+// they start generating a batch they run it to completion.
+func withContext[M, T any](ctx context.Context, h *modelHandle[M], call func(ptr *M, cancel *C.CancelToken) (T, error)) (T, error) {
+	var zero T
+	if ctx.Done() == nil {
+		return withModel(h, func(ptr *M) (T, error) {
+			return call(ptr, nil)
+		})
+	}
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	token := C.call_new_cancel_token(fnNewCancelToken)
+	done := make(chan result, 1)
+
+	go func() {
+		value, err := withModel(h, func(ptr *M) (T, error) {
+			return call(ptr, token)
+		})
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		C.call_free_cancel_token(fnFreeCancelToken, token)
+		if isCancelled(r.err) {
+			return zero, ctx.Err()
+		}
+		return r.value, r.err
+	case <-ctx.Done():
+		C.call_cancel_token_cancel(fnCancelTokenCancel, token)
+		go func() {
+			<-done
+			C.call_free_cancel_token(fnFreeCancelToken, token)
+		}()
+		return zero, ctx.Err()
+	}
+}
+
+// cancelOnDone returns a token that is cancelled once ctx is done, for a native call
+// made on the calling goroutine, and the function freeing it once the call has
+// returned.
+func cancelOnDone(ctx context.Context) (token *C.CancelToken, release func()) {
+	token = C.call_new_cancel_token(fnNewCancelToken)
+	cancelled := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(cancelled)
+		C.call_cancel_token_cancel(fnCancelTokenCancel, token)
+	})
+	return token, func() {
+		if !stop() {
+			<-cancelled
+		}
+		C.call_free_cancel_token(fnFreeCancelToken, token)
+	}
+}
+
+func isCancelled(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == ErrorCodeCancelled
+}
@@ -0,0 +1,451 @@
+package rustbert
+
+/*
+#include <stdbool.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef struct CancelToken CancelToken;
+
+typedef struct {
+    void* model;
+    int64_t seed;
+} ConversationModelWrapper;
+
+typedef struct {
+    void* manager;
+} ConversationManagerWrapper;
+
+typedef struct {
+    int64_t max_length;
+    int64_t min_length;
+    bool do_sample;
+    bool early_stopping;
+    double temperature;
+    int64_t top_k;
+    double top_p;
+    double repetition_penalty;
+    int64_t no_repeat_ngram_size;
+    int64_t num_beams;
+    int64_t num_return_sequences;
+    double length_penalty;
+    int64_t seed;
+} GenerateOptions;
+
+typedef struct {
+    char** items;
+    size_t count;
+} StringArray;
+
+typedef struct {
+    char** user_inputs;
+    char** responses;
+    size_t count;
+    char* pending_input;
+} ConversationHistory;
+
+typedef struct {
+    char* id;
+    char* text;
+} ConversationResponse;
+
+typedef struct {
+    ConversationResponse* responses;
+    size_t count;
+} ConversationResponses;
+
+typedef ConversationModelWrapper* (*new_conversation_model_t)(int);
+typedef ConversationModelWrapper* (*new_conversation_model_with_options_t)(const GenerateOptions*, int);
+typedef ConversationResponses* (*generate_responses_t)(ConversationModelWrapper*, ConversationManagerWrapper*, CancelToken*);
+typedef void (*free_conversation_model_t)(ConversationModelWrapper*);
+typedef void (*free_conversation_responses_t)(ConversationResponses*);
+typedef ConversationManagerWrapper* (*new_conversation_manager_t)();
+typedef char* (*conversation_create_t)(ConversationManagerWrapper*, const char*);
+typedef bool (*conversation_add_user_input_t)(ConversationManagerWrapper*, const char*, const char*);
+typedef bool (*conversation_remove_t)(ConversationManagerWrapper*, const char*);
+typedef StringArray* (*conversation_ids_t)(ConversationManagerWrapper*);
+typedef ConversationHistory* (*conversation_history_t)(ConversationManagerWrapper*, const char*);
+typedef bool (*conversation_trim_t)(ConversationManagerWrapper*, const char*, size_t);
+typedef void (*free_conversation_manager_t)(ConversationManagerWrapper*);
+typedef void (*free_conversation_history_t)(ConversationHistory*);
+typedef void (*free_conversation_string_array_t)(StringArray*);
+
+ConversationModelWrapper* call_new_conversation_model(void* f, int device) {
+    return ((new_conversation_model_t)f)(device);
+}
+
+ConversationModelWrapper* call_new_conversation_model_with_options(void* f, const GenerateOptions* o, int device) {
+    return ((new_conversation_model_with_options_t)f)(o, device);
+}
+
+ConversationResponses* call_generate_responses(
+    void* f,
+    ConversationModelWrapper* w,
+    ConversationManagerWrapper* m,
+    CancelToken* cancel
+) {
+    return ((generate_responses_t)f)(w, m, cancel);
+}
+
+void call_free_conversation_model(void* f, ConversationModelWrapper* w) {
+    ((free_conversation_model_t)f)(w);
+}
+
+void call_free_conversation_responses(void* f, ConversationResponses* r) {
+    ((free_conversation_responses_t)f)(r);
+}
+
+ConversationManagerWrapper* call_new_conversation_manager(void* f) {
+    return ((new_conversation_manager_t)f)();
+}
+
+char* call_conversation_create(void* f, ConversationManagerWrapper* m, const char* text) {
+    return ((conversation_create_t)f)(m, text);
+}
+
+bool call_conversation_add_user_input(void* f, ConversationManagerWrapper* m, const char* id, const char* text) {
+    return ((conversation_add_user_input_t)f)(m, id, text);
+}
+
+bool call_conversation_remove(void* f, ConversationManagerWrapper* m, const char* id) {
+    return ((conversation_remove_t)f)(m, id);
+}
+
+StringArray* call_conversation_ids(void* f, ConversationManagerWrapper* m) {
+    return ((conversation_ids_t)f)(m);
+}
+
+ConversationHistory* call_conversation_history(void* f, ConversationManagerWrapper* m, const char* id) {
+    return ((conversation_history_t)f)(m, id);
+}
+
+bool call_conversation_trim(void* f, ConversationManagerWrapper* m, const char* id, size_t turns) {
+    return ((conversation_trim_t)f)(m, id, turns);
+}
+
+void call_free_conversation_manager(void* f, ConversationManagerWrapper* m) {
+    ((free_conversation_manager_t)f)(m);
+}
+
+void call_free_conversation_history(void* f, ConversationHistory* h) {
+    ((free_conversation_history_t)f)(h);
+}
+
+void call_free_conversation_string_array(void* f, StringArray* a) {
+    ((free_conversation_string_array_t)f)(a);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+var (
+	fnNewConversationModel            unsafe.Pointer
+	fnNewConversationModelWithOptions unsafe.Pointer
+	fnGenerateResponses               unsafe.Pointer
+	fnFreeConversationModel           unsafe.Pointer
+	fnFreeConversationResponses       unsafe.Pointer
+	fnNewConversationManager          unsafe.Pointer
+	fnConversationCreate              unsafe.Pointer
+	fnConversationAddUserInput        unsafe.Pointer
+	fnConversationRemove              unsafe.Pointer
+	fnConversationIDs                 unsafe.Pointer
+	fnConversationHistory             unsafe.Pointer
+	fnConversationTrim                unsafe.Pointer
+	fnFreeConversationManager         unsafe.Pointer
+	fnFreeConversationHistory         unsafe.Pointer
+)
+
+// DefaultConversationOptions returns the options NewConversationModel uses, which
+// match rust-bert's ConversationConfig defaults.
+func DefaultConversationOptions() GenerateOptions {
+	return GenerateOptions{
+		MaxLength:          1000,
+		MinLength:          0,
+		DoSample:           true,
+		EarlyStopping:      false,
+		Temperature:        1.0,
+		TopK:               50,
+		TopP:               0.9,
+		RepetitionPenalty:  1.0,
+		NoRepeatNgramSize:  0,
+		NumBeams:           1,
+		NumReturnSequences: 1,
+		LengthPenalty:      1.0,
+		Seed:               -1,
+	}
+}
+
+// ConversationModel generates the responses of a chatbot (DialoGPT) to the
+// conversations of a ConversationManager.
+type ConversationModel struct {
+	handle modelHandle[C.ConversationModelWrapper]
+}
+
+// NewConversationModel creates a ConversationModel with DialoGPT medium.
+func NewConversationModel() (*ConversationModel, error) {
+	return NewConversationModelOnDevice(DeviceAuto)
+}
+
+// NewConversationModelOnDevice is like NewConversationModel but loads the model on device.
+func NewConversationModelOnDevice(device Device) (*ConversationModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_conversation_model(fnNewConversationModel, C.int(device))
+	if ptr == nil {
+		return nil, lastError("NewConversationModel")
+	}
+	return &ConversationModel{handle: modelHandle[C.ConversationModelWrapper]{ptr: ptr}}, nil
+}
+
+// NewConversationModelWithOptions creates a ConversationModel with DialoGPT medium
+// responding with opts. Start from DefaultConversationOptions. Invalid options are
+// rejected with ErrInvalidInput, as is NumReturnSequences other than 1.
+func NewConversationModelWithOptions(opts GenerateOptions) (*ConversationModel, error) {
+	return NewConversationModelWithOptionsOnDevice(opts, DeviceAuto)
+}
+
+// NewConversationModelWithOptionsOnDevice is like NewConversationModelWithOptions but
+// loads the model on device.
+func NewConversationModelWithOptionsOnDevice(opts GenerateOptions, device Device) (*ConversationModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+
+	cOpts := opts.toC()
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_conversation_model_with_options(fnNewConversationModelWithOptions, &cOpts, C.int(device))
+	if ptr == nil {
+		return nil, lastError("NewConversationModelWithOptions")
+	}
+	return &ConversationModel{handle: modelHandle[C.ConversationModelWrapper]{ptr: ptr}}, nil
+}
+
+// GenerateResponses answers, in a single batch, every conversation of mgr with a
+// pending user input and records the responses in their history. It returns the
+// responses keyed by conversation id; conversations without a pending input are left
+// untouched and absent from the result.
+func (m *ConversationModel) GenerateResponses(mgr *ConversationManager) (map[string]string, error) {
+	return m.GenerateResponsesContext(context.Background(), mgr)
+}
+
+// GenerateResponsesContext is like GenerateResponses but returns ctx.Err() as soon as
+// ctx is done. Generation runs as a single native call that cannot be interrupted: a
+// round already started completes in the background, holding the model until it has,
+// and its responses are still recorded in mgr.
+func (m *ConversationModel) GenerateResponsesContext(ctx context.Context, mgr *ConversationManager) (map[string]string, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+
+	return withContext(ctx, &m.handle, func(ptr *C.ConversationModelWrapper, cancel *C.CancelToken) (map[string]string, error) {
+		return withModel(&mgr.handle, func(mgrPtr *C.ConversationManagerWrapper) (map[string]string, error) {
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+
+			res := C.call_generate_responses(fnGenerateResponses, ptr, mgrPtr, cancel)
+			if res == nil {
+				return nil, lastError("ConversationModel.GenerateResponses")
+			}
+			defer C.call_free_conversation_responses(fnFreeConversationResponses, res)
+
+			cResponses := unsafe.Slice(res.responses, int(res.count))
+			responses := make(map[string]string, len(cResponses))
+			for _, r := range cResponses {
+				responses[C.GoString(r.id)] = C.GoString(r.text)
+			}
+			return responses, nil
+		})
+	})
+}
+
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
+func (m *ConversationModel) Close() {
+	m.handle.close(func(ptr *C.ConversationModelWrapper) {
+		C.call_free_conversation_model(fnFreeConversationModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *ConversationModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
+}
+
+// ConversationTurn is a user input and the response generated for it.
+type ConversationTurn struct {
+	User     string
+	Response string
+}
+
+// Conversation is a snapshot of the history of a conversation.
+type Conversation struct {
+	ID string
+	// Turns are the answered exchanges, oldest first.
+	Turns []ConversationTurn
+	// PendingInput is the user input awaiting a response, "" if there is none.
+	PendingInput string
+}
+
+// ConversationManager holds conversations, identified by UUID strings, between calls
+// to ConversationModel.GenerateResponses. It is safe for concurrent use.
+type ConversationManager struct {
+	handle modelHandle[C.ConversationManagerWrapper]
+}
+
+// NewConversationManager creates a ConversationManager without conversations.
+func NewConversationManager() (*ConversationManager, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_conversation_manager(fnNewConversationManager)
+	if ptr == nil {
+		return nil, lastError("NewConversationManager")
+	}
+	return &ConversationManager{handle: modelHandle[C.ConversationManagerWrapper]{ptr: ptr, auxiliary: true}}, nil
+}
+
+// Create starts a conversation with text as its first user input and returns its id.
+func (c *ConversationManager) Create(text string) (string, error) {
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+	return c.create("ConversationManager.Create", cText)
+}
+
+// CreateEmpty starts a conversation without user input and returns its id.
+func (c *ConversationManager) CreateEmpty() (string, error) {
+	return c.create("ConversationManager.CreateEmpty", nil)
+}
+
+func (c *ConversationManager) create(op string, cText *C.char) (string, error) {
+	return withModel(&c.handle, func(ptr *C.ConversationManagerWrapper) (string, error) {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		cID := C.call_conversation_create(fnConversationCreate, ptr, cText)
+		if cID == nil {
+			return "", lastError(op)
+		}
+		defer freeString(cID)
+		return C.GoString(cID), nil
+	})
+}
+
+// AddUserInput appends a user input to the conversation with the given id, to be
+// answered by the next GenerateResponses. It fails with ErrInvalidInput if the id is
+// unknown or the previous input has not been answered yet.
+func (c *ConversationManager) AddUserInput(id, text string) error {
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+	return c.update("ConversationManager.AddUserInput", id, func(ptr *C.ConversationManagerWrapper, cID *C.char) C.bool {
+		return C.call_conversation_add_user_input(fnConversationAddUserInput, ptr, cID, cText)
+	})
+}
+
+// Trim keeps only the last turns exchanges of the conversation with the given id, which
+// also bounds the context the model is prompted with. A pending input is kept.
+func (c *ConversationManager) Trim(id string, turns int) error {
+	if turns < 0 {
+		return invalidInput("ConversationManager.Trim", fmt.Sprintf("turns cannot be negative, got %d", turns))
+	}
+	return c.update("ConversationManager.Trim", id, func(ptr *C.ConversationManagerWrapper, cID *C.char) C.bool {
+		return C.call_conversation_trim(fnConversationTrim, ptr, cID, C.size_t(turns))
+	})
+}
+
+// Remove deletes the conversation with the given id.
+func (c *ConversationManager) Remove(id string) error {
+	return c.update("ConversationManager.Remove", id, func(ptr *C.ConversationManagerWrapper, cID *C.char) C.bool {
+		return C.call_conversation_remove(fnConversationRemove, ptr, cID)
+	})
+}
+
+func (c *ConversationManager) update(op, id string, call func(ptr *C.ConversationManagerWrapper, cID *C.char) C.bool) error {
+	cID := C.CString(id)
+	defer C.free(unsafe.Pointer(cID))
+
+	_, err := withModel(&c.handle, func(ptr *C.ConversationManagerWrapper) (struct{}, error) {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		if !call(ptr, cID) {
+			return struct{}{}, lastError(op)
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// IDs returns the ids of the conversations of c, in no particular order.
+func (c *ConversationManager) IDs() ([]string, error) {
+	return withModel(&c.handle, func(ptr *C.ConversationManagerWrapper) ([]string, error) {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_conversation_ids(fnConversationIDs, ptr)
+		if res == nil {
+			return nil, lastError("ConversationManager.IDs")
+		}
+		defer C.call_free_conversation_string_array(fnFreeStringArray, res)
+
+		return goStrings(res.items, res.count), nil
+	})
+}
+
+// Get returns the history of the conversation with the given id. It fails with
+// ErrInvalidInput if the id is unknown.
+func (c *ConversationManager) Get(id string) (Conversation, error) {
+	cID := C.CString(id)
+	defer C.free(unsafe.Pointer(cID))
+
+	return withModel(&c.handle, func(ptr *C.ConversationManagerWrapper) (Conversation, error) {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_conversation_history(fnConversationHistory, ptr, cID)
+		if res == nil {
+			return Conversation{}, lastError("ConversationManager.Get")
+		}
+		defer C.call_free_conversation_history(fnFreeConversationHistory, res)
+
+		userInputs := unsafe.Slice(res.user_inputs, int(res.count))
+		responses := unsafe.Slice(res.responses, int(res.count))
+		conv := Conversation{ID: id, Turns: make([]ConversationTurn, len(userInputs))}
+		for i := range userInputs {
+			conv.Turns[i] = ConversationTurn{
+				User:     C.GoString(userInputs[i]),
+				Response: C.GoString(responses[i]),
+			}
+		}
+		if res.pending_input != nil {
+			conv.PendingInput = C.GoString(res.pending_input)
+		}
+		return conv, nil
+	})
+}
+
+// Close frees the conversations once in-flight calls have returned. Later calls
+// return ErrClosed.
+func (c *ConversationManager) Close() {
+	c.handle.close(func(ptr *C.ConversationManagerWrapper) {
+		C.call_free_conversation_manager(fnFreeConversationManager, ptr)
+	})
+}
@@ -0,0 +1,104 @@
+package rustbert
+
+/*
+#include <stdbool.h>
+#include <stdint.h>
+
+typedef bool (*set_default_device_t)(int);
+typedef int64_t (*cuda_device_count_t)(void);
+typedef bool (*mps_available_t)(void);
+
+bool call_set_default_device(void* f, int code) {
+    return ((set_default_device_t)f)(code);
+}
+
+int64_t call_cuda_device_count(void* f) {
+    return ((cuda_device_count_t)f)();
+}
+
+bool call_mps_available(void* f) {
+    return ((mps_available_t)f)();
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+var (
+	fnSetDefaultDevice unsafe.Pointer
+	fnCudaDeviceCount  unsafe.Pointer
+	fnMPSAvailable     unsafe.Pointer
+)
+
+// Device selects the hardware a model runs on. The zero value is DeviceAuto; CUDA
+// devices are obtained with DeviceCUDA.
+//
+// Every constructor has an *OnDevice variant taking the device as its last argument,
+// except those taking a configuration, which read its Device field, and
+// TranslationModelBuilder, which has WithDevice. The plain constructors load their
+// model on DeviceAuto. Per-call options never carry a device: a loaded model stays
+// where it is. A device that is not present is reported as ErrInvalidInput instead of
+// falling back.
+type Device int
+
+const (
+	// DeviceAuto runs on InitOptions.Device if set, and otherwise on the first CUDA
+	// device when one is available and on the CPU when not.
+	DeviceAuto Device = 0
+	// DeviceCPU runs on the CPU, even when a GPU is visible.
+	DeviceCPU Device = -1
+	// DeviceMPS runs on the Metal Performance Shaders backend of Apple silicon.
+	DeviceMPS Device = -2
+)
+
+// DeviceCUDA returns the CUDA device with the given index.
+func DeviceCUDA(index int) Device {
+	return Device(index + 1)
+}
+
+// String returns "auto", "cpu", "mps" or "cuda:<index>".
+func (d Device) String() string {
+	switch {
+	case d == DeviceAuto:
+		return "auto"
+	case d == DeviceCPU:
+		return "cpu"
+	case d == DeviceMPS:
+		return "mps"
+	case d > 0:
+		return fmt.Sprintf("cuda:%d", int(d)-1)
+	default:
+		return fmt.Sprintf("Device(%d)", int(d))
+	}
+}
+
+// AvailableDevices lists the devices models can run on: the CPU first, then every CUDA
+// device and MPS when present.
+func AvailableDevices() ([]Device, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+	devices := []Device{DeviceCPU}
+	for i := 0; i < int(C.call_cuda_device_count(fnCudaDeviceCount)); i++ {
+		devices = append(devices, DeviceCUDA(i))
+	}
+	if C.call_mps_available(fnMPSAvailable) {
+		devices = append(devices, DeviceMPS)
+	}
+	return devices, nil
+}
+
+// setDefaultDevice sets the device of the models built on DeviceAuto.
+func setDefaultDevice(device Device) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if !C.call_set_default_device(fnSetDefaultDevice, C.int(device)) {
+		return lastError("InitOptions.Device")
+	}
+	return nil
+}
@@ -12,7 +12,8 @@ import (
 // repoID: e.g. "distilbert-base-uncased-finetuned-sst-2-english"
 // cacheDir: directory to store the model. If empty, uses ~/.cache/rustbert
 //
-// Returns paths to: model, config, vocab, merges (optional)
+// Returns paths to: model, config, vocab, merges (optional).
+// tokenizer_config.json is fetched alongside when the repository provides one.
 func DownloadArtifacts(repoID, cacheDir string) (string, string, string, string, error) {
 	if cacheDir == "" {
 		home, err := os.UserHomeDir()
@@ -60,5 +61,9 @@ func DownloadArtifacts(repoID, cacheDir string) (string, string, string, string,
 		mergesPath = mp
 	}
 
+	// Download tokenizer config (optional). It lands next to the vocab file, where the
+	// *FromFiles constructors look for do_lower_case and related tokenizer flags.
+	_, _ = repo.DownloadFile("tokenizer_config.json")
+
 	return modelPath, configPath, vocabPath, mergesPath, nil
 }
@@ -0,0 +1,255 @@
+package rustbert
+
+/*
+#include <stdbool.h>
+#include <stdlib.h>
+
+typedef struct CancelToken CancelToken;
+
+typedef struct {
+    void* model;
+} SentenceEmbeddingsModelWrapper;
+
+typedef struct {
+    int pooling;
+    bool normalize;
+    int device;
+} EmbeddingsOptions;
+
+typedef struct {
+    float* data;
+    size_t count;
+    size_t dim;
+} EmbeddingsResult;
+
+typedef SentenceEmbeddingsModelWrapper* (*new_sentence_embeddings_model_t)(int, const EmbeddingsOptions*);
+typedef SentenceEmbeddingsModelWrapper* (*new_sentence_embeddings_model_from_dir_t)(const char*, const EmbeddingsOptions*);
+typedef EmbeddingsResult* (*encode_sentences_t)(SentenceEmbeddingsModelWrapper*, const char**, size_t, CancelToken*);
+typedef void (*free_sentence_embeddings_model_t)(SentenceEmbeddingsModelWrapper*);
+typedef void (*free_embeddings_result_t)(EmbeddingsResult*);
+
+SentenceEmbeddingsModelWrapper* call_new_sentence_embeddings_model(void* f, int kind, const EmbeddingsOptions* o) {
+    return ((new_sentence_embeddings_model_t)f)(kind, o);
+}
+
+SentenceEmbeddingsModelWrapper* call_new_sentence_embeddings_model_from_dir(void* f, const char* dir, const EmbeddingsOptions* o) {
+    return ((new_sentence_embeddings_model_from_dir_t)f)(dir, o);
+}
+
+EmbeddingsResult* call_encode_sentences(
+    void* f,
+    SentenceEmbeddingsModelWrapper* w,
+    const char** texts,
+    size_t count,
+    CancelToken* cancel
+) {
+    return ((encode_sentences_t)f)(w, texts, count, cancel);
+}
+
+void call_free_sentence_embeddings_model(void* f, SentenceEmbeddingsModelWrapper* w) {
+    ((free_sentence_embeddings_model_t)f)(w);
+}
+
+void call_free_embeddings_result(void* f, EmbeddingsResult* r) {
+    ((free_embeddings_result_t)f)(r);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"runtime"
+	"unsafe"
+)
+
+var (
+	fnNewSentenceEmbeddingsModel        unsafe.Pointer
+	fnNewSentenceEmbeddingsModelFromDir unsafe.Pointer
+	fnEncodeSentences                   unsafe.Pointer
+	fnFreeSentenceEmbeddingsModel       unsafe.Pointer
+	fnFreeEmbeddingsResult              unsafe.Pointer
+)
+
+// SentenceEmbeddingsModelKind selects a pretrained sentence-transformers model.
+type SentenceEmbeddingsModelKind int
+
+const (
+	// SentenceEmbeddingsAllMiniLmL12V2 is all-MiniLM-L12-v2 (384 dimensions, the default).
+	SentenceEmbeddingsAllMiniLmL12V2 SentenceEmbeddingsModelKind = iota
+	// SentenceEmbeddingsAllMiniLmL6V2 is all-MiniLM-L6-v2 (384 dimensions), smaller and faster.
+	SentenceEmbeddingsAllMiniLmL6V2
+	// SentenceEmbeddingsAllDistilrobertaV1 is all-distilroberta-v1 (768 dimensions).
+	SentenceEmbeddingsAllDistilrobertaV1
+	// SentenceEmbeddingsBertBaseNliMeanTokens is bert-base-nli-mean-tokens (768 dimensions).
+	SentenceEmbeddingsBertBaseNliMeanTokens
+	// SentenceEmbeddingsDistiluseBaseMultilingualCased is distiluse-base-multilingual-cased
+	// (512 dimensions), covering 15 languages.
+	SentenceEmbeddingsDistiluseBaseMultilingualCased
+	// SentenceEmbeddingsParaphraseAlbertSmallV2 is paraphrase-albert-small-v2 (768 dimensions).
+	SentenceEmbeddingsParaphraseAlbertSmallV2
+	// SentenceEmbeddingsSentenceT5Base is sentence-t5-base (768 dimensions).
+	SentenceEmbeddingsSentenceT5Base
+)
+
+// Pooling selects how token embeddings are combined into a sentence embedding.
+type Pooling int
+
+const (
+	// PoolingDefault keeps the pooling the model was trained with.
+	PoolingDefault Pooling = iota
+	// PoolingMean averages the token embeddings.
+	PoolingMean
+	// PoolingCLS uses the embedding of the first ([CLS]) token.
+	PoolingCLS
+	// PoolingMax takes the maximum over the token embeddings, per dimension.
+	PoolingMax
+	// PoolingMeanSqrtLen sums the token embeddings and divides by the square root of
+	// the number of tokens.
+	PoolingMeanSqrtLen
+)
+
+// SentenceEmbeddingsConfig configures a SentenceEmbeddingsModel. The zero value loads
+// the default model with its own pooling, unnormalized, on DeviceAuto.
+type SentenceEmbeddingsConfig struct {
+	// Model is the pretrained model to load. It is ignored by
+	// NewSentenceEmbeddingsModelFromDir.
+	Model SentenceEmbeddingsModelKind
+	// Pooling overrides the pooling of the model.
+	Pooling Pooling
+	// Normalize scales every embedding to unit L2 norm, so that cosine similarity is a
+	// plain dot product.
+	Normalize bool
+	// Device is the hardware the model runs on.
+	Device Device
+}
+
+// DefaultSentenceEmbeddingsConfig returns the configuration of
+// SentenceEmbeddingsAllMiniLmL12V2 with normalized embeddings.
+func DefaultSentenceEmbeddingsConfig() SentenceEmbeddingsConfig {
+	return SentenceEmbeddingsConfig{
+		Model:     SentenceEmbeddingsAllMiniLmL12V2,
+		Pooling:   PoolingDefault,
+		Normalize: true,
+		Device:    DeviceAuto,
+	}
+}
+
+func (c SentenceEmbeddingsConfig) toC() C.EmbeddingsOptions {
+	return C.EmbeddingsOptions{
+		pooling:   C.int(c.Pooling),
+		normalize: C.bool(c.Normalize),
+		device:    C.int(c.Device),
+	}
+}
+
+// SentenceEmbeddingsModel turns sentences into fixed size vectors for semantic search,
+// clustering and similarity.
+type SentenceEmbeddingsModel struct {
+	handle modelHandle[C.SentenceEmbeddingsModelWrapper]
+}
+
+// NewSentenceEmbeddingsModel downloads (if needed) and loads the pretrained model
+// selected by cfg.Model.
+func NewSentenceEmbeddingsModel(cfg SentenceEmbeddingsConfig) (*SentenceEmbeddingsModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+
+	cOpts := cfg.toC()
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_sentence_embeddings_model(fnNewSentenceEmbeddingsModel, C.int(cfg.Model), &cOpts)
+	if ptr == nil {
+		return nil, lastError("NewSentenceEmbeddingsModel")
+	}
+	return &SentenceEmbeddingsModel{handle: modelHandle[C.SentenceEmbeddingsModelWrapper]{ptr: ptr}}, nil
+}
+
+// NewSentenceEmbeddingsModelFromDir loads a sentence-transformers model from a local
+// directory holding modules.json, the transformer config, vocabulary and weights
+// converted to rust_model.ot. cfg.Model is ignored.
+func NewSentenceEmbeddingsModelFromDir(dir string, cfg SentenceEmbeddingsConfig) (*SentenceEmbeddingsModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return nil, invalidInput("NewSentenceEmbeddingsModelFromDir", "model directory cannot be empty")
+	}
+
+	cDir := C.CString(dir)
+	defer C.free(unsafe.Pointer(cDir))
+	cOpts := cfg.toC()
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_sentence_embeddings_model_from_dir(fnNewSentenceEmbeddingsModelFromDir, cDir, &cOpts)
+	if ptr == nil {
+		return nil, lastError("NewSentenceEmbeddingsModelFromDir")
+	}
+	return &SentenceEmbeddingsModel{handle: modelHandle[C.SentenceEmbeddingsModelWrapper]{ptr: ptr}}, nil
+}
+
+// Encode returns one embedding per text, at the index of the text. The embeddings
+// share a single backing array.
+func (m *SentenceEmbeddingsModel) Encode(texts []string) ([][]float32, error) {
+	return m.EncodeContext(context.Background(), texts)
+}
+
+// EncodeContext is like Encode but stops once ctx is done, returning ctx.Err().
+func (m *SentenceEmbeddingsModel) EncodeContext(ctx context.Context, texts []string) ([][]float32, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	return withContext(ctx, &m.handle, func(ptr *C.SentenceEmbeddingsModelWrapper, cancel *C.CancelToken) ([][]float32, error) {
+		cTexts := cStringArray(texts)
+		defer freeCStringArray(cTexts)
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_encode_sentences(fnEncodeSentences, ptr, &cTexts[0], C.size_t(len(cTexts)), cancel)
+		if res == nil {
+			return nil, lastError("SentenceEmbeddingsModel.Encode")
+		}
+		defer C.call_free_embeddings_result(fnFreeEmbeddingsResult, res)
+
+		return embeddings(res), nil
+	})
+}
+
+// embeddings copies the native buffer once and slices it into one row per sentence.
+func embeddings(res *C.EmbeddingsResult) [][]float32 {
+	count, dim := int(res.count), int(res.dim)
+	rows := make([][]float32, count)
+	if count == 0 || dim == 0 {
+		return rows
+	}
+
+	data := make([]float32, count*dim)
+	copy(data, unsafe.Slice((*float32)(unsafe.Pointer(res.data)), count*dim))
+	for i := range rows {
+		rows[i] = data[i*dim : (i+1)*dim : (i+1)*dim]
+	}
+	return rows
+}
+
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
+func (m *SentenceEmbeddingsModel) Close() {
+	m.handle.close(func(ptr *C.SentenceEmbeddingsModelWrapper) {
+		C.call_free_sentence_embeddings_model(fnFreeSentenceEmbeddingsModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *SentenceEmbeddingsModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
+}
@@ -0,0 +1,154 @@
+package rustbert
+
+/*
+#include <stdlib.h>
+
+typedef int (*last_error_code_t)();
+typedef char* (*last_error_message_t)();
+typedef char* (*take_last_warning_t)();
+typedef void (*free_string_t)(char*);
+
+int call_last_error_code(void* f) {
+    return ((last_error_code_t)f)();
+}
+
+char* call_last_error_message(void* f) {
+    return ((last_error_message_t)f)();
+}
+
+char* call_take_last_warning(void* f) {
+    return ((take_last_warning_t)f)();
+}
+
+void call_free_string(void* f, char* s) {
+    ((free_string_t)f)(s);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// ErrorCode classifies failures reported by the native library.
+// Values match the ErrorCode enum in the Rust binding.
+type ErrorCode int
+
+const (
+	ErrorCodeUnknown      ErrorCode = 1
+	ErrorCodeInvalidInput ErrorCode = 2
+	ErrorCodeModelLoad    ErrorCode = 3
+	ErrorCodeTokenization ErrorCode = 4
+	ErrorCodeInference    ErrorCode = 5
+	ErrorCodeOOM          ErrorCode = 6
+	// ErrorCodeCancelled is reported when a call is stopped through its context.
+	// The *Context methods translate it into ctx.Err().
+	ErrorCodeCancelled ErrorCode = 7
+)
+
+func (c ErrorCode) String() string {
+	switch c {
+	case ErrorCodeInvalidInput:
+		return "invalid input"
+	case ErrorCodeModelLoad:
+		return "model load"
+	case ErrorCodeTokenization:
+		return "tokenization"
+	case ErrorCodeInference:
+		return "inference"
+	case ErrorCodeOOM:
+		return "out of memory"
+	case ErrorCodeCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// Error is returned by model constructors and inference calls when the native
+// library reports a failure. Message carries the original rust-bert error.
+//
+// Errors can be matched by category with errors.Is and the sentinel values below:
+//
+//	if errors.Is(err, rustbert.ErrModelLoad) { ... }
+type Error struct {
+	Code    ErrorCode
+	Op      string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("rustbert: %s error: %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("rustbert: %s: %s error: %s", e.Op, e.Code, e.Message)
+}
+
+// Is reports whether target is an *Error with the same Code. A target carrying a
+// message or operation must match those as well.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Code == e.Code &&
+		(t.Op == "" || t.Op == e.Op) &&
+		(t.Message == "" || t.Message == e.Message)
+}
+
+// Sentinel errors for use with errors.Is.
+var (
+	ErrUnknown      = &Error{Code: ErrorCodeUnknown}
+	ErrInvalidInput = &Error{Code: ErrorCodeInvalidInput}
+	ErrModelLoad    = &Error{Code: ErrorCodeModelLoad}
+	ErrTokenization = &Error{Code: ErrorCodeTokenization}
+	ErrInference    = &Error{Code: ErrorCodeInference}
+	ErrOOM          = &Error{Code: ErrorCodeOOM}
+)
+
+var (
+	fnLastErrorCode    unsafe.Pointer
+	fnLastErrorMessage unsafe.Pointer
+	fnTakeLastWarning  unsafe.Pointer
+	fnFreeString       unsafe.Pointer
+)
+
+// lastError returns the error recorded by the binding for the most recent call
+// on this OS thread. Callers must hold runtime.LockOSThread across the failed
+// call and lastError, otherwise the goroutine may have moved to another thread.
+func lastError(op string) error {
+	code := ErrorCode(C.call_last_error_code(fnLastErrorCode))
+	if code == 0 {
+		return &Error{Code: ErrorCodeUnknown, Op: op, Message: "native call failed without reporting an error"}
+	}
+
+	msg := "no error message"
+	if cMsg := C.call_last_error_message(fnLastErrorMessage); cMsg != nil {
+		msg = C.GoString(cMsg)
+		freeString(cMsg)
+	}
+	return &Error{Code: code, Op: op, Message: msg}
+}
+
+// logWarning passes the warning recorded by the binding for the most recent call on
+// this OS thread, if any, to InitOptions.Logger. The same thread rule as for lastError
+// applies.
+func logWarning(op string) {
+	cMsg := C.call_take_last_warning(fnTakeLastWarning)
+	if cMsg == nil {
+		return
+	}
+	msg := C.GoString(cMsg)
+	freeString(cMsg)
+	initLogger.Warn(msg, "op", op)
+}
+
+// freeString releases a string allocated by the binding.
+func freeString(s *C.char) {
+	C.call_free_string(fnFreeString, s)
+}
+
+func invalidInput(op, msg string) error {
+	return &Error{Code: ErrorCodeInvalidInput, Op: op, Message: msg}
+}
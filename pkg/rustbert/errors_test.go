@@ -0,0 +1,58 @@
+package rustbert
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+	err := fmt.Errorf("loading model: %w", &Error{
+		Code:    ErrorCodeModelLoad,
+		Op:      "NewSentimentModelFromFiles",
+		Message: "model file not found: /tmp/rust_model.ot",
+	})
+
+	if !errors.Is(err, ErrModelLoad) {
+		t.Errorf("errors.Is(%v, ErrModelLoad) = false, want true", err)
+	}
+	if errors.Is(err, ErrInference) {
+		t.Errorf("errors.Is(%v, ErrInference) = true, want false", err)
+	}
+	if !errors.Is(err, &Error{Code: ErrorCodeModelLoad, Op: "NewSentimentModelFromFiles"}) {
+		t.Errorf("errors.Is with matching Op = false, want true")
+	}
+	if errors.Is(err, &Error{Code: ErrorCodeModelLoad, Op: "NewNERModelFromFiles"}) {
+		t.Errorf("errors.Is with different Op = true, want false")
+	}
+
+	var rbErr *Error
+	if !errors.As(err, &rbErr) {
+		t.Fatalf("errors.As(%v, *Error) = false, want true", err)
+	}
+	if rbErr.Message != "model file not found: /tmp/rust_model.ot" {
+		t.Errorf("Message = %q", rbErr.Message)
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	tests := []struct {
+		err  *Error
+		want string
+	}{
+		{
+			&Error{Code: ErrorCodeOOM, Op: "SentimentModel.Predict", Message: "CUDA out of memory"},
+			"rustbert: SentimentModel.Predict: out of memory error: CUDA out of memory",
+		},
+		{
+			&Error{Code: ErrorCodeTokenization, Message: "bad vocab"},
+			"rustbert: tokenization error: bad vocab",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
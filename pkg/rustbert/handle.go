@@ -0,0 +1,66 @@
+package rustbert
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed is returned by calls on a model after its Close method has been called.
+var ErrClosed = errors.New("rustbert: model is closed")
+
+// modelHandle owns the native pointer of a model and makes the model safe for
+// concurrent use.
+//
+// Calls hold the read side of mu for as long as the native call runs, so Close, which
+// takes the write side, waits for them before freeing the model and later calls see
+// ErrClosed. Inference itself is serialized by run: rust-bert pipelines do not document
+// their forward passes as thread-safe and libtorch already spreads a single pass over
+// all cores. Use a separate model instance per goroutine for parallel inference.
+type modelHandle[T any] struct {
+	mu  sync.RWMutex
+	run sync.Mutex
+	ptr *T
+	// threads is the intra-op thread count of the calls, 0 for the process count.
+	threads atomic.Int32
+	// auxiliary marks the handles of objects that run no inference, such as tokenizers
+	// and conversation managers. Their calls leave the thread count alone, so that one
+	// made within a model call does not reset the count of the model.
+	auxiliary bool
+}
+
+// closed reports whether Close has been called.
+func (h *modelHandle[T]) closed() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.ptr == nil
+}
+
+// close frees the native model with free once in-flight calls have returned.
+// Calling it again is a no-op.
+func (h *modelHandle[T]) close(free func(ptr *T)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.ptr != nil {
+		free(h.ptr)
+		h.ptr = nil
+	}
+}
+
+// withModel runs call with the native pointer of h, serialized with the other calls on
+// the same model. It returns ErrClosed if the model has been closed.
+func withModel[T, R any](h *modelHandle[T], call func(ptr *T) (R, error)) (R, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.ptr == nil {
+		var zero R
+		return zero, ErrClosed
+	}
+
+	h.run.Lock()
+	defer h.run.Unlock()
+	if !h.auxiliary {
+		defer useThreads(h.threads.Load())()
+	}
+	return call(h.ptr)
+}
@@ -0,0 +1,87 @@
+package rustbert
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestModelHandleSerializesCalls(t *testing.T) {
+	value := 42
+	h := &modelHandle[int]{ptr: &value}
+
+	var running, maxRunning atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := withModel(h, func(ptr *int) (int, error) {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					peak := maxRunning.Load()
+					if n <= peak || maxRunning.CompareAndSwap(peak, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				return *ptr, nil
+			})
+			if err != nil || got != 42 {
+				t.Errorf("withModel = %d, %v; want 42, nil", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak := maxRunning.Load(); peak != 1 {
+		t.Errorf("%d calls ran concurrently, want 1", peak)
+	}
+}
+
+func TestModelHandleCloseWaitsForCalls(t *testing.T) {
+	value := 42
+	h := &modelHandle[int]{ptr: &value}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_, _ = withModel(h, func(ptr *int) (int, error) {
+			close(started)
+			<-release
+			// Close must not have freed the model while the call runs
+			if *ptr != 42 {
+				t.Errorf("model freed during call")
+			}
+			return *ptr, nil
+		})
+	}()
+	<-started
+
+	closed := make(chan struct{})
+	go func() {
+		h.close(func(ptr *int) { *ptr = 0 })
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("close returned while a call was in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-closed
+
+	if _, err := withModel(h, func(ptr *int) (int, error) { return *ptr, nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("withModel after close: got %v, want ErrClosed", err)
+	}
+	if !h.closed() {
+		t.Error("closed() = false after close")
+	}
+
+	// A second close is a no-op
+	h.close(func(*int) { t.Error("free called twice") })
+}
@@ -0,0 +1,286 @@
+package rustbert
+
+/*
+#include <stdbool.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef struct CancelToken CancelToken;
+
+typedef struct {
+    void* model;
+} KeywordExtractionModelWrapper;
+
+typedef struct {
+    int embeddings_model;
+    int device;
+    int64_t ngram_min;
+    int64_t ngram_max;
+    int64_t max_keywords;
+    int scorer;
+    double diversity;
+    int64_t max_sum_candidates;
+    const char** stopwords;
+    size_t stopwords_count;
+    bool custom_stopwords;
+} KeywordOptions;
+
+typedef struct {
+    size_t begin;
+    size_t end;
+} KeywordOffset;
+
+typedef struct {
+    char* text;
+    float score;
+    KeywordOffset* offsets;
+    size_t offsets_count;
+} KeywordItem;
+
+typedef struct {
+    KeywordItem* keywords;
+    size_t count;
+} KeywordResult;
+
+typedef struct {
+    KeywordResult* results;
+    size_t count;
+} KeywordBatchResult;
+
+typedef KeywordExtractionModelWrapper* (*new_keyword_extraction_model_t)(const KeywordOptions*);
+typedef KeywordBatchResult* (*extract_keywords_batch_t)(KeywordExtractionModelWrapper*, const char**, size_t, CancelToken*);
+typedef void (*free_keyword_extraction_model_t)(KeywordExtractionModelWrapper*);
+typedef void (*free_keyword_batch_result_t)(KeywordBatchResult*);
+
+KeywordExtractionModelWrapper* call_new_keyword_extraction_model(void* f, const KeywordOptions* o) {
+    return ((new_keyword_extraction_model_t)f)(o);
+}
+
+KeywordBatchResult* call_extract_keywords_batch(
+    void* f,
+    KeywordExtractionModelWrapper* w,
+    const char** texts,
+    size_t count,
+    CancelToken* cancel
+) {
+    return ((extract_keywords_batch_t)f)(w, texts, count, cancel);
+}
+
+void call_free_keyword_extraction_model(void* f, KeywordExtractionModelWrapper* w) {
+    ((free_keyword_extraction_model_t)f)(w);
+}
+
+void call_free_keyword_batch_result(void* f, KeywordBatchResult* r) {
+    ((free_keyword_batch_result_t)f)(r);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"runtime"
+	"unsafe"
+)
+
+var (
+	fnNewKeywordExtractionModel  unsafe.Pointer
+	fnExtractKeywordsBatch       unsafe.Pointer
+	fnFreeKeywordExtractionModel unsafe.Pointer
+	fnFreeKeywordBatchResult     unsafe.Pointer
+)
+
+// KeywordScorer selects how candidate keywords are ranked against the document.
+type KeywordScorer int
+
+const (
+	// KeywordScorerCosine ranks candidates by the cosine similarity of their embedding
+	// to the document embedding.
+	KeywordScorerCosine KeywordScorer = iota
+	// KeywordScorerMMR uses maximal marginal relevance, trading similarity to the
+	// document for dissimilarity to the keywords already picked (see
+	// KeywordExtractionConfig.Diversity).
+	KeywordScorerMMR
+	// KeywordScorerMaxSum picks, among the KeywordExtractionConfig.MaxSumCandidates
+	// candidates closest to the document, the keywords least similar to each other.
+	KeywordScorerMaxSum
+)
+
+// KeywordExtractionConfig configures a KeywordExtractionModel. Start from
+// DefaultKeywordExtractionConfig.
+type KeywordExtractionConfig struct {
+	// EmbeddingsModel is the sentence embeddings model documents and candidates are
+	// encoded with.
+	EmbeddingsModel SentenceEmbeddingsModelKind
+	// MinNgram and MaxNgram bound the number of words of a keyphrase.
+	MinNgram int
+	MaxNgram int
+	// MaxKeywords is the number of keywords returned per document, at most.
+	MaxKeywords int
+	Scorer      KeywordScorer
+	// Diversity, between 0 and 1, weighs dissimilarity between keywords against
+	// relevance to the document. Used by KeywordScorerMMR.
+	Diversity float64
+	// MaxSumCandidates is the number of candidates KeywordScorerMaxSum picks from. It
+	// must be at least MaxKeywords.
+	MaxSumCandidates int
+	// Stopwords are never used as keywords nor at the edges of keyphrases. nil uses
+	// rust-bert's built-in English list.
+	Stopwords []string
+	// Device is the hardware the model runs on.
+	Device Device
+}
+
+// DefaultKeywordExtractionConfig returns rust-bert's defaults: up to 5 single-word
+// keywords ranked by cosine similarity with all-MiniLM-L6-v2 embeddings.
+func DefaultKeywordExtractionConfig() KeywordExtractionConfig {
+	return KeywordExtractionConfig{
+		EmbeddingsModel:  SentenceEmbeddingsAllMiniLmL6V2,
+		MinNgram:         1,
+		MaxNgram:         1,
+		MaxKeywords:      5,
+		Scorer:           KeywordScorerCosine,
+		Diversity:        0.5,
+		MaxSumCandidates: 20,
+		Device:           DeviceAuto,
+	}
+}
+
+// Keyword is a keyword or keyphrase extracted from a document.
+type Keyword struct {
+	Text string
+	// Score is the relevance of the keyword to the document, higher is better.
+	Score float64
+	// Offsets locates every occurrence of the keyword in the document.
+	Offsets []Offset
+}
+
+// Offset is a span of a text, in characters (Unicode code points).
+type Offset struct {
+	Begin int
+	End   int
+}
+
+// KeywordExtractionModel extracts the keywords and keyphrases that best describe a
+// document.
+type KeywordExtractionModel struct {
+	handle modelHandle[C.KeywordExtractionModelWrapper]
+}
+
+// NewKeywordExtractionModel downloads (if needed) and loads the embeddings model of cfg.
+// Invalid settings are rejected with ErrInvalidInput.
+func NewKeywordExtractionModel(cfg KeywordExtractionConfig) (*KeywordExtractionModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+
+	cStopwords := cStringArray(cfg.Stopwords)
+	defer freeCStringArray(cStopwords)
+
+	cOpts := C.KeywordOptions{
+		embeddings_model:   C.int(cfg.EmbeddingsModel),
+		device:             C.int(cfg.Device),
+		ngram_min:          C.int64_t(cfg.MinNgram),
+		ngram_max:          C.int64_t(cfg.MaxNgram),
+		max_keywords:       C.int64_t(cfg.MaxKeywords),
+		scorer:             C.int(cfg.Scorer),
+		diversity:          C.double(cfg.Diversity),
+		max_sum_candidates: C.int64_t(cfg.MaxSumCandidates),
+		stopwords_count:    C.size_t(len(cStopwords)),
+		custom_stopwords:   C.bool(cfg.Stopwords != nil),
+	}
+	if len(cStopwords) > 0 {
+		cOpts.stopwords = &cStopwords[0]
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_keyword_extraction_model(fnNewKeywordExtractionModel, &cOpts)
+	if ptr == nil {
+		return nil, lastError("NewKeywordExtractionModel")
+	}
+	return &KeywordExtractionModel{handle: modelHandle[C.KeywordExtractionModelWrapper]{ptr: ptr}}, nil
+}
+
+// Extract returns the keywords of text, best first.
+func (m *KeywordExtractionModel) Extract(text string) ([]Keyword, error) {
+	return m.ExtractContext(context.Background(), text)
+}
+
+// ExtractContext is like Extract but returns ctx.Err() as soon as ctx is done.
+func (m *KeywordExtractionModel) ExtractContext(ctx context.Context, text string) ([]Keyword, error) {
+	results, err := m.ExtractBatchContext(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// ExtractBatch extracts the keywords of several texts. The keywords of texts[i] are
+// returned at index i.
+func (m *KeywordExtractionModel) ExtractBatch(texts []string) ([][]Keyword, error) {
+	return m.ExtractBatchContext(context.Background(), texts)
+}
+
+// ExtractBatchContext is like ExtractBatch but stops once ctx is done, returning ctx.Err().
+func (m *KeywordExtractionModel) ExtractBatchContext(ctx context.Context, texts []string) ([][]Keyword, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if len(texts) == 0 {
+		return [][]Keyword{}, nil
+	}
+
+	return withContext(ctx, &m.handle, func(ptr *C.KeywordExtractionModelWrapper, cancel *C.CancelToken) ([][]Keyword, error) {
+		cTexts := cStringArray(texts)
+		defer freeCStringArray(cTexts)
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_extract_keywords_batch(fnExtractKeywordsBatch, ptr, &cTexts[0], C.size_t(len(cTexts)), cancel)
+		if res == nil {
+			return nil, lastError("KeywordExtractionModel.ExtractBatch")
+		}
+		defer C.call_free_keyword_batch_result(fnFreeKeywordBatchResult, res)
+
+		cResults := unsafe.Slice(res.results, int(res.count))
+		results := make([][]Keyword, len(cResults))
+		for i := range cResults {
+			results[i] = keywords(&cResults[i])
+		}
+		return results, nil
+	})
+}
+
+func keywords(res *C.KeywordResult) []Keyword {
+	cKeywords := unsafe.Slice(res.keywords, int(res.count))
+	keywords := make([]Keyword, len(cKeywords))
+	for i, k := range cKeywords {
+		cOffsets := unsafe.Slice(k.offsets, int(k.offsets_count))
+		offsets := make([]Offset, len(cOffsets))
+		for j, o := range cOffsets {
+			offsets[j] = Offset{Begin: int(o.begin), End: int(o.end)}
+		}
+		keywords[i] = Keyword{
+			Text:    C.GoString(k.text),
+			Score:   float64(k.score),
+			Offsets: offsets,
+		}
+	}
+	return keywords
+}
+
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
+func (m *KeywordExtractionModel) Close() {
+	m.handle.close(func(ptr *C.KeywordExtractionModelWrapper) {
+		C.call_free_keyword_extraction_model(fnFreeKeywordExtractionModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *KeywordExtractionModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
+}
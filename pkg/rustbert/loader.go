@@ -21,13 +21,18 @@ char* get_dlerror() {
 import "C"
 import (
 	"compress/gzip"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"unsafe"
 )
 
@@ -38,13 +43,160 @@ var libFS embed.FS
 var (
 	initialized bool
 	dlHandle    unsafe.Pointer
+
+	// initMu guards initialized, the loading of the library and the options below.
+	initMu sync.Mutex
+	// loadOptions holds LibDir, ExtractDir and SkipEmbedded of the loaded library.
+	loadOptions InitOptions
+	// initDevice is the Device last given to InitWithOptions.
+	initDevice Device
+	// initCasing is the Casing last given to InitWithOptions.
+	initCasing Casing
+	// initLogger receives the warnings of the binding: InitOptions.Logger of the
+	// loaded library, or a logger discarding them.
+	initLogger = slog.New(slog.DiscardHandler)
+	// autoInit lets constructors load the library on first use.
+	autoInit = true
 )
 
+// ErrNotInitialized is returned by constructors when the library has not been loaded
+// and DisableAutoInit has been called.
+var ErrNotInitialized = errors.New("rustbert: library not initialized")
+
+// InitOptions configures how the library is loaded. The zero value extracts the
+// embedded libraries to a fresh temporary directory, as Init does.
+type InitOptions struct {
+	// LibDir is a directory holding the binding library and its libtorch dependencies,
+	// e.g. extracted by an earlier run or installed with the application. Nothing is
+	// extracted when it is set.
+	LibDir string
+	// ExtractDir is where the embedded libraries are extracted. They are kept in a
+	// subdirectory named after the embedded build and reused by later processes, so
+	// only the first start pays for the extraction. Empty uses a temporary directory
+	// that is removed once the libraries are loaded.
+	ExtractDir string
+	// SkipEmbedded never extracts the embedded libraries. Without LibDir, the binding
+	// library is then looked up on the system library path.
+	SkipEmbedded bool
+	// Logger receives progress and diagnostics while the library loads. Nil discards
+	// them.
+	Logger *slog.Logger
+	// Threads sizes libtorch's thread pools before any model runs.
+	Threads ThreadConfig
+	// Device is where models run when their constructor is given DeviceAuto.
+	// DeviceAuto keeps the default of the first CUDA device when one is available and
+	// the CPU otherwise.
+	Device Device
+	// Casing is how the *FromFiles constructors tokenize the BERT, DistilBERT and
+	// ELECTRA checkpoints whose files do not tell whether they are cased.
+	Casing Casing
+}
+
+// Init extracts the embedded libraries to a temporary directory and loads them.
+// It is called by the first model constructor unless DisableAutoInit has been called;
+// call it, or InitWithOptions, to load the library up front and handle failures at
+// startup.
 func Init() error {
+	return InitWithOptions(InitOptions{})
+}
+
+// InitWithOptions loads the library as configured by opts. Once the library is loaded,
+// later calls apply the non-zero Threads counts, a Casing other than CasingAuto and a
+// Device if none was given before.
+// Setting LibDir, ExtractDir, SkipEmbedded or Device to another value than the one in
+// use returns ErrInvalidInput; fields left zero accept it.
+func InitWithOptions(opts InitOptions) error {
+	initMu.Lock()
+	err := initLocked(opts)
+	initMu.Unlock()
+	if err != nil {
+		return err
+	}
+	return opts.Threads.apply()
+}
+
+// initLocked loads the library with opts, or checks them against the loaded one, and
+// sets the default device. initMu must be held.
+func initLocked(opts InitOptions) error {
+	if initialized {
+		if err := checkReinit(opts); err != nil {
+			return err
+		}
+	} else if err := load(opts); err != nil {
+		return err
+	}
+
+	if opts.Device != DeviceAuto && opts.Device != initDevice {
+		if err := setDefaultDevice(opts.Device); err != nil {
+			return err
+		}
+		initDevice = opts.Device
+	}
+	if opts.Casing != CasingAuto && opts.Casing != initCasing {
+		if err := setDefaultCasing(opts.Casing); err != nil {
+			return err
+		}
+		initCasing = opts.Casing
+	}
+	return nil
+}
+
+// checkReinit returns ErrInvalidInput if opts set a loading option or the device to
+// another value than the one in use. initMu must be held.
+func checkReinit(opts InitOptions) error {
+	var conflicts []string
+	if opts.LibDir != "" && opts.LibDir != loadOptions.LibDir {
+		conflicts = append(conflicts, fmt.Sprintf("LibDir %q instead of %q", loadOptions.LibDir, opts.LibDir))
+	}
+	if opts.ExtractDir != "" && opts.ExtractDir != loadOptions.ExtractDir {
+		conflicts = append(conflicts, fmt.Sprintf("ExtractDir %q instead of %q", loadOptions.ExtractDir, opts.ExtractDir))
+	}
+	if opts.SkipEmbedded && !loadOptions.SkipEmbedded {
+		conflicts = append(conflicts, "the embedded libraries")
+	}
+	if opts.Device != DeviceAuto && initDevice != DeviceAuto && opts.Device != initDevice {
+		conflicts = append(conflicts, fmt.Sprintf("Device %s instead of %s", initDevice, opts.Device))
+	}
+	if len(conflicts) > 0 {
+		return invalidInput("InitWithOptions", "library already initialized with "+strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// DisableAutoInit stops constructors from loading the library on first use. They
+// return ErrNotInitialized until Init or InitWithOptions has been called, so that
+// programs only pay for loading libtorch on the code paths that call it explicitly.
+func DisableAutoInit() {
+	initMu.Lock()
+	defer initMu.Unlock()
+	autoInit = false
+}
+
+// ensureInit loads the library with the default options if it is not loaded yet and
+// auto-init has not been disabled.
+func ensureInit() error {
+	initMu.Lock()
+	defer initMu.Unlock()
+	if initialized {
+		return nil
+	}
+	if !autoInit {
+		return ErrNotInitialized
+	}
+	return load(InitOptions{})
+}
+
+// load opens the binding library and resolves its symbols. initMu must be held.
+func load(opts InitOptions) error {
 	if initialized {
 		return nil
 	}
 
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
 	goOS := runtime.GOOS
 	goArch := runtime.GOARCH
 
@@ -68,49 +220,21 @@ func Init() error {
 		return fmt.Errorf("unsupported OS: %s", goOS)
 	}
 
-	tmpDir, err := os.MkdirTemp("", "go-rust-bert-lib")
-	if err != nil {
-		return fmt.Errorf("failed to create temp dir: %w", err)
-	}
-
-	// Extract Libs
 	libNameBinding := strings.TrimSuffix(libName, ".gz")
-	destBinding := filepath.Join(tmpDir, libNameBinding)
-	if err := extractAndDecompress(filepath.Join(libPath, libName), destBinding); err != nil {
-		return err
-	}
-
-	// Extract libtorch dependencies - platform specific
-	var libs []string
-	var optionalLibs []string
-	switch goOS {
-	case "darwin":
-		libs = []string{"libc10.dylib.gz", "libtorch_cpu.dylib.gz", "libtorch.dylib.gz", "libomp.dylib.gz"}
-	case "linux":
-		libs = []string{"libc10.so.gz", "libtorch_cpu.so.gz", "libtorch.so.gz"}
-		// gomp library will be discovered dynamically
-	}
-	for _, l := range libs {
-		dest := filepath.Join(tmpDir, strings.TrimSuffix(l, ".gz"))
-		if err := extractAndDecompress(filepath.Join(libPath, l), dest); err != nil {
-			return fmt.Errorf("failed to extract %s: %w", l, err)
+	var libDir string
+	switch {
+	case opts.LibDir != "":
+		libDir = opts.LibDir
+	case opts.SkipEmbedded:
+		// Left to the dynamic linker's search path
+	default:
+		var err error
+		if libDir, err = extractLibs(libPath, libName, opts.ExtractDir, logger); err != nil {
+			return err
 		}
-	}
-	// Extract optional libs (don't fail if missing)
-	for _, l := range optionalLibs {
-		dest := filepath.Join(tmpDir, strings.TrimSuffix(l, ".gz"))
-		_ = extractAndDecompress(filepath.Join(libPath, l), dest)
-	}
-
-	// On Linux, discover and extract any libgomp*.so*.gz files (name includes hash)
-	if goOS == "linux" {
-		entries, _ := libFS.ReadDir(libPath)
-		for _, entry := range entries {
-			name := entry.Name()
-			if strings.HasPrefix(name, "libgomp") && strings.HasSuffix(name, ".gz") {
-				dest := filepath.Join(tmpDir, strings.TrimSuffix(name, ".gz"))
-				_ = extractAndDecompress(filepath.Join(libPath, name), dest)
-			}
+		if opts.ExtractDir == "" {
+			// The loaded libraries stay mapped once their files are gone.
+			defer os.RemoveAll(libDir)
 		}
 	}
 
@@ -123,15 +247,27 @@ func Init() error {
 	case "linux":
 		depLibNames = []string{"libc10.so", "libtorch.so", "libtorch_cpu.so"}
 		// Also load any libgomp libraries
-		files, _ := os.ReadDir(tmpDir)
+		files, _ := os.ReadDir(libDir)
 		for _, f := range files {
 			if strings.HasPrefix(f.Name(), "libgomp") && strings.HasSuffix(f.Name(), ".so.1") {
 				depLibNames = append([]string{f.Name()}, depLibNames...)
 			}
 		}
 	}
+	if libDir == "" {
+		// The dynamic linker finds them next to the binding library
+		depLibNames = nil
+	}
 	for _, depLib := range depLibNames {
-		depPath := filepath.Join(tmpDir, depLib)
+		depPath := filepath.Join(libDir, depLib)
+		if opts.LibDir != "" {
+			// Dependencies missing from a user-provided directory are left to the
+			// dynamic linker.
+			if _, err := os.Stat(depPath); err != nil {
+				logger.Debug("dependency not in library directory", "lib", depLib, "dir", libDir)
+				continue
+			}
+		}
 		cDepPath := C.CString(depPath)
 		depHandle := C.open_lib(cDepPath)
 		C.free(unsafe.Pointer(cDepPath))
@@ -142,6 +278,10 @@ func Init() error {
 	}
 
 	// DLOPEN main binding library
+	destBinding := libNameBinding
+	if libDir != "" {
+		destBinding = filepath.Join(libDir, libNameBinding)
+	}
 	cPath := C.CString(destBinding)
 	defer C.free(unsafe.Pointer(cPath))
 	dlHandle = C.open_lib(cPath)
@@ -149,6 +289,9 @@ func Init() error {
 		cErr := C.get_dlerror()
 		return fmt.Errorf("dlopen failed: %s", C.GoString(cErr))
 	}
+	logger.Info("loaded rust-bert binding", "path", destBinding)
+
+	var err error
 
 	// Load Symbols
 	loadSym := func(name string) (unsafe.Pointer, error) {
@@ -161,6 +304,54 @@ func Init() error {
 		return sym, nil
 	}
 
+	// Errors
+	if fnLastErrorCode, err = loadSym("last_error_code"); err != nil {
+		return err
+	}
+	if fnLastErrorMessage, err = loadSym("last_error_message"); err != nil {
+		return err
+	}
+	if fnTakeLastWarning, err = loadSym("take_last_warning"); err != nil {
+		return err
+	}
+	if fnFreeString, err = loadSym("free_string"); err != nil {
+		return err
+	}
+	if fnNewCancelToken, err = loadSym("new_cancel_token"); err != nil {
+		return err
+	}
+	if fnCancelTokenCancel, err = loadSym("cancel_token_cancel"); err != nil {
+		return err
+	}
+	if fnFreeCancelToken, err = loadSym("free_cancel_token"); err != nil {
+		return err
+	}
+
+	// Devices
+	if fnSetDefaultDevice, err = loadSym("set_default_device"); err != nil {
+		return err
+	}
+	if fnCudaDeviceCount, err = loadSym("cuda_device_count"); err != nil {
+		return err
+	}
+	if fnMPSAvailable, err = loadSym("mps_available"); err != nil {
+		return err
+	}
+
+	// Threads
+	if fnSetNumThreads, err = loadSym("set_num_threads"); err != nil {
+		return err
+	}
+	if fnGetNumThreads, err = loadSym("get_num_threads"); err != nil {
+		return err
+	}
+	if fnSetNumInteropThreads, err = loadSym("set_num_interop_threads"); err != nil {
+		return err
+	}
+	if fnGetNumInteropThreads, err = loadSym("get_num_interop_threads"); err != nil {
+		return err
+	}
+
 	if fnNewSentimentModel, err = loadSym("new_sentiment_model"); err != nil {
 		return err
 	}
@@ -176,6 +367,15 @@ func Init() error {
 	if fnFreeSentimentResult, err = loadSym("free_sentiment_result"); err != nil {
 		return err
 	}
+	if fnPredictSentimentBatch, err = loadSym("predict_sentiment_batch"); err != nil {
+		return err
+	}
+	if fnFreeSentimentBatchResult, err = loadSym("free_sentiment_batch_result"); err != nil {
+		return err
+	}
+	if fnPredictSentimentLong, err = loadSym("predict_sentiment_long"); err != nil {
+		return err
+	}
 
 	// POS Tagging
 	if fnNewPOSModel, err = loadSym("new_pos_model"); err != nil {
@@ -190,6 +390,12 @@ func Init() error {
 	if fnFreePOSResult, err = loadSym("free_pos_result"); err != nil {
 		return err
 	}
+	if fnPredictPOSBatch, err = loadSym("predict_pos_batch"); err != nil {
+		return err
+	}
+	if fnFreePOSBatchResult, err = loadSym("free_pos_batch_result"); err != nil {
+		return err
+	}
 
 	// NER
 	if fnNewNERModel, err = loadSym("new_ner_model"); err != nil {
@@ -207,6 +413,15 @@ func Init() error {
 	if fnFreeNERResult, err = loadSym("free_ner_result"); err != nil {
 		return err
 	}
+	if fnPredictNERBatch, err = loadSym("predict_ner_batch"); err != nil {
+		return err
+	}
+	if fnFreeNERBatchResult, err = loadSym("free_ner_batch_result"); err != nil {
+		return err
+	}
+	if fnPredictNERLong, err = loadSym("predict_ner_long"); err != nil {
+		return err
+	}
 
 	// Question Answering
 	if fnNewQAModel, err = loadSym("new_qa_model"); err != nil {
@@ -224,6 +439,21 @@ func Init() error {
 	if fnFreeQAResult, err = loadSym("free_qa_result"); err != nil {
 		return err
 	}
+	if fnPredictQABatch, err = loadSym("predict_qa_batch"); err != nil {
+		return err
+	}
+	if fnFreeQABatchResult, err = loadSym("free_qa_batch_result"); err != nil {
+		return err
+	}
+	if fnNewQAModelWithOptions, err = loadSym("new_qa_model_with_options"); err != nil {
+		return err
+	}
+	if fnNewQAModelFromFilesWithOptions, err = loadSym("new_qa_model_from_files_with_options"); err != nil {
+		return err
+	}
+	if fnPredictQABatchWithOptions, err = loadSym("predict_qa_batch_with_options"); err != nil {
+		return err
+	}
 
 	// Summarization
 	if fnNewSummarizationModel, err = loadSym("new_summarization_model"); err != nil {
@@ -232,6 +462,12 @@ func Init() error {
 	if fnNewSummarizationModelFromFiles, err = loadSym("new_summarization_model_from_files"); err != nil {
 		return err
 	}
+	if fnNewSummarizationModelWithConfig, err = loadSym("new_summarization_model_with_config"); err != nil {
+		return err
+	}
+	if fnNewSummarizationModelFromFilesWithConfig, err = loadSym("new_summarization_model_from_files_with_config"); err != nil {
+		return err
+	}
 	if fnSummarize, err = loadSym("summarize"); err != nil {
 		return err
 	}
@@ -241,6 +477,15 @@ func Init() error {
 	if fnFreeSummarizationResult, err = loadSym("free_summarization_result"); err != nil {
 		return err
 	}
+	if fnSummarizeBatch, err = loadSym("summarize_batch"); err != nil {
+		return err
+	}
+	if fnSummarizeLong, err = loadSym("summarize_long"); err != nil {
+		return err
+	}
+	if fnFreeLongSummaryResult, err = loadSym("free_long_summary_result"); err != nil {
+		return err
+	}
 
 	// Zero-Shot Classification
 	if fnNewZeroShotModel, err = loadSym("new_zero_shot_model"); err != nil {
@@ -258,11 +503,23 @@ func Init() error {
 	if fnFreeZeroShotResult, err = loadSym("free_zero_shot_result"); err != nil {
 		return err
 	}
+	if fnPredictZeroShotBatch, err = loadSym("predict_zero_shot_batch"); err != nil {
+		return err
+	}
+	if fnFreeZeroShotBatchResult, err = loadSym("free_zero_shot_batch_result"); err != nil {
+		return err
+	}
+	if fnPredictZeroShotWithOptions, err = loadSym("predict_zero_shot_with_options"); err != nil {
+		return err
+	}
 
 	// Translation
 	if fnNewTranslationModel, err = loadSym("new_translation_model"); err != nil {
 		return err
 	}
+	if fnNewTranslationModelWithBuilder, err = loadSym("new_translation_model_with_builder"); err != nil {
+		return err
+	}
 	if fnNewTranslationModelFromFiles, err = loadSym("new_translation_model_from_files"); err != nil {
 		return err
 	}
@@ -272,6 +529,9 @@ func Init() error {
 	if fnFreeTranslationModel, err = loadSym("free_translation_model"); err != nil {
 		return err
 	}
+	if fnTranslateBatch, err = loadSym("translate_batch"); err != nil {
+		return err
+	}
 
 	// Text Generation
 	if fnNewTextGenerationModel, err = loadSym("new_text_generation_model"); err != nil {
@@ -280,17 +540,309 @@ func Init() error {
 	if fnNewTextGenerationModelFromFiles, err = loadSym("new_text_generation_model_from_files"); err != nil {
 		return err
 	}
+	if fnNewTextGenerationModelWithOptions, err = loadSym("new_text_generation_model_with_options"); err != nil {
+		return err
+	}
+	if fnNewTextGenerationModelFromFilesWithOptions, err = loadSym("new_text_generation_model_from_files_with_options"); err != nil {
+		return err
+	}
 	if fnGenerateText, err = loadSym("generate_text"); err != nil {
 		return err
 	}
 	if fnFreeTextGenerationModel, err = loadSym("free_text_generation_model"); err != nil {
 		return err
 	}
+	if fnGenerateTextBatch, err = loadSym("generate_text_batch"); err != nil {
+		return err
+	}
+	if fnGenerateTextStream, err = loadSym("generate_text_stream"); err != nil {
+		return err
+	}
 
+	// Sentence Embeddings
+	if fnNewSentenceEmbeddingsModel, err = loadSym("new_sentence_embeddings_model"); err != nil {
+		return err
+	}
+	if fnNewSentenceEmbeddingsModelFromDir, err = loadSym("new_sentence_embeddings_model_from_dir"); err != nil {
+		return err
+	}
+	if fnEncodeSentences, err = loadSym("encode_sentences"); err != nil {
+		return err
+	}
+	if fnFreeSentenceEmbeddingsModel, err = loadSym("free_sentence_embeddings_model"); err != nil {
+		return err
+	}
+	if fnFreeEmbeddingsResult, err = loadSym("free_embeddings_result"); err != nil {
+		return err
+	}
+
+	// Keyword Extraction
+	if fnNewKeywordExtractionModel, err = loadSym("new_keyword_extraction_model"); err != nil {
+		return err
+	}
+	if fnExtractKeywordsBatch, err = loadSym("extract_keywords_batch"); err != nil {
+		return err
+	}
+	if fnFreeKeywordExtractionModel, err = loadSym("free_keyword_extraction_model"); err != nil {
+		return err
+	}
+	if fnFreeKeywordBatchResult, err = loadSym("free_keyword_batch_result"); err != nil {
+		return err
+	}
+
+	// Fill-Mask
+	if fnNewMaskedLanguageModel, err = loadSym("new_masked_language_model"); err != nil {
+		return err
+	}
+	if fnNewMaskedLanguageModelFromFiles, err = loadSym("new_masked_language_model_from_files"); err != nil {
+		return err
+	}
+	if fnFillMaskBatch, err = loadSym("fill_mask_batch"); err != nil {
+		return err
+	}
+	if fnFreeMaskedLanguageModel, err = loadSym("free_masked_language_model"); err != nil {
+		return err
+	}
+	if fnFreeFillMaskBatchResult, err = loadSym("free_fill_mask_batch_result"); err != nil {
+		return err
+	}
+
+	// Sequence Classification
+	if fnNewSequenceClassificationModel, err = loadSym("new_sequence_classification_model"); err != nil {
+		return err
+	}
+	if fnNewSequenceClassificationModelFromFiles, err = loadSym("new_sequence_classification_model_from_files"); err != nil {
+		return err
+	}
+	if fnClassifyBatch, err = loadSym("classify_batch"); err != nil {
+		return err
+	}
+	if fnFreeSequenceClassificationModel, err = loadSym("free_sequence_classification_model"); err != nil {
+		return err
+	}
+	if fnFreeClassificationBatchResult, err = loadSym("free_classification_batch_result"); err != nil {
+		return err
+	}
+
+	// Token Classification
+	if fnNewTokenClassificationModel, err = loadSym("new_token_classification_model"); err != nil {
+		return err
+	}
+	if fnNewTokenClassificationModelFromFiles, err = loadSym("new_token_classification_model_from_files"); err != nil {
+		return err
+	}
+	if fnClassifyTokensBatch, err = loadSym("classify_tokens_batch"); err != nil {
+		return err
+	}
+	if fnFreeTokenClassificationModel, err = loadSym("free_token_classification_model"); err != nil {
+		return err
+	}
+	if fnFreeTokenClassificationBatchResult, err = loadSym("free_token_classification_batch_result"); err != nil {
+		return err
+	}
+
+	// Conversation
+	if fnNewConversationModel, err = loadSym("new_conversation_model"); err != nil {
+		return err
+	}
+	if fnNewConversationModelWithOptions, err = loadSym("new_conversation_model_with_options"); err != nil {
+		return err
+	}
+	if fnGenerateResponses, err = loadSym("generate_responses"); err != nil {
+		return err
+	}
+	if fnFreeConversationModel, err = loadSym("free_conversation_model"); err != nil {
+		return err
+	}
+	if fnFreeConversationResponses, err = loadSym("free_conversation_responses"); err != nil {
+		return err
+	}
+	if fnNewConversationManager, err = loadSym("new_conversation_manager"); err != nil {
+		return err
+	}
+	if fnConversationCreate, err = loadSym("conversation_create"); err != nil {
+		return err
+	}
+	if fnConversationAddUserInput, err = loadSym("conversation_add_user_input"); err != nil {
+		return err
+	}
+	if fnConversationRemove, err = loadSym("conversation_remove"); err != nil {
+		return err
+	}
+	if fnConversationIDs, err = loadSym("conversation_ids"); err != nil {
+		return err
+	}
+	if fnConversationHistory, err = loadSym("conversation_history"); err != nil {
+		return err
+	}
+	if fnConversationTrim, err = loadSym("conversation_trim"); err != nil {
+		return err
+	}
+	if fnFreeConversationManager, err = loadSym("free_conversation_manager"); err != nil {
+		return err
+	}
+	if fnFreeConversationHistory, err = loadSym("free_conversation_history"); err != nil {
+		return err
+	}
+
+	// Tokenizer
+	if fnNewTokenizerFromFiles, err = loadSym("new_tokenizer_from_files"); err != nil {
+		return err
+	}
+	if fnSetDefaultCasing, err = loadSym("set_default_casing"); err != nil {
+		return err
+	}
+	if fnEncodeText, err = loadSym("encode_text"); err != nil {
+		return err
+	}
+	if fnDecodeIDs, err = loadSym("decode_ids"); err != nil {
+		return err
+	}
+	if fnFreeTokenizer, err = loadSym("free_tokenizer"); err != nil {
+		return err
+	}
+	if fnFreeEncodingResult, err = loadSym("free_encoding_result"); err != nil {
+		return err
+	}
+
+	if fnFreeStringArray, err = loadSym("free_string_array"); err != nil {
+		return err
+	}
+
+	defaultNumThreads = libtorchNumThreads()
+
+	loadOptions = InitOptions{LibDir: opts.LibDir, ExtractDir: opts.ExtractDir, SkipEmbedded: opts.SkipEmbedded}
+	initLogger = logger
 	initialized = true
 	return nil
 }
 
+// extractLibs extracts the embedded libraries of libPath and returns the directory
+// holding them: a new temporary directory, left to the caller to remove, or a
+// subdirectory of extractDir reused by later runs.
+func extractLibs(libPath, libName, extractDir string, logger *slog.Logger) (string, error) {
+	var dir string
+	if extractDir == "" {
+		tmpDir, err := os.MkdirTemp("", "go-rust-bert-lib")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temp dir: %w", err)
+		}
+		dir = tmpDir
+	} else {
+		version, err := embeddedVersion(libPath)
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(extractDir, "go-rust-bert-"+version)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create extract dir: %w", err)
+		}
+	}
+	logger.Info("extracting embedded libraries", "dir", dir)
+
+	// Extract Libs
+	destBinding := filepath.Join(dir, strings.TrimSuffix(libName, ".gz"))
+	if err := extractOnce(filepath.Join(libPath, libName), destBinding, logger); err != nil {
+		return "", err
+	}
+
+	// Extract libtorch dependencies - platform specific
+	var libs []string
+	var optionalLibs []string
+	switch runtime.GOOS {
+	case "darwin":
+		libs = []string{"libc10.dylib.gz", "libtorch_cpu.dylib.gz", "libtorch.dylib.gz", "libomp.dylib.gz"}
+	case "linux":
+		libs = []string{"libc10.so.gz", "libtorch_cpu.so.gz", "libtorch.so.gz"}
+		// gomp library will be discovered dynamically
+	}
+	for _, l := range libs {
+		dest := filepath.Join(dir, strings.TrimSuffix(l, ".gz"))
+		if err := extractOnce(filepath.Join(libPath, l), dest, logger); err != nil {
+			return "", fmt.Errorf("failed to extract %s: %w", l, err)
+		}
+	}
+	// Extract optional libs (don't fail if missing)
+	for _, l := range optionalLibs {
+		dest := filepath.Join(dir, strings.TrimSuffix(l, ".gz"))
+		_ = extractOnce(filepath.Join(libPath, l), dest, logger)
+	}
+
+	// On Linux, discover and extract any libgomp*.so*.gz files (name includes hash)
+	if runtime.GOOS == "linux" {
+		entries, _ := libFS.ReadDir(libPath)
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.HasPrefix(name, "libgomp") && strings.HasSuffix(name, ".gz") {
+				dest := filepath.Join(dir, strings.TrimSuffix(name, ".gz"))
+				_ = extractOnce(filepath.Join(libPath, name), dest, logger)
+			}
+		}
+	}
+	return dir, nil
+}
+
+// embeddedVersion identifies the embedded libraries of libPath by their names and sizes
+// and the contents of the binding library, which a rebuild can change without changing
+// its size. The libtorch archives, gigabytes that only change with a libtorch upgrade,
+// are not read.
+func embeddedVersion(libPath string) (string, error) {
+	entries, err := libFS.ReadDir(libPath)
+	if err != nil {
+		return "", fmt.Errorf("read embedded %s: %w", libPath, err)
+	}
+	h := sha256.New()
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return "", fmt.Errorf("stat embedded %s: %w", entry.Name(), err)
+		}
+		fmt.Fprintf(h, "%s:%d\n", entry.Name(), info.Size())
+		if strings.HasPrefix(entry.Name(), "librust_bert_binding") {
+			if err := hashEmbedded(h, filepath.Join(libPath, entry.Name())); err != nil {
+				return "", err
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8]), nil
+}
+
+// hashEmbedded writes the contents of the embedded file at path to h.
+func hashEmbedded(h io.Writer, path string) error {
+	f, err := libFS.Open(path)
+	if err != nil {
+		return fmt.Errorf("open embedded %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("read embedded %s: %w", path, err)
+	}
+	return nil
+}
+
+// extractOnce extracts srcPath to destPath unless an earlier run already did. The file
+// is written under a temporary name and renamed into place, so a process stopped
+// halfway, or another one extracting at the same time, never leaves a truncated
+// library behind.
+func extractOnce(srcPath, destPath string, logger *slog.Logger) error {
+	if _, err := os.Stat(destPath); err == nil {
+		logger.Debug("reusing extracted library", "path", destPath)
+		return nil
+	}
+	logger.Debug("extracting library", "path", destPath)
+
+	tmpPath := fmt.Sprintf("%s.%d.tmp", destPath, os.Getpid())
+	if err := extractAndDecompress(srcPath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("rename %s: %w", tmpPath, err)
+	}
+	return nil
+}
+
 func extractAndDecompress(srcPath, destPath string) error {
 	var r io.Reader
 	var closers []io.Closer
@@ -366,10 +918,3 @@ func extractAndDecompress(srcPath, destPath string) error {
 	}
 	return nil
 }
-
-func init() {
-	if err := Init(); err != nil {
-		// Log but don't panic, let the user decide if they want to handle it
-		fmt.Fprintf(os.Stderr, "WARNING: go-rust-bert failed to initialize: %v\n", err)
-	}
-}
@@ -0,0 +1,80 @@
+package rustbert
+
+import (
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// embeddedLib returns the path of an embedded library in libFS.
+func embeddedLib(t *testing.T) string {
+	t.Helper()
+	var lib string
+	fs.WalkDir(libFS, "lib", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && lib == "" && strings.HasSuffix(path, ".gz") {
+			lib = path
+		}
+		return nil
+	})
+	if lib == "" {
+		t.Skip("no embedded library")
+	}
+	return lib
+}
+
+func TestExtractOnceReusesExtractedFiles(t *testing.T) {
+	src := embeddedLib(t)
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "lib.so")
+	logger := slog.New(slog.DiscardHandler)
+
+	if err := extractOnce(src, dest, logger); err != nil {
+		t.Fatalf("extractOnce = %v", err)
+	}
+	if info, err := os.Stat(dest); err != nil || info.Size() == 0 {
+		t.Fatalf("extracted file = %v, %v", info, err)
+	}
+
+	if err := os.WriteFile(dest, []byte("cached"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := extractOnce(src, dest, logger); err != nil {
+		t.Fatalf("second extractOnce = %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "cached" {
+		t.Errorf("second extractOnce rewrote the file")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("extract dir holds %d files, want only the library", len(entries))
+	}
+}
+
+func TestExtractOnceMissingSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := extractOnce("lib/missing.so.gz", filepath.Join(dir, "missing.so"), slog.New(slog.DiscardHandler)); err == nil {
+		t.Fatal("extractOnce of a missing library succeeded")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("failed extraction left %d files behind", len(entries))
+	}
+}
+
+func TestEmbeddedVersion(t *testing.T) {
+	libDir := filepath.Dir(embeddedLib(t))
+	first, err := embeddedVersion(libDir)
+	if err != nil {
+		t.Fatalf("embeddedVersion = %v", err)
+	}
+	second, _ := embeddedVersion(libDir)
+	if first != second || len(first) != 16 {
+		t.Errorf("embeddedVersion = %q then %q, want the same 16 hex digits", first, second)
+	}
+	if _, err := embeddedVersion("lib/missing"); err == nil {
+		t.Error("embeddedVersion of a missing directory succeeded")
+	}
+}
@@ -0,0 +1,232 @@
+package rustbert
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef struct CancelToken CancelToken;
+
+typedef struct {
+    void* model;
+} MaskedLanguageModelWrapper;
+
+typedef struct {
+    char* token;
+    int64_t id;
+    float score;
+} MaskCandidateItem;
+
+typedef struct {
+    MaskCandidateItem* candidates;
+    size_t count;
+} MaskPrediction;
+
+typedef struct {
+    MaskPrediction* masks;
+    size_t count;
+} FillMaskResult;
+
+typedef struct {
+    FillMaskResult* results;
+    size_t count;
+} FillMaskBatchResult;
+
+typedef MaskedLanguageModelWrapper* (*new_masked_language_model_t)(int);
+typedef MaskedLanguageModelWrapper* (*new_masked_language_model_from_files_t)(const char*, const char*, const char*, const char*, int, int);
+typedef FillMaskBatchResult* (*fill_mask_batch_t)(MaskedLanguageModelWrapper*, const char**, size_t, size_t, CancelToken*);
+typedef void (*free_masked_language_model_t)(MaskedLanguageModelWrapper*);
+typedef void (*free_fill_mask_batch_result_t)(FillMaskBatchResult*);
+
+MaskedLanguageModelWrapper* call_new_masked_language_model(void* f, int device) {
+    return ((new_masked_language_model_t)f)(device);
+}
+
+void* call_new_masked_language_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t, int device) {
+    return ((new_masked_language_model_from_files_t)f)(m, c, v, me, t, device);
+}
+
+FillMaskBatchResult* call_fill_mask_batch(
+    void* f,
+    MaskedLanguageModelWrapper* w,
+    const char** texts,
+    size_t count,
+    size_t top_k,
+    CancelToken* cancel
+) {
+    return ((fill_mask_batch_t)f)(w, texts, count, top_k, cancel);
+}
+
+void call_free_masked_language_model(void* f, MaskedLanguageModelWrapper* w) {
+    ((free_masked_language_model_t)f)(w);
+}
+
+void call_free_fill_mask_batch_result(void* f, FillMaskBatchResult* r) {
+    ((free_fill_mask_batch_result_t)f)(r);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+var (
+	fnNewMaskedLanguageModel          unsafe.Pointer
+	fnNewMaskedLanguageModelFromFiles unsafe.Pointer
+	fnFillMaskBatch                   unsafe.Pointer
+	fnFreeMaskedLanguageModel         unsafe.Pointer
+	fnFreeFillMaskBatchResult         unsafe.Pointer
+)
+
+// DefaultFillMaskTopK is the number of candidates FillMask returns per mask.
+const DefaultFillMaskTopK = 5
+
+// MaskCandidate is a token proposed for a masked position.
+type MaskCandidate struct {
+	// Token is the decoded token, without the word-piece markers of the tokenizer.
+	Token string
+	// ID is the vocabulary id of the token.
+	ID int64
+	// Score is the probability of the token at the masked position.
+	Score float64
+}
+
+// MaskedLanguageModel predicts the tokens hidden behind mask markers, e.g. to
+// generate variants of a sentence or probe what a fine-tuned checkpoint learned.
+//
+// Masks are written as "[MASK]" whatever the model; the model's own mask token
+// ("<mask>" for RoBERTa) is accepted too.
+type MaskedLanguageModel struct {
+	handle modelHandle[C.MaskedLanguageModelWrapper]
+}
+
+// NewMaskedLanguageModel creates a MaskedLanguageModel with BERT base (uncased).
+func NewMaskedLanguageModel() (*MaskedLanguageModel, error) {
+	return NewMaskedLanguageModelOnDevice(DeviceAuto)
+}
+
+// NewMaskedLanguageModelOnDevice is like NewMaskedLanguageModel but loads the model
+// on device.
+func NewMaskedLanguageModelOnDevice(device Device) (*MaskedLanguageModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_masked_language_model(fnNewMaskedLanguageModel, C.int(device))
+	if ptr == nil {
+		return nil, lastError("NewMaskedLanguageModel")
+	}
+	return &MaskedLanguageModel{handle: modelHandle[C.MaskedLanguageModelWrapper]{ptr: ptr}}, nil
+}
+
+// NewMaskedLanguageModelFromFiles creates a MaskedLanguageModel using local files of a
+// checkpoint with a masked language modeling head.
+// See NewSentimentModelFromFiles for the expected files and error conditions.
+func NewMaskedLanguageModelFromFiles(modelPath, configPath, vocabPath, mergesPath string, modelType int) (*MaskedLanguageModel, error) {
+	return NewMaskedLanguageModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath, modelType, DeviceAuto)
+}
+
+// NewMaskedLanguageModelFromFilesOnDevice is like NewMaskedLanguageModelFromFiles but
+// loads the model on device.
+func NewMaskedLanguageModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath string, modelType int, device Device) (*MaskedLanguageModel, error) {
+	ptr, err := callNewModelFromFiles("NewMaskedLanguageModelFromFiles", fnNewMaskedLanguageModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return C.call_new_masked_language_model_from_files(fn, m, c, v, me, t, C.int(device))
+	}, modelPath, configPath, vocabPath, mergesPath, modelType)
+	if err != nil {
+		return nil, err
+	}
+	return &MaskedLanguageModel{handle: modelHandle[C.MaskedLanguageModelWrapper]{ptr: (*C.MaskedLanguageModelWrapper)(ptr)}}, nil
+}
+
+// FillMask returns the DefaultFillMaskTopK most likely tokens of every mask of text,
+// one slice per mask in order of appearance, most likely token first.
+func (m *MaskedLanguageModel) FillMask(text string) ([][]MaskCandidate, error) {
+	return m.FillMaskContext(context.Background(), text)
+}
+
+// FillMaskContext is like FillMask but returns ctx.Err() as soon as ctx is done.
+func (m *MaskedLanguageModel) FillMaskContext(ctx context.Context, text string) ([][]MaskCandidate, error) {
+	results, err := m.FillMaskBatchContext(ctx, []string{text}, DefaultFillMaskTopK)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// FillMaskBatch returns the topK most likely tokens of every mask of several texts in
+// a single forward pass. The predictions of texts[i] are returned at index i.
+func (m *MaskedLanguageModel) FillMaskBatch(texts []string, topK int) ([][][]MaskCandidate, error) {
+	return m.FillMaskBatchContext(context.Background(), texts, topK)
+}
+
+// FillMaskBatchContext is like FillMaskBatch but stops once ctx is done, returning ctx.Err().
+func (m *MaskedLanguageModel) FillMaskBatchContext(ctx context.Context, texts []string, topK int) ([][][]MaskCandidate, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if topK < 1 {
+		return nil, invalidInput("MaskedLanguageModel.FillMaskBatch", fmt.Sprintf("topK must be at least 1, got %d", topK))
+	}
+	if len(texts) == 0 {
+		return [][][]MaskCandidate{}, nil
+	}
+
+	return withContext(ctx, &m.handle, func(ptr *C.MaskedLanguageModelWrapper, cancel *C.CancelToken) ([][][]MaskCandidate, error) {
+		cTexts := cStringArray(texts)
+		defer freeCStringArray(cTexts)
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_fill_mask_batch(fnFillMaskBatch, ptr, &cTexts[0], C.size_t(len(cTexts)), C.size_t(topK), cancel)
+		if res == nil {
+			return nil, lastError("MaskedLanguageModel.FillMaskBatch")
+		}
+		defer C.call_free_fill_mask_batch_result(fnFreeFillMaskBatchResult, res)
+
+		cResults := unsafe.Slice(res.results, int(res.count))
+		results := make([][][]MaskCandidate, len(cResults))
+		for i := range cResults {
+			results[i] = maskPredictions(&cResults[i])
+		}
+		return results, nil
+	})
+}
+
+func maskPredictions(res *C.FillMaskResult) [][]MaskCandidate {
+	cMasks := unsafe.Slice(res.masks, int(res.count))
+	masks := make([][]MaskCandidate, len(cMasks))
+	for i, mask := range cMasks {
+		cCandidates := unsafe.Slice(mask.candidates, int(mask.count))
+		candidates := make([]MaskCandidate, len(cCandidates))
+		for j, c := range cCandidates {
+			candidates[j] = MaskCandidate{
+				Token: C.GoString(c.token),
+				ID:    int64(c.id),
+				Score: float64(c.score),
+			}
+		}
+		masks[i] = candidates
+	}
+	return masks
+}
+
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
+func (m *MaskedLanguageModel) Close() {
+	m.handle.close(func(ptr *C.MaskedLanguageModelWrapper) {
+		C.call_free_masked_language_model(fnFreeMaskedLanguageModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *MaskedLanguageModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
+}
@@ -0,0 +1,348 @@
+package rustbert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrPoolTimeout is returned by Pool.Get when no instance became available within
+// PoolOptions.MaxWait.
+var ErrPoolTimeout = errors.New("rustbert: timed out waiting for a pooled model")
+
+// Model is implemented by every model type of this package. A Pool tells its
+// instances apart by identity, hence the comparable constraint, which the pointer
+// model types satisfy.
+type Model interface {
+	comparable
+	Close()
+}
+
+// PoolOptions configures a Pool.
+type PoolOptions struct {
+	// MaxWait bounds how long Get waits for a free instance before returning
+	// ErrPoolTimeout. 0 waits until the context passed to Get is done.
+	MaxWait time.Duration
+}
+
+// PoolStats is a snapshot of the activity of a Pool.
+type PoolStats struct {
+	// Size is the number of instances owned by the pool.
+	Size int
+	// InUse is the number of instances currently handed out.
+	InUse int
+	// Waiting is the number of Get calls currently waiting for an instance.
+	Waiting int
+	// Acquired is the number of instances handed out since the pool was created.
+	Acquired uint64
+	// Timeouts is the number of Get calls that gave up, through MaxWait or their context.
+	Timeouts uint64
+	// WaitTime is the total time Get calls spent waiting.
+	WaitTime time.Duration
+	// Utilization is the fraction of instance time spent handed out since the pool
+	// was created, between 0 and 1.
+	Utilization float64
+}
+
+// Pool owns several instances of a model and hands them out to goroutines, so that
+// inference runs in parallel across instances while each instance is used by one
+// goroutine at a time.
+//
+//	pool, err := rustbert.NewPool(4, rustbert.NewSentimentModel, rustbert.PoolOptions{MaxWait: time.Second})
+//	...
+//	err = pool.Do(ctx, func(m *rustbert.SentimentModel) error {
+//		result, err = m.PredictContext(ctx, text)
+//		return err
+//	})
+//
+// A Pool is safe for concurrent use.
+type Pool[M Model] struct {
+	all     []M
+	idle    chan M
+	done    chan struct{}
+	maxWait time.Duration
+	// warmup admits one Warmup call at a time.
+	warmup chan struct{}
+	// warmed hands the instances in cold over to the running Warmup when returned.
+	warmed chan M
+
+	mu     sync.Mutex
+	closed bool
+	// out holds the instances handed out.
+	out map[M]bool
+	// cold holds the instances the running Warmup has not reached yet, nil when no
+	// Warmup runs.
+	cold       map[M]bool
+	size       int
+	inUse      int
+	waiting    int
+	acquired   uint64
+	timeouts   uint64
+	waitTime   time.Duration
+	busyTime   time.Duration
+	lastChange time.Time
+	created    time.Time
+}
+
+// NewPool creates size instances with newModel, typically one of the New*Model
+// functions. If one of them fails, the instances already created are closed and the
+// error is returned.
+func NewPool[M Model](size int, newModel func() (M, error), opts PoolOptions) (*Pool[M], error) {
+	if size < 1 {
+		return nil, invalidInput("NewPool", fmt.Sprintf("pool size must be at least 1, got %d", size))
+	}
+
+	p := &Pool[M]{
+		idle:    make(chan M, size),
+		done:    make(chan struct{}),
+		maxWait: opts.MaxWait,
+		warmup:  make(chan struct{}, 1),
+		warmed:  make(chan M, size),
+		out:     make(map[M]bool, size),
+		size:    size,
+	}
+	for i := 0; i < size; i++ {
+		m, err := newModel()
+		if err != nil {
+			close(p.idle)
+			for m := range p.idle {
+				m.Close()
+			}
+			return nil, err
+		}
+		p.all = append(p.all, m)
+		p.idle <- m
+	}
+	p.created = time.Now()
+	p.lastChange = p.created
+	return p, nil
+}
+
+// Get waits for a free instance and hands it out. The instance must be returned with
+// Put. Get fails with ctx.Err() when ctx is done, ErrPoolTimeout after
+// PoolOptions.MaxWait and ErrClosed once the pool is closed.
+func (p *Pool[M]) Get(ctx context.Context) (M, error) {
+	var zero M
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return zero, ErrClosed
+	}
+	p.waiting++
+	p.mu.Unlock()
+
+	start := time.Now()
+	var timeout <-chan time.Time
+	if p.maxWait > 0 {
+		timer := time.NewTimer(p.maxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var err error
+	select {
+	case m := <-p.idle:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.waiting--
+		p.waitTime += time.Since(start)
+		if p.closed {
+			// Close is collecting the instances, give this one back to it
+			p.idle <- m
+			return zero, ErrClosed
+		}
+		p.acquire(m)
+		return m, nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timeout:
+		err = ErrPoolTimeout
+	case <-p.done:
+		err = ErrClosed
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.waiting--
+	p.waitTime += time.Since(start)
+	if err != ErrClosed {
+		p.timeouts++
+	}
+	return zero, err
+}
+
+// Put returns an instance obtained from Get to the pool. It panics when m is not
+// handed out, as when it is returned twice.
+func (p *Pool[M]) Put(m M) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.out[m] {
+		panic("rustbert: Put of a model not obtained from the pool")
+	}
+	if p.cold[m] {
+		// The running Warmup takes the instance over, still handed out
+		delete(p.cold, m)
+		p.acquired++
+		p.warmed <- m
+		return
+	}
+	delete(p.out, m)
+	p.updateBusyTime()
+	p.inUse--
+	p.idle <- m
+}
+
+// Do runs f with an instance from the pool, returning it afterwards. Errors from Get
+// are returned without calling f.
+func (p *Pool[M]) Do(ctx context.Context, f func(m M) error) error {
+	m, err := p.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.Put(m)
+	return f(m)
+}
+
+// Warmup runs f once on every instance, e.g. to run a first inference before serving
+// traffic. Idle instances are warmed up in parallel and the ones in use once they are
+// returned; each goes back to the pool as soon as f returns, so that Warmup never
+// holds instances other callers wait for. Warmup calls run one at a time. The first
+// error f returned is reported, else ctx.Err() or ErrClosed when Warmup gives up on
+// the instances still in use.
+func (p *Pool[M]) Warmup(ctx context.Context, f func(m M) error) error {
+	select {
+	case p.warmup <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.done:
+		return ErrClosed
+	}
+	defer func() { <-p.warmup }()
+
+	errs := make(chan error, p.size)
+	started := 0
+	run := func(m M) {
+		started++
+		go func() {
+			err := f(m)
+			p.Put(m)
+			errs <- err
+		}()
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrClosed
+	}
+	p.cold = make(map[M]bool, p.size)
+	for _, m := range p.all {
+		p.cold[m] = true
+	}
+	for drained := false; !drained; {
+		select {
+		case m := <-p.idle:
+			delete(p.cold, m)
+			p.acquire(m)
+			run(m)
+		default:
+			drained = true
+		}
+	}
+	p.mu.Unlock()
+
+	var err error
+	for started < p.size && err == nil {
+		select {
+		case m := <-p.warmed:
+			run(m)
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-p.done:
+			err = ErrClosed
+		}
+	}
+
+	p.mu.Lock()
+	p.cold = nil
+	p.mu.Unlock()
+	// Instances handed over while giving up go back to the pool
+	for drained := false; !drained; {
+		select {
+		case m := <-p.warmed:
+			p.Put(m)
+		default:
+			drained = true
+		}
+	}
+
+	var ferr error
+	for i := 0; i < started; i++ {
+		if e := <-errs; e != nil && ferr == nil {
+			ferr = e
+		}
+	}
+	if ferr != nil {
+		return ferr
+	}
+	return err
+}
+
+// Stats returns a snapshot of the pool activity.
+func (p *Pool[M]) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.updateBusyTime()
+	var utilization float64
+	if elapsed := time.Since(p.created); elapsed > 0 {
+		utilization = float64(p.busyTime) / (float64(elapsed) * float64(p.size))
+	}
+	return PoolStats{
+		Size:        p.size,
+		InUse:       p.inUse,
+		Waiting:     p.waiting,
+		Acquired:    p.acquired,
+		Timeouts:    p.timeouts,
+		WaitTime:    p.waitTime,
+		Utilization: utilization,
+	}
+}
+
+// acquire records m as handed out. p.mu must be held.
+func (p *Pool[M]) acquire(m M) {
+	p.updateBusyTime()
+	p.inUse++
+	p.acquired++
+	p.out[m] = true
+}
+
+// updateBusyTime accounts the instance time spent in use since the last change of
+// inUse. p.mu must be held.
+func (p *Pool[M]) updateBusyTime() {
+	now := time.Now()
+	p.busyTime += time.Duration(p.inUse) * now.Sub(p.lastChange)
+	p.lastChange = now
+}
+
+// Close waits for the instances in use to be returned and closes all of them. Waiting
+// and later Get calls return ErrClosed. Calling Close again is a no-op.
+func (p *Pool[M]) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.done)
+	p.mu.Unlock()
+
+	for i := 0; i < p.size; i++ {
+		m := <-p.idle
+		m.Close()
+	}
+}
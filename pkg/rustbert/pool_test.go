@@ -0,0 +1,279 @@
+package rustbert
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeModel struct {
+	id     int
+	closed atomic.Bool
+	warm   atomic.Bool
+}
+
+func (m *fakeModel) Close() {
+	m.closed.Store(true)
+}
+
+func newFakeModels() (func() (*fakeModel, error), *[]*fakeModel) {
+	var models []*fakeModel
+	return func() (*fakeModel, error) {
+		m := &fakeModel{id: len(models)}
+		models = append(models, m)
+		return m, nil
+	}, &models
+}
+
+func TestPool(t *testing.T) {
+	newModel, models := newFakeModels()
+	pool, err := NewPool(3, newModel, PoolOptions{})
+	if err != nil {
+		t.Fatalf("NewPool error = %v", err)
+	}
+
+	err = pool.Warmup(context.Background(), func(m *fakeModel) error {
+		m.warm.Store(true)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Warmup error = %v", err)
+	}
+	for _, m := range *models {
+		if !m.warm.Load() {
+			t.Errorf("model %d was not warmed up", m.id)
+		}
+	}
+
+	// Each instance is used by one goroutine at a time
+	var inUse sync.Map
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := pool.Do(context.Background(), func(m *fakeModel) error {
+				if _, busy := inUse.LoadOrStore(m.id, true); busy {
+					t.Errorf("model %d handed out twice", m.id)
+				}
+				time.Sleep(time.Millisecond)
+				inUse.Delete(m.id)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Do error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := pool.Stats()
+	if stats.Size != 3 || stats.InUse != 0 || stats.Waiting != 0 {
+		t.Errorf("Stats = %+v, want Size 3 and nothing in use or waiting", stats)
+	}
+	if stats.Acquired != 32+3 {
+		t.Errorf("Acquired = %d, want %d", stats.Acquired, 32+3)
+	}
+	if stats.Utilization <= 0 || stats.Utilization > 1 {
+		t.Errorf("Utilization = %f, want in (0, 1]", stats.Utilization)
+	}
+
+	pool.Close()
+	for _, m := range *models {
+		if !m.closed.Load() {
+			t.Errorf("model %d was not closed", m.id)
+		}
+	}
+	if _, err := pool.Get(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Get after Close: got %v, want ErrClosed", err)
+	}
+	pool.Close()
+}
+
+func TestPoolBoundedWait(t *testing.T) {
+	newModel, _ := newFakeModels()
+	pool, err := NewPool(1, newModel, PoolOptions{MaxWait: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewPool error = %v", err)
+	}
+
+	m, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get error = %v", err)
+	}
+	if _, err := pool.Get(context.Background()); !errors.Is(err, ErrPoolTimeout) {
+		t.Errorf("Get on an exhausted pool: got %v, want ErrPoolTimeout", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := pool.Get(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get with cancelled context: got %v, want context.Canceled", err)
+	}
+	if stats := pool.Stats(); stats.InUse != 1 || stats.Timeouts != 2 {
+		t.Errorf("Stats = %+v, want InUse 1 and Timeouts 2", stats)
+	}
+
+	pool.Put(m)
+	pool.Close()
+}
+
+func TestPoolCloseWaitsForInstances(t *testing.T) {
+	newModel, _ := newFakeModels()
+	pool, err := NewPool(1, newModel, PoolOptions{})
+	if err != nil {
+		t.Fatalf("NewPool error = %v", err)
+	}
+
+	m, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get error = %v", err)
+	}
+	waitErr := make(chan error)
+	go func() {
+		_, err := pool.Get(context.Background())
+		waitErr <- err
+	}()
+	for pool.Stats().Waiting == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		pool.Close()
+		close(closed)
+	}()
+	if err := <-waitErr; !errors.Is(err, ErrClosed) {
+		t.Errorf("Get waiting during Close: got %v, want ErrClosed", err)
+	}
+	select {
+	case <-closed:
+		t.Fatal("Close returned while an instance was in use")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	pool.Put(m)
+	<-closed
+	if !m.closed.Load() {
+		t.Error("returned instance was not closed")
+	}
+}
+
+func TestPoolConstructorError(t *testing.T) {
+	newModel, models := newFakeModels()
+	failing := func() (*fakeModel, error) {
+		if len(*models) == 2 {
+			return nil, errors.New("out of memory")
+		}
+		return newModel()
+	}
+
+	if _, err := NewPool(4, failing, PoolOptions{}); err == nil {
+		t.Fatal("NewPool succeeded with a failing constructor")
+	}
+	for _, m := range *models {
+		if !m.closed.Load() {
+			t.Errorf("model %d leaked after a failed NewPool", m.id)
+		}
+	}
+
+	if _, err := NewPool(0, newModel, PoolOptions{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("NewPool(0): got %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestPoolWarmupReleasesInstances(t *testing.T) {
+	newModel, models := newFakeModels()
+	pool, err := NewPool(2, newModel, PoolOptions{})
+	if err != nil {
+		t.Fatalf("NewPool error = %v", err)
+	}
+	defer pool.Close()
+
+	held, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get error = %v", err)
+	}
+
+	// Concurrent warmups neither hold the idle instance nor deadlock on the held one
+	var warmups atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := pool.Warmup(context.Background(), func(m *fakeModel) error {
+				warmups.Add(1)
+				m.warm.Store(true)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Warmup error = %v", err)
+			}
+		}()
+	}
+	for warmups.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := pool.Do(ctx, func(m *fakeModel) error { return nil }); err != nil {
+		t.Fatalf("Do during Warmup: got %v, want an instance", err)
+	}
+
+	pool.Put(held)
+	wg.Wait()
+	for _, m := range *models {
+		if !m.warm.Load() {
+			t.Errorf("model %d was not warmed up", m.id)
+		}
+	}
+	if got := warmups.Load(); got != 4 {
+		t.Errorf("f ran %d times, want once per instance and Warmup", got)
+	}
+
+	// A Warmup giving up on an instance in use leaves the pool usable
+	held, _ = pool.Get(context.Background())
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.Warmup(ctx, func(m *fakeModel) error { return nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Warmup with an instance in use: got %v, want context.DeadlineExceeded", err)
+	}
+	pool.Put(held)
+	if stats := pool.Stats(); stats.InUse != 0 {
+		t.Errorf("InUse = %d after Warmup gave up, want 0", stats.InUse)
+	}
+}
+
+func TestPoolPutMisuse(t *testing.T) {
+	newModel, _ := newFakeModels()
+	pool, err := NewPool(1, newModel, PoolOptions{})
+	if err != nil {
+		t.Fatalf("NewPool error = %v", err)
+	}
+	defer pool.Close()
+
+	m, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get error = %v", err)
+	}
+	pool.Put(m)
+
+	for name, m := range map[string]*fakeModel{"returned twice": m, "foreign": {id: -1}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Put of a model %s did not panic", name)
+				}
+			}()
+			pool.Put(m)
+		}()
+	}
+	if stats := pool.Stats(); stats.InUse != 0 {
+		t.Errorf("InUse = %d after misuse, want 0", stats.InUse)
+	}
+}
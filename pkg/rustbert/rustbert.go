@@ -1,12 +1,23 @@
 package rustbert
 
 /*
+#include <stdbool.h>
+#include <stdint.h>
 #include <stdlib.h>
 
+typedef struct CancelToken CancelToken;
+
+typedef struct {
+    size_t size;
+    size_t stride;
+    int aggregation;
+} WindowOptions;
+
 // --- Sentiment Analysis ---
 
 typedef struct {
     void* model;
+    void* windower;
 } SentimentModelWrapper;
 
 typedef struct {
@@ -14,6 +25,11 @@ typedef struct {
     float score;
 } SentimentResult;
 
+typedef struct {
+    SentimentResult* results;
+    size_t count;
+} SentimentBatchResult;
+
 // --- POS Tagging ---
 
 typedef struct {
@@ -31,10 +47,16 @@ typedef struct {
     size_t count;
 } POSResult;
 
+typedef struct {
+    POSResult* results;
+    size_t count;
+} POSBatchResult;
+
 // --- NER ---
 
 typedef struct {
     void* model;
+    void* windower;
 } NERModelWrapper;
 
 typedef struct {
@@ -50,10 +72,29 @@ typedef struct {
     size_t count;
 } NERResult;
 
+typedef struct {
+    NERResult* results;
+    size_t count;
+} NERBatchResult;
+
 // --- Question Answering ---
 
+typedef struct {
+    int64_t top_k;
+    double min_score;
+} QAOptions;
+
+typedef struct {
+    size_t max_seq_length;
+    size_t doc_stride;
+    size_t max_query_length;
+    size_t max_answer_length;
+    QAOptions defaults;
+} QAModelOptions;
+
 typedef struct {
     void* model;
+    QAOptions options;
 } QAModelWrapper;
 
 typedef struct {
@@ -68,17 +109,42 @@ typedef struct {
     size_t count;
 } QAResult;
 
+typedef struct {
+    QAResult* results;
+    size_t count;
+} QABatchResult;
+
 // --- Summarization ---
 
 typedef struct {
     void* model;
+    int64_t seed;
 } SummarizationModelWrapper;
 
+typedef struct {
+    int64_t min_length;
+    int64_t max_length;
+    int64_t num_beams;
+    double length_penalty;
+    int64_t no_repeat_ngram_size;
+} SummarizeOverrides;
+
 typedef struct {
     char** summaries;
     size_t count;
 } SummarizationResult;
 
+typedef struct {
+    size_t chunk_size;
+    size_t target_length;
+} LongSummarizeOptions;
+
+typedef struct {
+    char* summary;
+    char** chunk_summaries;
+    size_t chunk_count;
+} LongSummaryResult;
+
 // --- Zero-Shot Classification ---
 
 typedef struct {
@@ -95,6 +161,17 @@ typedef struct {
     size_t count;
 } ZeroShotResult;
 
+typedef struct {
+    ZeroShotResult* results;
+    size_t count;
+} ZeroShotBatchResult;
+
+typedef struct {
+    const char* template;
+    bool multi_label;
+    size_t max_length;
+} ZeroShotOptions;
+
 // --- Translation ---
 
 typedef struct {
@@ -105,63 +182,115 @@ typedef struct {
 
 typedef struct {
     void* model;
+    int64_t seed;
 } TextGenerationModelWrapper;
 
+typedef struct {
+    int64_t max_length;
+    int64_t min_length;
+    bool do_sample;
+    bool early_stopping;
+    double temperature;
+    int64_t top_k;
+    double top_p;
+    double repetition_penalty;
+    int64_t no_repeat_ngram_size;
+    int64_t num_beams;
+    int64_t num_return_sequences;
+    double length_penalty;
+    int64_t seed;
+} GenerateOptions;
+
+// --- Shared ---
+
+typedef struct {
+    char** items;
+    size_t count;
+} StringArray;
+
 // Function pointer typedefs
-typedef SentimentModelWrapper* (*new_sentiment_model_t)();
+typedef SentimentModelWrapper* (*new_sentiment_model_t)(int);
 typedef SentimentResult* (*predict_sentiment_t)(SentimentModelWrapper*, const char*);
 typedef void (*free_sentiment_model_t)(SentimentModelWrapper*);
 typedef void (*free_sentiment_result_t)(SentimentResult*);
+typedef SentimentBatchResult* (*predict_sentiment_batch_t)(SentimentModelWrapper*, const char**, size_t, CancelToken*);
+typedef SentimentResult* (*predict_sentiment_long_t)(SentimentModelWrapper*, const char*, const WindowOptions*, CancelToken*);
+typedef void (*free_sentiment_batch_result_t)(SentimentBatchResult*);
 
-typedef POSModelWrapper* (*new_pos_model_t)();
+typedef POSModelWrapper* (*new_pos_model_t)(int);
 typedef POSResult* (*predict_pos_t)(POSModelWrapper*, const char*);
 typedef void (*free_pos_model_t)(POSModelWrapper*);
 typedef void (*free_pos_result_t)(POSResult*);
+typedef POSBatchResult* (*predict_pos_batch_t)(POSModelWrapper*, const char**, size_t, CancelToken*);
+typedef void (*free_pos_batch_result_t)(POSBatchResult*);
 
-typedef NERModelWrapper* (*new_ner_model_t)();
+typedef NERModelWrapper* (*new_ner_model_t)(int);
 typedef NERResult* (*predict_ner_t)(NERModelWrapper*, const char*);
 typedef void (*free_ner_model_t)(NERModelWrapper*);
 typedef void (*free_ner_result_t)(NERResult*);
+typedef NERBatchResult* (*predict_ner_batch_t)(NERModelWrapper*, const char**, size_t, CancelToken*);
+typedef NERResult* (*predict_ner_long_t)(NERModelWrapper*, const char*, const WindowOptions*, CancelToken*);
+typedef void (*free_ner_batch_result_t)(NERBatchResult*);
 
-typedef QAModelWrapper* (*new_qa_model_t)();
+typedef QAModelWrapper* (*new_qa_model_t)(int);
 typedef QAResult* (*predict_qa_t)(QAModelWrapper*, const char*, const char*);
 typedef void (*free_qa_model_t)(QAModelWrapper*);
 typedef void (*free_qa_result_t)(QAResult*);
+typedef QABatchResult* (*predict_qa_batch_t)(QAModelWrapper*, const char**, const char**, size_t, CancelToken*);
+typedef void (*free_qa_batch_result_t)(QABatchResult*);
+typedef QAModelWrapper* (*new_qa_model_with_options_t)(const QAModelOptions*, int);
+typedef QABatchResult* (*predict_qa_batch_with_options_t)(QAModelWrapper*, const char**, const char**, size_t, const QAOptions*, CancelToken*);
 
-typedef SummarizationModelWrapper* (*new_summarization_model_t)();
+typedef SummarizationModelWrapper* (*new_summarization_model_t)(int);
 typedef SummarizationResult* (*summarize_t)(SummarizationModelWrapper*, const char*);
 typedef void (*free_summarization_model_t)(SummarizationModelWrapper*);
 typedef void (*free_summarization_result_t)(SummarizationResult*);
+typedef SummarizationResult* (*summarize_batch_t)(SummarizationModelWrapper*, const char**, size_t, const SummarizeOverrides*, CancelToken*);
+typedef LongSummaryResult* (*summarize_long_t)(SummarizationModelWrapper*, const char*, const LongSummarizeOptions*, const SummarizeOverrides*, CancelToken*);
+typedef void (*free_long_summary_result_t)(LongSummaryResult*);
+typedef SummarizationModelWrapper* (*new_summarization_model_with_config_t)(int, const GenerateOptions*, int);
 
-typedef ZeroShotClassificationModelWrapper* (*new_zero_shot_model_t)();
+typedef ZeroShotClassificationModelWrapper* (*new_zero_shot_model_t)(int);
 typedef ZeroShotResult* (*predict_zero_shot_t)(ZeroShotClassificationModelWrapper*, const char*, const char**, size_t);
 typedef void (*free_zero_shot_model_t)(ZeroShotClassificationModelWrapper*);
 typedef void (*free_zero_shot_result_t)(ZeroShotResult*);
+typedef ZeroShotBatchResult* (*predict_zero_shot_batch_t)(ZeroShotClassificationModelWrapper*, const char**, size_t, const char**, size_t, CancelToken*);
+typedef ZeroShotBatchResult* (*predict_zero_shot_with_options_t)(ZeroShotClassificationModelWrapper*, const char**, size_t, const char**, size_t, const ZeroShotOptions*, CancelToken*);
+typedef void (*free_zero_shot_batch_result_t)(ZeroShotBatchResult*);
 
-typedef TranslationModelWrapper* (*new_translation_model_t)();
+typedef TranslationModelWrapper* (*new_translation_model_t)(int);
+typedef TranslationModelWrapper* (*new_translation_model_with_builder_t)(int, const char**, size_t, const char**, size_t, int);
 typedef char* (*translate_t)(TranslationModelWrapper*, const char*, const char*, const char*);
 typedef void (*free_translation_model_t)(TranslationModelWrapper*);
+typedef StringArray* (*translate_batch_t)(TranslationModelWrapper*, const char**, size_t, const char*, const char*, CancelToken*);
 
-typedef SentimentModelWrapper* (*new_sentiment_model_from_files_t)(const char*, const char*, const char*, const char*, int);
+typedef SentimentModelWrapper* (*new_sentiment_model_from_files_t)(const char*, const char*, const char*, const char*, int, int);
 
-typedef TextGenerationModelWrapper* (*new_text_generation_model_t)();
+typedef TextGenerationModelWrapper* (*new_text_generation_model_t)(int);
+typedef TextGenerationModelWrapper* (*new_text_generation_model_with_options_t)(const GenerateOptions*, int);
 typedef char* (*generate_text_t)(TextGenerationModelWrapper*, const char*, const char*);
 typedef void (*free_text_generation_model_t)(TextGenerationModelWrapper*);
+typedef StringArray* (*generate_text_batch_t)(TextGenerationModelWrapper*, const char**, size_t, const char*, CancelToken*);
+
+typedef void (*free_string_array_t)(StringArray*);
 
-typedef NERModelWrapper* (*new_ner_model_from_files_t)(const char*, const char*, const char*, const char*, int);
-typedef QAModelWrapper* (*new_qa_model_from_files_t)(const char*, const char*, const char*, const char*, int);
-typedef SummarizationModelWrapper* (*new_summarization_model_from_files_t)(const char*, const char*, const char*, const char*, int);
-typedef ZeroShotClassificationModelWrapper* (*new_zero_shot_model_from_files_t)(const char*, const char*, const char*, const char*, int);
-typedef TranslationModelWrapper* (*new_translation_model_from_files_t)(const char*, const char*, const char*, const char*, int);
-typedef TextGenerationModelWrapper* (*new_text_generation_model_from_files_t)(const char*, const char*, const char*, const char*, int);
+typedef NERModelWrapper* (*new_ner_model_from_files_t)(const char*, const char*, const char*, const char*, int, int);
+typedef QAModelWrapper* (*new_qa_model_from_files_t)(const char*, const char*, const char*, const char*, int, int);
+typedef QAModelWrapper* (*new_qa_model_from_files_with_options_t)(const char*, const char*, const char*, const char*, int, const QAModelOptions*, int);
+typedef SummarizationModelWrapper* (*new_summarization_model_from_files_t)(const char*, const char*, const char*, const char*, int, int);
+typedef SummarizationModelWrapper* (*new_summarization_model_from_files_with_config_t)(const char*, const char*, const char*, const char*, int, const GenerateOptions*, int);
+typedef ZeroShotClassificationModelWrapper* (*new_zero_shot_model_from_files_t)(const char*, const char*, const char*, const char*, int, int);
+typedef TranslationModelWrapper* (*new_translation_model_from_files_t)(const char*, const char*, const char*, const char*, int, int);
+typedef TextGenerationModelWrapper* (*new_text_generation_model_from_files_t)(const char*, const char*, const char*, const char*, int, int);
+typedef TextGenerationModelWrapper* (*new_text_generation_model_from_files_with_options_t)(const char*, const char*, const char*, const char*, int, const GenerateOptions*, int);
 
 // Helpers to call function pointers from C
-SentimentModelWrapper* call_new_sentiment_model(void* f) {
-    return ((new_sentiment_model_t)f)();
+SentimentModelWrapper* call_new_sentiment_model(void* f, int device) {
+    return ((new_sentiment_model_t)f)(device);
 }
 
-SentimentModelWrapper* call_new_sentiment_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t) {
-    return ((new_sentiment_model_from_files_t)f)(m, c, v, me, t);
+SentimentModelWrapper* call_new_sentiment_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t, int device) {
+    return ((new_sentiment_model_from_files_t)f)(m, c, v, me, t, device);
 }
 
 SentimentResult* call_predict_sentiment(void* f, SentimentModelWrapper* w, const char* text) {
@@ -176,8 +305,20 @@ void call_free_sentiment_result(void* f, SentimentResult* r) {
     ((free_sentiment_result_t)f)(r);
 }
 
-POSModelWrapper* call_new_pos_model(void* f) {
-    return ((new_pos_model_t)f)();
+SentimentBatchResult* call_predict_sentiment_batch(void* f, SentimentModelWrapper* w, const char** texts, size_t count, CancelToken* cancel) {
+    return ((predict_sentiment_batch_t)f)(w, texts, count, cancel);
+}
+
+void call_free_sentiment_batch_result(void* f, SentimentBatchResult* r) {
+    ((free_sentiment_batch_result_t)f)(r);
+}
+
+SentimentResult* call_predict_sentiment_long(void* f, SentimentModelWrapper* w, const char* text, const WindowOptions* o, CancelToken* cancel) {
+    return ((predict_sentiment_long_t)f)(w, text, o, cancel);
+}
+
+POSModelWrapper* call_new_pos_model(void* f, int device) {
+    return ((new_pos_model_t)f)(device);
 }
 
 POSResult* call_predict_pos(void* f, POSModelWrapper* w, const char* text) {
@@ -192,8 +333,16 @@ void call_free_pos_result(void* f, POSResult* r) {
     ((free_pos_result_t)f)(r);
 }
 
-NERModelWrapper* call_new_ner_model(void* f) {
-    return ((new_ner_model_t)f)();
+POSBatchResult* call_predict_pos_batch(void* f, POSModelWrapper* w, const char** texts, size_t count, CancelToken* cancel) {
+    return ((predict_pos_batch_t)f)(w, texts, count, cancel);
+}
+
+void call_free_pos_batch_result(void* f, POSBatchResult* r) {
+    ((free_pos_batch_result_t)f)(r);
+}
+
+NERModelWrapper* call_new_ner_model(void* f, int device) {
+    return ((new_ner_model_t)f)(device);
 }
 
 NERResult* call_predict_ner(void* f, NERModelWrapper* w, const char* text) {
@@ -208,8 +357,20 @@ void call_free_ner_result(void* f, NERResult* r) {
     ((free_ner_result_t)f)(r);
 }
 
-QAModelWrapper* call_new_qa_model(void* f) {
-    return ((new_qa_model_t)f)();
+NERBatchResult* call_predict_ner_batch(void* f, NERModelWrapper* w, const char** texts, size_t count, CancelToken* cancel) {
+    return ((predict_ner_batch_t)f)(w, texts, count, cancel);
+}
+
+void call_free_ner_batch_result(void* f, NERBatchResult* r) {
+    ((free_ner_batch_result_t)f)(r);
+}
+
+NERResult* call_predict_ner_long(void* f, NERModelWrapper* w, const char* text, const WindowOptions* o, CancelToken* cancel) {
+    return ((predict_ner_long_t)f)(w, text, o, cancel);
+}
+
+QAModelWrapper* call_new_qa_model(void* f, int device) {
+    return ((new_qa_model_t)f)(device);
 }
 
 QAResult* call_predict_qa(void* f, QAModelWrapper* w, const char* question, const char* context) {
@@ -224,8 +385,32 @@ void call_free_qa_result(void* f, QAResult* r) {
     ((free_qa_result_t)f)(r);
 }
 
-SummarizationModelWrapper* call_new_summarization_model(void* f) {
-    return ((new_summarization_model_t)f)();
+QABatchResult* call_predict_qa_batch(void* f, QAModelWrapper* w, const char** questions, const char** contexts, size_t count, CancelToken* cancel) {
+    return ((predict_qa_batch_t)f)(w, questions, contexts, count, cancel);
+}
+
+void call_free_qa_batch_result(void* f, QABatchResult* r) {
+    ((free_qa_batch_result_t)f)(r);
+}
+
+QAModelWrapper* call_new_qa_model_with_options(void* f, const QAModelOptions* o, int device) {
+    return ((new_qa_model_with_options_t)f)(o, device);
+}
+
+QABatchResult* call_predict_qa_batch_with_options(
+    void* f,
+    QAModelWrapper* w,
+    const char** questions,
+    const char** contexts,
+    size_t count,
+    const QAOptions* options,
+    CancelToken* cancel
+) {
+    return ((predict_qa_batch_with_options_t)f)(w, questions, contexts, count, options, cancel);
+}
+
+SummarizationModelWrapper* call_new_summarization_model(void* f, int device) {
+    return ((new_summarization_model_t)f)(device);
 }
 
 SummarizationResult* call_summarize(void* f, SummarizationModelWrapper* w, const char* text) {
@@ -240,8 +425,38 @@ void call_free_summarization_result(void* f, SummarizationResult* r) {
     ((free_summarization_result_t)f)(r);
 }
 
-ZeroShotClassificationModelWrapper* call_new_zero_shot_model(void* f) {
-    return ((new_zero_shot_model_t)f)();
+SummarizationResult* call_summarize_batch(
+    void* f,
+    SummarizationModelWrapper* w,
+    const char** texts,
+    size_t count,
+    const SummarizeOverrides* overrides,
+    CancelToken* cancel
+) {
+    return ((summarize_batch_t)f)(w, texts, count, overrides, cancel);
+}
+
+LongSummaryResult* call_summarize_long(
+    void* f,
+    SummarizationModelWrapper* w,
+    const char* text,
+    const LongSummarizeOptions* options,
+    const SummarizeOverrides* overrides,
+    CancelToken* cancel
+) {
+    return ((summarize_long_t)f)(w, text, options, overrides, cancel);
+}
+
+void call_free_long_summary_result(void* f, LongSummaryResult* r) {
+    ((free_long_summary_result_t)f)(r);
+}
+
+SummarizationModelWrapper* call_new_summarization_model_with_config(void* f, int kind, const GenerateOptions* o, int device) {
+    return ((new_summarization_model_with_config_t)f)(kind, o, device);
+}
+
+ZeroShotClassificationModelWrapper* call_new_zero_shot_model(void* f, int device) {
+    return ((new_zero_shot_model_t)f)(device);
 }
 
 ZeroShotResult* call_predict_zero_shot(
@@ -262,8 +477,49 @@ void call_free_zero_shot_result(void* f, ZeroShotResult* r) {
     ((free_zero_shot_result_t)f)(r);
 }
 
-TranslationModelWrapper* call_new_translation_model(void* f) {
-    return ((new_translation_model_t)f)();
+ZeroShotBatchResult* call_predict_zero_shot_batch(
+    void* f,
+    ZeroShotClassificationModelWrapper* w,
+    const char** texts,
+    size_t texts_count,
+    const char** labels,
+    size_t labels_count,
+    CancelToken* cancel
+) {
+    return ((predict_zero_shot_batch_t)f)(w, texts, texts_count, labels, labels_count, cancel);
+}
+
+void call_free_zero_shot_batch_result(void* f, ZeroShotBatchResult* r) {
+    ((free_zero_shot_batch_result_t)f)(r);
+}
+
+ZeroShotBatchResult* call_predict_zero_shot_with_options(
+    void* f,
+    ZeroShotClassificationModelWrapper* w,
+    const char** texts,
+    size_t texts_count,
+    const char** labels,
+    size_t labels_count,
+    const ZeroShotOptions* options,
+    CancelToken* cancel
+) {
+    return ((predict_zero_shot_with_options_t)f)(w, texts, texts_count, labels, labels_count, options, cancel);
+}
+
+TranslationModelWrapper* call_new_translation_model(void* f, int device) {
+    return ((new_translation_model_t)f)(device);
+}
+
+TranslationModelWrapper* call_new_translation_model_with_builder(
+    void* f,
+    int model_type,
+    const char** source_languages,
+    size_t source_count,
+    const char** target_languages,
+    size_t target_count,
+    int device
+) {
+    return ((new_translation_model_with_builder_t)f)(model_type, source_languages, source_count, target_languages, target_count, device);
 }
 
 char* call_translate(
@@ -276,12 +532,28 @@ char* call_translate(
     return ((translate_t)f)(w, text, source_lang, target_lang);
 }
 
+StringArray* call_translate_batch(
+    void* f,
+    TranslationModelWrapper* w,
+    const char** texts,
+    size_t count,
+    const char* source_lang,
+    const char* target_lang,
+    CancelToken* cancel
+) {
+    return ((translate_batch_t)f)(w, texts, count, source_lang, target_lang, cancel);
+}
+
 void call_free_translation_model(void* f, TranslationModelWrapper* w) {
     ((free_translation_model_t)f)(w);
 }
 
-TextGenerationModelWrapper* call_new_text_generation_model(void* f) {
-    return ((new_text_generation_model_t)f)();
+TextGenerationModelWrapper* call_new_text_generation_model(void* f, int device) {
+    return ((new_text_generation_model_t)f)(device);
+}
+
+TextGenerationModelWrapper* call_new_text_generation_model_with_options(void* f, const GenerateOptions* o, int device) {
+    return ((new_text_generation_model_with_options_t)f)(o, device);
 }
 
 char* call_generate_text(
@@ -293,40 +565,96 @@ char* call_generate_text(
     return ((generate_text_t)f)(w, prompt, prefix);
 }
 
+StringArray* call_generate_text_batch(
+    void* f,
+    TextGenerationModelWrapper* w,
+    const char** prompts,
+    size_t count,
+    const char* prefix,
+    CancelToken* cancel
+) {
+    return ((generate_text_batch_t)f)(w, prompts, count, prefix, cancel);
+}
+
 void call_free_text_generation_model(void* f, TextGenerationModelWrapper* w) {
     ((free_text_generation_model_t)f)(w);
 }
 
+void call_free_string_array(void* f, StringArray* a) {
+    ((free_string_array_t)f)(a);
+}
+
 // Helpers for custom loaders
-void* call_new_ner_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t) {
-    return ((new_ner_model_from_files_t)f)(m, c, v, me, t);
+void* call_new_ner_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t, int device) {
+    return ((new_ner_model_from_files_t)f)(m, c, v, me, t, device);
+}
+
+void* call_new_qa_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t, int device) {
+    return ((new_qa_model_from_files_t)f)(m, c, v, me, t, device);
+}
+
+void* call_new_qa_model_from_files_with_options(
+    void* f,
+    const char* m,
+    const char* c,
+    const char* v,
+    const char* me,
+    int t,
+    const QAModelOptions* o,
+    int device
+) {
+    return ((new_qa_model_from_files_with_options_t)f)(m, c, v, me, t, o, device);
+}
+
+void* call_new_summarization_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t, int device) {
+    return ((new_summarization_model_from_files_t)f)(m, c, v, me, t, device);
 }
 
-void* call_new_qa_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t) {
-    return ((new_qa_model_from_files_t)f)(m, c, v, me, t);
+void* call_new_summarization_model_from_files_with_config(
+    void* f,
+    const char* m,
+    const char* c,
+    const char* v,
+    const char* me,
+    int t,
+    const GenerateOptions* o,
+    int device
+) {
+    return ((new_summarization_model_from_files_with_config_t)f)(m, c, v, me, t, o, device);
 }
 
-void* call_new_summarization_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t) {
-    return ((new_summarization_model_from_files_t)f)(m, c, v, me, t);
+void* call_new_zero_shot_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t, int device) {
+    return ((new_zero_shot_model_from_files_t)f)(m, c, v, me, t, device);
 }
 
-void* call_new_zero_shot_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t) {
-    return ((new_zero_shot_model_from_files_t)f)(m, c, v, me, t);
+void* call_new_translation_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t, int device) {
+    return ((new_translation_model_from_files_t)f)(m, c, v, me, t, device);
 }
 
-void* call_new_translation_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t) {
-    return ((new_translation_model_from_files_t)f)(m, c, v, me, t);
+void* call_new_text_generation_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t, int device) {
+    return ((new_text_generation_model_from_files_t)f)(m, c, v, me, t, device);
 }
 
-void* call_new_text_generation_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t) {
-    return ((new_text_generation_model_from_files_t)f)(m, c, v, me, t);
+void* call_new_text_generation_model_from_files_with_options(
+    void* f,
+    const char* m,
+    const char* c,
+    const char* v,
+    const char* me,
+    int t,
+    const GenerateOptions* o,
+    int device
+) {
+    return ((new_text_generation_model_from_files_with_options_t)f)(m, c, v, me, t, o, device);
 }
 */
 import "C"
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"runtime"
+	"strings"
 	"unsafe"
 )
 
@@ -343,6 +671,12 @@ const (
 	ModelTypeMarian     = 8
 	ModelTypeT5         = 9
 	ModelTypeGPT2       = 10
+	ModelTypePegasus    = 11
+	ModelTypeProphetNet = 12
+	ModelTypeLongT5     = 13
+	ModelTypeM2M100     = 14
+	ModelTypeMBart      = 15
+	ModelTypeNLLB       = 16
 )
 
 var (
@@ -351,51 +685,80 @@ var (
 	fnPredictSentiment           unsafe.Pointer
 	fnFreeSentimentModel         unsafe.Pointer
 	fnFreeSentimentResult        unsafe.Pointer
+	fnPredictSentimentBatch      unsafe.Pointer
+	fnFreeSentimentBatchResult   unsafe.Pointer
+	fnPredictSentimentLong       unsafe.Pointer
 
-	fnNewPOSModel   unsafe.Pointer
-	fnPredictPOS    unsafe.Pointer
-	fnFreePOSModel  unsafe.Pointer
-	fnFreePOSResult unsafe.Pointer
+	fnNewPOSModel        unsafe.Pointer
+	fnPredictPOS         unsafe.Pointer
+	fnFreePOSModel       unsafe.Pointer
+	fnFreePOSResult      unsafe.Pointer
+	fnPredictPOSBatch    unsafe.Pointer
+	fnFreePOSBatchResult unsafe.Pointer
 
 	fnNewNERModel          unsafe.Pointer
 	fnNewNERModelFromFiles unsafe.Pointer
 	fnPredictNER           unsafe.Pointer
 	fnFreeNERModel         unsafe.Pointer
 	fnFreeNERResult        unsafe.Pointer
+	fnPredictNERBatch      unsafe.Pointer
+	fnFreeNERBatchResult   unsafe.Pointer
+	fnPredictNERLong       unsafe.Pointer
 
 	fnNewQAModel          unsafe.Pointer
 	fnNewQAModelFromFiles unsafe.Pointer
 	fnPredictQA           unsafe.Pointer
 	fnFreeQAModel         unsafe.Pointer
 	fnFreeQAResult        unsafe.Pointer
-
-	fnNewSummarizationModel          unsafe.Pointer
-	fnNewSummarizationModelFromFiles unsafe.Pointer
-	fnSummarize                      unsafe.Pointer
-	fnFreeSummarizationModel         unsafe.Pointer
-	fnFreeSummarizationResult        unsafe.Pointer
-
-	fnNewZeroShotModel          unsafe.Pointer
-	fnNewZeroShotModelFromFiles unsafe.Pointer
-	fnPredictZeroShot           unsafe.Pointer
-	fnFreeZeroShotModel         unsafe.Pointer
-	fnFreeZeroShotResult        unsafe.Pointer
-
-	fnNewTranslationModel          unsafe.Pointer
-	fnNewTranslationModelFromFiles unsafe.Pointer
-	fnTranslate                    unsafe.Pointer
-	fnFreeTranslationModel         unsafe.Pointer
-
-	fnNewTextGenerationModel          unsafe.Pointer
-	fnNewTextGenerationModelFromFiles unsafe.Pointer
-	fnGenerateText                    unsafe.Pointer
-	fnFreeTextGenerationModel         unsafe.Pointer
+	fnPredictQABatch      unsafe.Pointer
+	fnFreeQABatchResult   unsafe.Pointer
+
+	fnNewQAModelWithOptions          unsafe.Pointer
+	fnNewQAModelFromFilesWithOptions unsafe.Pointer
+	fnPredictQABatchWithOptions      unsafe.Pointer
+
+	fnNewSummarizationModel                    unsafe.Pointer
+	fnNewSummarizationModelFromFiles           unsafe.Pointer
+	fnSummarize                                unsafe.Pointer
+	fnFreeSummarizationModel                   unsafe.Pointer
+	fnFreeSummarizationResult                  unsafe.Pointer
+	fnSummarizeBatch                           unsafe.Pointer
+	fnNewSummarizationModelWithConfig          unsafe.Pointer
+	fnNewSummarizationModelFromFilesWithConfig unsafe.Pointer
+	fnSummarizeLong                            unsafe.Pointer
+	fnFreeLongSummaryResult                    unsafe.Pointer
+
+	fnNewZeroShotModel           unsafe.Pointer
+	fnNewZeroShotModelFromFiles  unsafe.Pointer
+	fnPredictZeroShot            unsafe.Pointer
+	fnFreeZeroShotModel          unsafe.Pointer
+	fnFreeZeroShotResult         unsafe.Pointer
+	fnPredictZeroShotBatch       unsafe.Pointer
+	fnFreeZeroShotBatchResult    unsafe.Pointer
+	fnPredictZeroShotWithOptions unsafe.Pointer
+
+	fnNewTranslationModel            unsafe.Pointer
+	fnNewTranslationModelWithBuilder unsafe.Pointer
+	fnNewTranslationModelFromFiles   unsafe.Pointer
+	fnTranslate                      unsafe.Pointer
+	fnFreeTranslationModel           unsafe.Pointer
+	fnTranslateBatch                 unsafe.Pointer
+
+	fnNewTextGenerationModel                     unsafe.Pointer
+	fnNewTextGenerationModelFromFiles            unsafe.Pointer
+	fnNewTextGenerationModelWithOptions          unsafe.Pointer
+	fnNewTextGenerationModelFromFilesWithOptions unsafe.Pointer
+	fnGenerateText                               unsafe.Pointer
+	fnFreeTextGenerationModel                    unsafe.Pointer
+	fnGenerateTextBatch                          unsafe.Pointer
+
+	fnFreeStringArray unsafe.Pointer
 )
 
 // Helper for calling *from_files functions which all have same signature
-func callNewModelFromFiles(fn unsafe.Pointer, helper func(unsafe.Pointer, *C.char, *C.char, *C.char, *C.char, C.int) unsafe.Pointer, modelPath, configPath, vocabPath, mergesPath string, modelType int) (unsafe.Pointer, error) {
-	if !initialized {
-		return nil, errors.New("library not initialized")
+func callNewModelFromFiles(op string, fn unsafe.Pointer, helper func(unsafe.Pointer, *C.char, *C.char, *C.char, *C.char, C.int) unsafe.Pointer, modelPath, configPath, vocabPath, mergesPath string, modelType int) (unsafe.Pointer, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
 	}
 
 	cModel := C.CString(modelPath)
@@ -411,16 +774,20 @@ func callNewModelFromFiles(fn unsafe.Pointer, helper func(unsafe.Pointer, *C.cha
 		defer C.free(unsafe.Pointer(cMerges))
 	}
 
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
 	ptr := helper(fn, cModel, cConfig, cVocab, cMerges, C.int(modelType))
 	if ptr == nil {
-		return nil, errors.New("failed to create custom model")
+		return nil, lastError(op)
 	}
+	logWarning(op)
 	return ptr, nil
 }
 
 // SentimentModel is a wrapper around the Rust sentiment analysis model
 type SentimentModel struct {
-	ptr *C.SentimentModelWrapper
+	handle modelHandle[C.SentimentModelWrapper]
 }
 
 // SentimentResult represents the output of sentiment analysis
@@ -431,116 +798,327 @@ type SentimentResult struct {
 
 // NewSentimentModel creates a new sentiment analysis model
 func NewSentimentModel() (*SentimentModel, error) {
-	if !initialized {
-		return nil, errors.New("library not initialized")
+	return NewSentimentModelOnDevice(DeviceAuto)
+}
+
+// NewSentimentModelOnDevice is like NewSentimentModel but loads the model on device.
+func NewSentimentModelOnDevice(device Device) (*SentimentModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
 	}
 
-	ptr := C.call_new_sentiment_model(fnNewSentimentModel)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_sentiment_model(fnNewSentimentModel, C.int(device))
 	if ptr == nil {
-		return nil, errors.New("failed to create sentiment model")
+		return nil, lastError("NewSentimentModel")
 	}
-	return &SentimentModel{ptr: ptr}, nil
+	return &SentimentModel{handle: modelHandle[C.SentimentModelWrapper]{ptr: ptr}}, nil
 }
 
 // NewSentimentModelFromFiles creates a new SentimentModel using local files.
-// mergesPath is optional (pass "" if not used).
+// mergesPath is optional (pass "" if not used) except for BPE based model types
+// (Roberta, Bart, Marian, GPT2). Tokenizer flags such as lower casing are read from a
+// tokenizer_config.json stored next to the vocab file, if present. Without it, lower
+// casing follows modelType, or for BERT, DistilBERT and ELECTRA, which come both cased
+// and uncased, config.json.
+//
+// An error is returned when a file is missing, modelType is unknown, the model_type
+// declared in config.json does not match modelType, or whether the checkpoint is cased
+// cannot be told.
 func NewSentimentModelFromFiles(modelPath, configPath, vocabPath, mergesPath string, modelType int) (*SentimentModel, error) {
-	ptr, err := callNewModelFromFiles(fnNewSentimentModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
-		return unsafe.Pointer(C.call_new_sentiment_model_from_files(fn, m, c, v, me, t))
+	return NewSentimentModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath, modelType, DeviceAuto)
+}
+
+// NewSentimentModelFromFilesOnDevice is like NewSentimentModelFromFiles but loads the
+// model on device.
+func NewSentimentModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath string, modelType int, device Device) (*SentimentModel, error) {
+	ptr, err := callNewModelFromFiles("NewSentimentModelFromFiles", fnNewSentimentModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return unsafe.Pointer(C.call_new_sentiment_model_from_files(fn, m, c, v, me, t, C.int(device)))
 	}, modelPath, configPath, vocabPath, mergesPath, modelType)
 	if err != nil {
 		return nil, err
 	}
-	return &SentimentModel{ptr: (*C.SentimentModelWrapper)(ptr)}, nil
+	return &SentimentModel{handle: modelHandle[C.SentimentModelWrapper]{ptr: (*C.SentimentModelWrapper)(ptr)}}, nil
 }
 
+// NewNERModelFromFiles creates a new NERModel using local files.
+// See NewSentimentModelFromFiles for the expected files and error conditions.
 func NewNERModelFromFiles(modelPath, configPath, vocabPath, mergesPath string, modelType int) (*NERModel, error) {
-	ptr, err := callNewModelFromFiles(fnNewNERModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
-		return unsafe.Pointer(C.call_new_ner_model_from_files(fn, m, c, v, me, t))
+	return NewNERModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath, modelType, DeviceAuto)
+}
+
+// NewNERModelFromFilesOnDevice is like NewNERModelFromFiles but loads the model
+// on device.
+func NewNERModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath string, modelType int, device Device) (*NERModel, error) {
+	ptr, err := callNewModelFromFiles("NewNERModelFromFiles", fnNewNERModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return unsafe.Pointer(C.call_new_ner_model_from_files(fn, m, c, v, me, t, C.int(device)))
 	}, modelPath, configPath, vocabPath, mergesPath, modelType)
 	if err != nil {
 		return nil, err
 	}
-	return &NERModel{ptr: (*C.NERModelWrapper)(ptr)}, nil
+	return &NERModel{handle: modelHandle[C.NERModelWrapper]{ptr: (*C.NERModelWrapper)(ptr)}}, nil
 }
 
+// NewQAModelFromFiles creates a new QAModel using local files.
+// See NewSentimentModelFromFiles for the expected files and error conditions.
 func NewQAModelFromFiles(modelPath, configPath, vocabPath, mergesPath string, modelType int) (*QAModel, error) {
-	ptr, err := callNewModelFromFiles(fnNewQAModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
-		return unsafe.Pointer(C.call_new_qa_model_from_files(fn, m, c, v, me, t))
+	return NewQAModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath, modelType, DeviceAuto)
+}
+
+// NewQAModelFromFilesOnDevice is like NewQAModelFromFiles but loads the model on
+// device.
+func NewQAModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath string, modelType int, device Device) (*QAModel, error) {
+	ptr, err := callNewModelFromFiles("NewQAModelFromFiles", fnNewQAModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return unsafe.Pointer(C.call_new_qa_model_from_files(fn, m, c, v, me, t, C.int(device)))
+	}, modelPath, configPath, vocabPath, mergesPath, modelType)
+	if err != nil {
+		return nil, err
+	}
+	return &QAModel{handle: modelHandle[C.QAModelWrapper]{ptr: (*C.QAModelWrapper)(ptr)}}, nil
+}
+
+// NewQAModelFromFilesWithConfig is like NewQAModelFromFiles but splits contexts and
+// answers with the settings in cfg.
+func NewQAModelFromFilesWithConfig(modelPath, configPath, vocabPath, mergesPath string, modelType int, cfg QAConfig) (*QAModel, error) {
+	cOpts := cfg.toC()
+	ptr, err := callNewModelFromFiles("NewQAModelFromFilesWithConfig", fnNewQAModelFromFilesWithOptions, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return unsafe.Pointer(C.call_new_qa_model_from_files_with_options(fn, m, c, v, me, t, &cOpts, C.int(cfg.Device)))
 	}, modelPath, configPath, vocabPath, mergesPath, modelType)
 	if err != nil {
 		return nil, err
 	}
-	return &QAModel{ptr: (*C.QAModelWrapper)(ptr)}, nil
+	return &QAModel{handle: modelHandle[C.QAModelWrapper]{ptr: (*C.QAModelWrapper)(ptr)}}, nil
 }
 
+// NewSummarizationModelFromFiles creates a new SummarizationModel using local files.
+// See NewSentimentModelFromFiles for the expected files and error conditions.
 func NewSummarizationModelFromFiles(modelPath, configPath, vocabPath, mergesPath string, modelType int) (*SummarizationModel, error) {
-	ptr, err := callNewModelFromFiles(fnNewSummarizationModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
-		return unsafe.Pointer(C.call_new_summarization_model_from_files(fn, m, c, v, me, t))
+	return NewSummarizationModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath, modelType, DeviceAuto)
+}
+
+// NewSummarizationModelFromFilesOnDevice is like NewSummarizationModelFromFiles but
+// loads the model on device.
+func NewSummarizationModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath string, modelType int, device Device) (*SummarizationModel, error) {
+	ptr, err := callNewModelFromFiles("NewSummarizationModelFromFiles", fnNewSummarizationModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return unsafe.Pointer(C.call_new_summarization_model_from_files(fn, m, c, v, me, t, C.int(device)))
+	}, modelPath, configPath, vocabPath, mergesPath, modelType)
+	if err != nil {
+		return nil, err
+	}
+	return &SummarizationModel{handle: modelHandle[C.SummarizationModelWrapper]{ptr: (*C.SummarizationModelWrapper)(ptr)}}, nil
+}
+
+// NewSummarizationModelFromFilesWithConfig is like NewSummarizationModelFromFiles but
+// generates with the options in cfg. cfg.Model is ignored, the checkpoint being given
+// by the files and modelType.
+func NewSummarizationModelFromFilesWithConfig(modelPath, configPath, vocabPath, mergesPath string, modelType int, cfg SummarizationConfig) (*SummarizationModel, error) {
+	cOpts := cfg.GenerateOptions.toC()
+	ptr, err := callNewModelFromFiles("NewSummarizationModelFromFilesWithConfig", fnNewSummarizationModelFromFilesWithConfig, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return unsafe.Pointer(C.call_new_summarization_model_from_files_with_config(fn, m, c, v, me, t, &cOpts, C.int(cfg.Device)))
 	}, modelPath, configPath, vocabPath, mergesPath, modelType)
 	if err != nil {
 		return nil, err
 	}
-	return &SummarizationModel{ptr: (*C.SummarizationModelWrapper)(ptr)}, nil
+	return &SummarizationModel{handle: modelHandle[C.SummarizationModelWrapper]{ptr: (*C.SummarizationModelWrapper)(ptr)}}, nil
 }
 
+// NewZeroShotModelFromFiles creates a new ZeroShotModel using local files.
+// See NewSentimentModelFromFiles for the expected files and error conditions.
 func NewZeroShotModelFromFiles(modelPath, configPath, vocabPath, mergesPath string, modelType int) (*ZeroShotModel, error) {
-	ptr, err := callNewModelFromFiles(fnNewZeroShotModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
-		return unsafe.Pointer(C.call_new_zero_shot_model_from_files(fn, m, c, v, me, t))
+	return NewZeroShotModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath, modelType, DeviceAuto)
+}
+
+// NewZeroShotModelFromFilesOnDevice is like NewZeroShotModelFromFiles but loads the
+// model on device.
+func NewZeroShotModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath string, modelType int, device Device) (*ZeroShotModel, error) {
+	ptr, err := callNewModelFromFiles("NewZeroShotModelFromFiles", fnNewZeroShotModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return unsafe.Pointer(C.call_new_zero_shot_model_from_files(fn, m, c, v, me, t, C.int(device)))
 	}, modelPath, configPath, vocabPath, mergesPath, modelType)
 	if err != nil {
 		return nil, err
 	}
-	return &ZeroShotModel{ptr: (*C.ZeroShotClassificationModelWrapper)(ptr)}, nil
+	return &ZeroShotModel{handle: modelHandle[C.ZeroShotClassificationModelWrapper]{ptr: (*C.ZeroShotClassificationModelWrapper)(ptr)}}, nil
 }
 
+// NewTranslationModelFromFiles creates a new TranslationModel using local files.
+// See NewSentimentModelFromFiles for the expected files and error conditions. M2M100 and
+// NLLB checkpoints also need mergesPath (the SentencePiece model, respectively the special
+// tokens map).
 func NewTranslationModelFromFiles(modelPath, configPath, vocabPath, mergesPath string, modelType int) (*TranslationModel, error) {
-	ptr, err := callNewModelFromFiles(fnNewTranslationModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
-		return unsafe.Pointer(C.call_new_translation_model_from_files(fn, m, c, v, me, t))
+	return NewTranslationModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath, modelType, DeviceAuto)
+}
+
+// NewTranslationModelFromFilesOnDevice is like NewTranslationModelFromFiles but loads
+// the model on device.
+func NewTranslationModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath string, modelType int, device Device) (*TranslationModel, error) {
+	ptr, err := callNewModelFromFiles("NewTranslationModelFromFiles", fnNewTranslationModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return unsafe.Pointer(C.call_new_translation_model_from_files(fn, m, c, v, me, t, C.int(device)))
 	}, modelPath, configPath, vocabPath, mergesPath, modelType)
 	if err != nil {
 		return nil, err
 	}
-	return &TranslationModel{ptr: (*C.TranslationModelWrapper)(ptr)}, nil
+	return &TranslationModel{handle: modelHandle[C.TranslationModelWrapper]{ptr: (*C.TranslationModelWrapper)(ptr)}}, nil
 }
 
+// NewTextGenerationModelFromFiles creates a new TextGenerationModel using local files.
+// See NewSentimentModelFromFiles for the expected files and error conditions.
 func NewTextGenerationModelFromFiles(modelPath, configPath, vocabPath, mergesPath string, modelType int) (*TextGenerationModel, error) {
-	ptr, err := callNewModelFromFiles(fnNewTextGenerationModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
-		return unsafe.Pointer(C.call_new_text_generation_model_from_files(fn, m, c, v, me, t))
+	return NewTextGenerationModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath, modelType, DeviceAuto)
+}
+
+// NewTextGenerationModelFromFilesOnDevice is like NewTextGenerationModelFromFiles but
+// loads the model on device.
+func NewTextGenerationModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath string, modelType int, device Device) (*TextGenerationModel, error) {
+	ptr, err := callNewModelFromFiles("NewTextGenerationModelFromFiles", fnNewTextGenerationModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return unsafe.Pointer(C.call_new_text_generation_model_from_files(fn, m, c, v, me, t, C.int(device)))
+	}, modelPath, configPath, vocabPath, mergesPath, modelType)
+	if err != nil {
+		return nil, err
+	}
+	return &TextGenerationModel{handle: modelHandle[C.TextGenerationModelWrapper]{ptr: (*C.TextGenerationModelWrapper)(ptr)}}, nil
+}
+
+// NewTextGenerationModelFromFilesWithOptions is like NewTextGenerationModelFromFiles but
+// generates with opts instead of the rust-bert defaults.
+func NewTextGenerationModelFromFilesWithOptions(modelPath, configPath, vocabPath, mergesPath string, modelType int, opts GenerateOptions) (*TextGenerationModel, error) {
+	return NewTextGenerationModelFromFilesWithOptionsOnDevice(modelPath, configPath, vocabPath, mergesPath, modelType, opts, DeviceAuto)
+}
+
+// NewTextGenerationModelFromFilesWithOptionsOnDevice is like
+// NewTextGenerationModelFromFilesWithOptions but loads the model on device.
+func NewTextGenerationModelFromFilesWithOptionsOnDevice(modelPath, configPath, vocabPath, mergesPath string, modelType int, opts GenerateOptions, device Device) (*TextGenerationModel, error) {
+	cOpts := opts.toC()
+	ptr, err := callNewModelFromFiles("NewTextGenerationModelFromFilesWithOptions", fnNewTextGenerationModelFromFilesWithOptions, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return unsafe.Pointer(C.call_new_text_generation_model_from_files_with_options(fn, m, c, v, me, t, &cOpts, C.int(device)))
 	}, modelPath, configPath, vocabPath, mergesPath, modelType)
 	if err != nil {
 		return nil, err
 	}
-	return &TextGenerationModel{ptr: (*C.TextGenerationModelWrapper)(ptr)}, nil
+	return &TextGenerationModel{handle: modelHandle[C.TextGenerationModelWrapper]{ptr: (*C.TextGenerationModelWrapper)(ptr)}}, nil
 }
 
 // Predict performs sentiment analysis on the given text
 func (m *SentimentModel) Predict(text string) (*SentimentResult, error) {
-	if m.ptr == nil {
-		return nil, errors.New("model is closed")
+	return withModel(&m.handle, func(ptr *C.SentimentModelWrapper) (*SentimentResult, error) {
+		cText := C.CString(text)
+		defer C.free(unsafe.Pointer(cText))
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_predict_sentiment(fnPredictSentiment, ptr, cText)
+		if res == nil {
+			return nil, lastError("SentimentModel.Predict")
+		}
+		defer C.call_free_sentiment_result(fnFreeSentimentResult, res)
+
+		result := sentimentResult(res)
+		return &result, nil
+	})
+}
+
+// PredictContext is like Predict but returns ctx.Err() as soon as ctx is done.
+func (m *SentimentModel) PredictContext(ctx context.Context, text string) (*SentimentResult, error) {
+	results, err := m.PredictBatchContext(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return &results[0], nil
+}
+
+// PredictBatch performs sentiment analysis on several texts in a single forward
+// pass. Results are returned in input order.
+func (m *SentimentModel) PredictBatch(texts []string) ([]SentimentResult, error) {
+	return m.PredictBatchContext(context.Background(), texts)
+}
+
+// PredictBatchContext is like PredictBatch but stops once ctx is done, returning ctx.Err().
+func (m *SentimentModel) PredictBatchContext(ctx context.Context, texts []string) ([]SentimentResult, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if len(texts) == 0 {
+		return []SentimentResult{}, nil
 	}
 
-	cText := C.CString(text)
-	defer C.free(unsafe.Pointer(cText))
+	return withContext(ctx, &m.handle, func(ptr *C.SentimentModelWrapper, cancel *C.CancelToken) ([]SentimentResult, error) {
+		cTexts := cStringArray(texts)
+		defer freeCStringArray(cTexts)
 
-	res := C.call_predict_sentiment(fnPredictSentiment, m.ptr, cText)
-	if res == nil {
-		return nil, errors.New("prediction failed")
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_predict_sentiment_batch(fnPredictSentimentBatch, ptr, &cTexts[0], C.size_t(len(cTexts)), cancel)
+		if res == nil {
+			return nil, lastError("SentimentModel.PredictBatch")
+		}
+		defer C.call_free_sentiment_batch_result(fnFreeSentimentBatchResult, res)
+
+		cResults := unsafe.Slice(res.results, int(res.count))
+		results := make([]SentimentResult, len(cResults))
+		for i := range cResults {
+			results[i] = sentimentResult(&cResults[i])
+		}
+		return results, nil
+	})
+}
+
+// PredictLong performs sentiment analysis on a text longer than the model accepts.
+// The text is split into overlapping windows of cfg.WindowSize tokens whose scores are
+// combined with cfg.Aggregation. A text that fits in one window gives the same result
+// as Predict.
+func (m *SentimentModel) PredictLong(text string, cfg WindowConfig) (*SentimentResult, error) {
+	return m.PredictLongContext(context.Background(), text, cfg)
+}
+
+// PredictLongContext is like PredictLong but stops once ctx is done, returning ctx.Err().
+func (m *SentimentModel) PredictLongContext(ctx context.Context, text string, cfg WindowConfig) (*SentimentResult, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if err := cfg.validate("SentimentModel.PredictLong"); err != nil {
+		return nil, err
 	}
-	defer C.call_free_sentiment_result(fnFreeSentimentResult, res)
+	return withContext(ctx, &m.handle, func(ptr *C.SentimentModelWrapper, cancel *C.CancelToken) (*SentimentResult, error) {
+		cText := C.CString(text)
+		defer C.free(unsafe.Pointer(cText))
+		cOpts := cfg.toC()
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_predict_sentiment_long(fnPredictSentimentLong, ptr, cText, &cOpts, cancel)
+		if res == nil {
+			return nil, lastError("SentimentModel.PredictLong")
+		}
+		defer C.call_free_sentiment_result(fnFreeSentimentResult, res)
+
+		result := sentimentResult(res)
+		return &result, nil
+	})
+}
 
-	return &SentimentResult{
+func sentimentResult(res *C.SentimentResult) SentimentResult {
+	return SentimentResult{
 		Label: C.GoString(res.label),
 		Score: float64(res.score),
-	}, nil
+	}
 }
 
-// Close frees the underlying Rust model
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
 func (m *SentimentModel) Close() {
-	if m.ptr != nil {
-		C.call_free_sentiment_model(fnFreeSentimentModel, m.ptr)
-		m.ptr = nil
-	}
+	m.handle.close(func(ptr *C.SentimentModelWrapper) {
+		C.call_free_sentiment_model(fnFreeSentimentModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *SentimentModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
 }
 
 // SetFinalizer ensures the model is closed when garbage collected (optional but good practice)
@@ -554,7 +1132,7 @@ func (m *SentimentModel) SetFinalizer() {
 
 // POSModel is a wrapper around the Rust POS tagging model
 type POSModel struct {
-	ptr *C.POSModelWrapper
+	handle modelHandle[C.POSModelWrapper]
 }
 
 // POSTag represents a single Part-of-Speech tag
@@ -566,32 +1144,91 @@ type POSTag struct {
 
 // NewPOSModel creates a new POS tagging model
 func NewPOSModel() (*POSModel, error) {
-	if !initialized {
-		return nil, errors.New("library not initialized")
+	return NewPOSModelOnDevice(DeviceAuto)
+}
+
+// NewPOSModelOnDevice is like NewPOSModel but loads the model on device.
+func NewPOSModelOnDevice(device Device) (*POSModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
 	}
 
-	ptr := C.call_new_pos_model(fnNewPOSModel)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_pos_model(fnNewPOSModel, C.int(device))
 	if ptr == nil {
-		return nil, errors.New("failed to create POS model")
+		return nil, lastError("NewPOSModel")
 	}
-	return &POSModel{ptr: ptr}, nil
+	return &POSModel{handle: modelHandle[C.POSModelWrapper]{ptr: ptr}}, nil
 }
 
 // Predict performs POS tagging on the given text
 func (m *POSModel) Predict(text string) ([]POSTag, error) {
-	if m.ptr == nil {
-		return nil, errors.New("model is closed")
+	return withModel(&m.handle, func(ptr *C.POSModelWrapper) ([]POSTag, error) {
+		cText := C.CString(text)
+		defer C.free(unsafe.Pointer(cText))
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_predict_pos(fnPredictPOS, ptr, cText)
+		if res == nil {
+			return nil, lastError("POSModel.Predict")
+		}
+		defer C.call_free_pos_result(fnFreePOSResult, res)
+
+		return posTags(res), nil
+	})
+}
+
+// PredictContext is like Predict but returns ctx.Err() as soon as ctx is done.
+func (m *POSModel) PredictContext(ctx context.Context, text string) ([]POSTag, error) {
+	results, err := m.PredictBatchContext(ctx, []string{text})
+	if err != nil {
+		return nil, err
 	}
+	return results[0], nil
+}
 
-	cText := C.CString(text)
-	defer C.free(unsafe.Pointer(cText))
+// PredictBatch performs POS tagging on several texts in a single forward pass.
+// The tags for texts[i] are returned at index i.
+func (m *POSModel) PredictBatch(texts []string) ([][]POSTag, error) {
+	return m.PredictBatchContext(context.Background(), texts)
+}
 
-	res := C.call_predict_pos(fnPredictPOS, m.ptr, cText)
-	if res == nil {
-		return nil, errors.New("prediction failed")
+// PredictBatchContext is like PredictBatch but stops once ctx is done, returning ctx.Err().
+func (m *POSModel) PredictBatchContext(ctx context.Context, texts []string) ([][]POSTag, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if len(texts) == 0 {
+		return [][]POSTag{}, nil
 	}
-	defer C.call_free_pos_result(fnFreePOSResult, res)
 
+	return withContext(ctx, &m.handle, func(ptr *C.POSModelWrapper, cancel *C.CancelToken) ([][]POSTag, error) {
+		cTexts := cStringArray(texts)
+		defer freeCStringArray(cTexts)
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_predict_pos_batch(fnPredictPOSBatch, ptr, &cTexts[0], C.size_t(len(cTexts)), cancel)
+		if res == nil {
+			return nil, lastError("POSModel.PredictBatch")
+		}
+		defer C.call_free_pos_batch_result(fnFreePOSBatchResult, res)
+
+		cResults := unsafe.Slice(res.results, int(res.count))
+		results := make([][]POSTag, len(cResults))
+		for i := range cResults {
+			results[i] = posTags(&cResults[i])
+		}
+		return results, nil
+	})
+}
+
+func posTags(res *C.POSResult) []POSTag {
 	count := int(res.count)
 	tags := make([]POSTag, count)
 
@@ -607,22 +1244,28 @@ func (m *POSModel) Predict(text string) ([]POSTag, error) {
 		}
 	}
 
-	return tags, nil
+	return tags
 }
 
-// Close frees the underlying Rust model
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
 func (m *POSModel) Close() {
-	if m.ptr != nil {
-		C.call_free_pos_model(fnFreePOSModel, m.ptr)
-		m.ptr = nil
-	}
+	m.handle.close(func(ptr *C.POSModelWrapper) {
+		C.call_free_pos_model(fnFreePOSModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *POSModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
 }
 
 // --- NER ---
 
 // NERModel is a wrapper around the Rust NER model
 type NERModel struct {
-	ptr *C.NERModelWrapper
+	handle modelHandle[C.NERModelWrapper]
 }
 
 // Entity represents an extracted named entity
@@ -638,105 +1281,406 @@ type Entity struct {
 
 // NewNERModel creates a new NER model
 func NewNERModel() (*NERModel, error) {
-	if !initialized {
-		return nil, errors.New("library not initialized")
+	return NewNERModelOnDevice(DeviceAuto)
+}
+
+// NewNERModelOnDevice is like NewNERModel but loads the model on device.
+func NewNERModelOnDevice(device Device) (*NERModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
 	}
 
-	ptr := C.call_new_ner_model(fnNewNERModel)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_ner_model(fnNewNERModel, C.int(device))
 	if ptr == nil {
-		return nil, errors.New("failed to create NER model")
+		return nil, lastError("NewNERModel")
 	}
-	return &NERModel{ptr: ptr}, nil
+	return &NERModel{handle: modelHandle[C.NERModelWrapper]{ptr: ptr}}, nil
 }
 
 // Predict performs named entity recognition on the given text
 func (m *NERModel) Predict(text string) ([]Entity, error) {
-	if m.ptr == nil {
-		return nil, errors.New("model is closed")
-	}
-
-	cText := C.CString(text)
-	defer C.free(unsafe.Pointer(cText))
+	return withModel(&m.handle, func(ptr *C.NERModelWrapper) ([]Entity, error) {
+		cText := C.CString(text)
+		defer C.free(unsafe.Pointer(cText))
 
-	res := C.call_predict_ner(fnPredictNER, m.ptr, cText)
-	if res == nil {
-		return nil, errors.New("prediction failed")
-	}
-	defer C.call_free_ner_result(fnFreeNERResult, res)
-
-	count := int(res.count)
-	entities := make([]Entity, count)
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
 
-	cEntities := unsafe.Slice(res.entities, count)
-
-	for i := 0; i < count; i++ {
-		entities[i] = Entity{
-			Word:  C.GoString(cEntities[i].word),
-			Score: float64(cEntities[i].score),
-			Label: C.GoString(cEntities[i].label),
-			Offset: struct{ Begin, End int }{
-				Begin: int(cEntities[i].offset_begin),
-				End:   int(cEntities[i].offset_end),
-			},
+		res := C.call_predict_ner(fnPredictNER, ptr, cText)
+		if res == nil {
+			return nil, lastError("NERModel.Predict")
 		}
-	}
+		defer C.call_free_ner_result(fnFreeNERResult, res)
 
-	return entities, nil
+		return nerEntities(res), nil
+	})
 }
 
-// Close frees the underlying Rust model
-func (m *NERModel) Close() {
-	if m.ptr != nil {
-		C.call_free_ner_model(fnFreeNERModel, m.ptr)
-		m.ptr = nil
+// PredictContext is like Predict but returns ctx.Err() as soon as ctx is done.
+func (m *NERModel) PredictContext(ctx context.Context, text string) ([]Entity, error) {
+	results, err := m.PredictBatchContext(ctx, []string{text})
+	if err != nil {
+		return nil, err
 	}
+	return results[0], nil
 }
 
-// --- Question Answering ---
-
-// QAModel is a wrapper around the Rust QA model
-type QAModel struct {
-	ptr *C.QAModelWrapper
+// PredictBatch performs named entity recognition on several texts in a single
+// forward pass. The entities found in texts[i] are returned at index i.
+func (m *NERModel) PredictBatch(texts []string) ([][]Entity, error) {
+	return m.PredictBatchContext(context.Background(), texts)
+}
+
+// PredictBatchContext is like PredictBatch but stops once ctx is done, returning ctx.Err().
+func (m *NERModel) PredictBatchContext(ctx context.Context, texts []string) ([][]Entity, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if len(texts) == 0 {
+		return [][]Entity{}, nil
+	}
+
+	return withContext(ctx, &m.handle, func(ptr *C.NERModelWrapper, cancel *C.CancelToken) ([][]Entity, error) {
+		cTexts := cStringArray(texts)
+		defer freeCStringArray(cTexts)
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_predict_ner_batch(fnPredictNERBatch, ptr, &cTexts[0], C.size_t(len(cTexts)), cancel)
+		if res == nil {
+			return nil, lastError("NERModel.PredictBatch")
+		}
+		defer C.call_free_ner_batch_result(fnFreeNERBatchResult, res)
+
+		cResults := unsafe.Slice(res.results, int(res.count))
+		results := make([][]Entity, len(cResults))
+		for i := range cResults {
+			results[i] = nerEntities(&cResults[i])
+		}
+		return results, nil
+	})
+}
+
+// PredictLong performs named entity recognition on a text longer than the model
+// accepts. The text is split into overlapping windows of cfg.WindowSize tokens; the
+// entities found twice in the overlaps are reported once, with offsets into text.
+// cfg.Aggregation is not used.
+func (m *NERModel) PredictLong(text string, cfg WindowConfig) ([]Entity, error) {
+	return m.PredictLongContext(context.Background(), text, cfg)
+}
+
+// PredictLongContext is like PredictLong but stops once ctx is done, returning ctx.Err().
+func (m *NERModel) PredictLongContext(ctx context.Context, text string, cfg WindowConfig) ([]Entity, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if err := cfg.validate("NERModel.PredictLong"); err != nil {
+		return nil, err
+	}
+	return withContext(ctx, &m.handle, func(ptr *C.NERModelWrapper, cancel *C.CancelToken) ([]Entity, error) {
+		cText := C.CString(text)
+		defer C.free(unsafe.Pointer(cText))
+		cOpts := cfg.toC()
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_predict_ner_long(fnPredictNERLong, ptr, cText, &cOpts, cancel)
+		if res == nil {
+			return nil, lastError("NERModel.PredictLong")
+		}
+		defer C.call_free_ner_result(fnFreeNERResult, res)
+
+		return nerEntities(res), nil
+	})
+}
+
+func nerEntities(res *C.NERResult) []Entity {
+	count := int(res.count)
+	entities := make([]Entity, count)
+
+	cEntities := unsafe.Slice(res.entities, count)
+
+	for i := 0; i < count; i++ {
+		entities[i] = Entity{
+			Word:  C.GoString(cEntities[i].word),
+			Score: float64(cEntities[i].score),
+			Label: C.GoString(cEntities[i].label),
+			Offset: struct{ Begin, End int }{
+				Begin: int(cEntities[i].offset_begin),
+				End:   int(cEntities[i].offset_end),
+			},
+		}
+	}
+
+	return entities
+}
+
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
+func (m *NERModel) Close() {
+	m.handle.close(func(ptr *C.NERModelWrapper) {
+		C.call_free_ner_model(fnFreeNERModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *NERModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
+}
+
+// --- Question Answering ---
+
+// QAModel is a wrapper around the Rust QA model
+type QAModel struct {
+	handle modelHandle[C.QAModelWrapper]
 }
 
 // Answer represents an extracted answer
 type Answer struct {
-	Score  float64
+	Score float64
+	// Start and End are the character offsets of the answer in the context.
 	Start  int
 	End    int
 	Answer string
 }
 
+// QAOptions selects the answers a QAModel returns.
+type QAOptions struct {
+	// TopK is the number of answers returned per question, best first.
+	TopK int
+	// MinScore drops the answers scoring below it. 0 keeps every answer. Scores are
+	// those of the spans only, with no score for the question having no answer, so a
+	// filtered prediction can be empty whether or not the context answers it.
+	MinScore float64
+}
+
+func (o QAOptions) toC() C.QAOptions {
+	return C.QAOptions{
+		top_k:     C.int64_t(o.TopK),
+		min_score: C.double(o.MinScore),
+	}
+}
+
+// QAConfig configures a QAModel. Start from DefaultQAConfig.
+type QAConfig struct {
+	// MaxSeqLength is the number of tokens of question and context fed to the model
+	// at once. Longer contexts are split into several spans.
+	MaxSeqLength int
+	// DocStride is the number of context tokens shared by consecutive spans.
+	DocStride int
+	// MaxQueryLength is the number of tokens questions are truncated to.
+	MaxQueryLength int
+	// MaxAnswerLength is the number of tokens of the longest answer.
+	MaxAnswerLength int
+	// Options are used by the calls that do not pass their own, such as Predict.
+	Options QAOptions
+	// Device is the hardware the model runs on.
+	Device Device
+}
+
+// DefaultQAConfig returns the configuration NewQAModel uses, which matches rust-bert's
+// QuestionAnsweringConfig defaults and returns the best answer only.
+func DefaultQAConfig() QAConfig {
+	return QAConfig{
+		MaxSeqLength:    384,
+		DocStride:       128,
+		MaxQueryLength:  64,
+		MaxAnswerLength: 15,
+		Options:         QAOptions{TopK: 1},
+	}
+}
+
+func (cfg QAConfig) toC() C.QAModelOptions {
+	return C.QAModelOptions{
+		max_seq_length:    C.size_t(cfg.MaxSeqLength),
+		doc_stride:        C.size_t(cfg.DocStride),
+		max_query_length:  C.size_t(cfg.MaxQueryLength),
+		max_answer_length: C.size_t(cfg.MaxAnswerLength),
+		defaults:          cfg.Options.toC(),
+	}
+}
+
+// QAPrediction holds the ranked answers to one question. Answers is empty when every
+// answer scored below QAOptions.MinScore.
+type QAPrediction struct {
+	Answers []Answer
+}
+
+// QAInput is a single question/context pair for QAModel.PredictBatch
+type QAInput struct {
+	Question string
+	Context  string
+}
+
 // NewQAModel creates a new Question Answering model
 func NewQAModel() (*QAModel, error) {
-	if !initialized {
-		return nil, errors.New("library not initialized")
+	return NewQAModelOnDevice(DeviceAuto)
+}
+
+// NewQAModelOnDevice is like NewQAModel but loads the model on device.
+func NewQAModelOnDevice(device Device) (*QAModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_qa_model(fnNewQAModel, C.int(device))
+	if ptr == nil {
+		return nil, lastError("NewQAModel")
+	}
+	return &QAModel{handle: modelHandle[C.QAModelWrapper]{ptr: ptr}}, nil
+}
+
+// NewQAModelWithConfig creates a Question Answering model that splits contexts and
+// answers with the settings in cfg.
+func NewQAModelWithConfig(cfg QAConfig) (*QAModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
 	}
 
-	ptr := C.call_new_qa_model(fnNewQAModel)
+	cOpts := cfg.toC()
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_qa_model_with_options(fnNewQAModelWithOptions, &cOpts, C.int(cfg.Device))
 	if ptr == nil {
-		return nil, errors.New("failed to create QA model")
+		return nil, lastError("NewQAModelWithConfig")
 	}
-	return &QAModel{ptr: ptr}, nil
+	return &QAModel{handle: modelHandle[C.QAModelWrapper]{ptr: ptr}}, nil
 }
 
 // Predict performs question answering
 func (m *QAModel) Predict(question, context string) ([]Answer, error) {
-	if m.ptr == nil {
-		return nil, errors.New("model is closed")
+	return withModel(&m.handle, func(ptr *C.QAModelWrapper) ([]Answer, error) {
+		cQuestion := C.CString(question)
+		defer C.free(unsafe.Pointer(cQuestion))
+		cContext := C.CString(context)
+		defer C.free(unsafe.Pointer(cContext))
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_predict_qa(fnPredictQA, ptr, cQuestion, cContext)
+		if res == nil {
+			return nil, lastError("QAModel.Predict")
+		}
+		defer C.call_free_qa_result(fnFreeQAResult, res)
+
+		return qaAnswers(res), nil
+	})
+}
+
+// PredictContext is like Predict but returns ctx.Err() as soon as ctx is done.
+func (m *QAModel) PredictContext(ctx context.Context, question, context string) ([]Answer, error) {
+	results, err := m.PredictBatchContext(ctx, []QAInput{{Question: question, Context: context}})
+	if err != nil {
+		return nil, err
 	}
+	return results[0], nil
+}
+
+// PredictBatch answers several question/context pairs in a single forward pass.
+// The answers for inputs[i] are returned at index i.
+func (m *QAModel) PredictBatch(inputs []QAInput) ([][]Answer, error) {
+	return m.PredictBatchContext(context.Background(), inputs)
+}
 
-	cQuestion := C.CString(question)
-	defer C.free(unsafe.Pointer(cQuestion))
-	cContext := C.CString(context)
-	defer C.free(unsafe.Pointer(cContext))
+// PredictBatchContext is like PredictBatch but stops once ctx is done, returning ctx.Err().
+func (m *QAModel) PredictBatchContext(ctx context.Context, inputs []QAInput) ([][]Answer, error) {
+	return m.predictBatch(ctx, inputs, nil)
+}
+
+// PredictWithOptions is like Predict, answering question from passage, but selects
+// the answers with opts instead of the options the model was created with.
+func (m *QAModel) PredictWithOptions(question, passage string, opts QAOptions) (*QAPrediction, error) {
+	return m.PredictWithOptionsContext(context.Background(), question, passage, opts)
+}
 
-	res := C.call_predict_qa(fnPredictQA, m.ptr, cQuestion, cContext)
-	if res == nil {
-		return nil, errors.New("prediction failed")
+// PredictWithOptionsContext is like PredictWithOptions but stops once ctx is done, returning ctx.Err().
+func (m *QAModel) PredictWithOptionsContext(ctx context.Context, question, passage string, opts QAOptions) (*QAPrediction, error) {
+	predictions, err := m.PredictBatchWithOptionsContext(ctx, []QAInput{{Question: question, Context: passage}}, opts)
+	if err != nil {
+		return nil, err
 	}
-	defer C.call_free_qa_result(fnFreeQAResult, res)
+	return &predictions[0], nil
+}
+
+// PredictBatchWithOptions is like PredictBatch but selects the answers with opts
+// instead of the options the model was created with.
+func (m *QAModel) PredictBatchWithOptions(inputs []QAInput, opts QAOptions) ([]QAPrediction, error) {
+	return m.PredictBatchWithOptionsContext(context.Background(), inputs, opts)
+}
+
+// PredictBatchWithOptionsContext is like PredictBatchWithOptions but stops once ctx is done, returning ctx.Err().
+func (m *QAModel) PredictBatchWithOptionsContext(ctx context.Context, inputs []QAInput, opts QAOptions) ([]QAPrediction, error) {
+	if opts.TopK < 1 {
+		return nil, invalidInput("QAModel.PredictBatchWithOptions", fmt.Sprintf("TopK must be at least 1, got %d", opts.TopK))
+	}
+	cOpts := opts.toC()
+	answers, err := m.predictBatch(ctx, inputs, &cOpts)
+	if err != nil {
+		return nil, err
+	}
+	predictions := make([]QAPrediction, len(answers))
+	for i := range answers {
+		predictions[i] = QAPrediction{Answers: answers[i]}
+	}
+	return predictions, nil
+}
+
+func (m *QAModel) predictBatch(ctx context.Context, inputs []QAInput, opts *C.QAOptions) ([][]Answer, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if len(inputs) == 0 {
+		return [][]Answer{}, nil
+	}
+
+	return withContext(ctx, &m.handle, func(ptr *C.QAModelWrapper, cancel *C.CancelToken) ([][]Answer, error) {
+		questions := make([]string, len(inputs))
+		contexts := make([]string, len(inputs))
+		for i, input := range inputs {
+			questions[i] = input.Question
+			contexts[i] = input.Context
+		}
+		cQuestions := cStringArray(questions)
+		defer freeCStringArray(cQuestions)
+		cContexts := cStringArray(contexts)
+		defer freeCStringArray(cContexts)
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		var res *C.QABatchResult
+		if opts == nil {
+			res = C.call_predict_qa_batch(fnPredictQABatch, ptr, &cQuestions[0], &cContexts[0], C.size_t(len(inputs)), cancel)
+		} else {
+			res = C.call_predict_qa_batch_with_options(fnPredictQABatchWithOptions, ptr, &cQuestions[0], &cContexts[0], C.size_t(len(inputs)), opts, cancel)
+		}
+		if res == nil {
+			return nil, lastError("QAModel.PredictBatch")
+		}
+		defer C.call_free_qa_batch_result(fnFreeQABatchResult, res)
+
+		cResults := unsafe.Slice(res.results, int(res.count))
+		results := make([][]Answer, len(cResults))
+		for i := range cResults {
+			results[i] = qaAnswers(&cResults[i])
+		}
+		return results, nil
+	})
+}
 
+func qaAnswers(res *C.QAResult) []Answer {
 	count := int(res.count)
 	answers := make([]Answer, count)
 
@@ -751,73 +1695,312 @@ func (m *QAModel) Predict(question, context string) ([]Answer, error) {
 		}
 	}
 
-	return answers, nil
+	return answers
 }
 
-// Close frees the underlying Rust model
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
 func (m *QAModel) Close() {
-	if m.ptr != nil {
-		C.call_free_qa_model(fnFreeQAModel, m.ptr)
-		m.ptr = nil
-	}
+	m.handle.close(func(ptr *C.QAModelWrapper) {
+		C.call_free_qa_model(fnFreeQAModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *QAModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
 }
 
 // --- Summarization ---
 
+// SummarizationModelKind selects a pretrained summarization checkpoint.
+type SummarizationModelKind int
+
+const (
+	// SummarizationBartCNN is BART large fine-tuned on CNN/DailyMail (the default).
+	SummarizationBartCNN SummarizationModelKind = iota
+	// SummarizationDistilBartCNN is DistilBART 6-6 fine-tuned on CNN/DailyMail.
+	SummarizationDistilBartCNN
+	// SummarizationT5Small is T5 small. Inputs are prefixed with "summarize: ".
+	SummarizationT5Small
+	// SummarizationT5Base is T5 base. Inputs are prefixed with "summarize: ".
+	SummarizationT5Base
+	// SummarizationPegasusCNN is Pegasus fine-tuned on CNN/DailyMail.
+	SummarizationPegasusCNN
+	// SummarizationProphetNetCNN is ProphetNet large fine-tuned on CNN/DailyMail.
+	SummarizationProphetNetCNN
+	// SummarizationLongT5 is LongT5 (transient global, base) fine-tuned on book
+	// summaries, suited to long inputs.
+	SummarizationLongT5
+)
+
+// SummarizationConfig selects the checkpoint and generation settings of a
+// SummarizationModel. Start from DefaultSummarizationConfig.
+type SummarizationConfig struct {
+	Model SummarizationModelKind
+	GenerateOptions
+	// Device is the hardware the model runs on.
+	Device Device
+}
+
+// DefaultSummarizationConfig returns the configuration NewSummarizationModel uses,
+// which matches rust-bert's SummarizationConfig defaults.
+func DefaultSummarizationConfig() SummarizationConfig {
+	return SummarizationConfig{
+		Model: SummarizationBartCNN,
+		GenerateOptions: GenerateOptions{
+			MaxLength:          142,
+			MinLength:          56,
+			DoSample:           false,
+			EarlyStopping:      true,
+			Temperature:        1.0,
+			TopK:               50,
+			TopP:               1.0,
+			RepetitionPenalty:  1.0,
+			NoRepeatNgramSize:  3,
+			NumBeams:           3,
+			NumReturnSequences: 1,
+			LengthPenalty:      1.0,
+			Seed:               -1,
+		},
+	}
+}
+
+// SummarizeOptions overrides generation settings for a single call. Zero fields
+// keep the value the model was created with.
+type SummarizeOptions struct {
+	// MinLength and MaxLength bound the summary length in tokens.
+	MinLength int
+	MaxLength int
+	NumBeams  int
+	// LengthPenalty > 1 favours longer summaries in beam search, < 1 shorter ones.
+	LengthPenalty     float64
+	NoRepeatNgramSize int
+}
+
+func (o SummarizeOptions) toC() C.SummarizeOverrides {
+	return C.SummarizeOverrides{
+		min_length:           C.int64_t(o.MinLength),
+		max_length:           C.int64_t(o.MaxLength),
+		num_beams:            C.int64_t(o.NumBeams),
+		length_penalty:       C.double(o.LengthPenalty),
+		no_repeat_ngram_size: C.int64_t(o.NoRepeatNgramSize),
+	}
+}
+
+// LongSummaryConfig configures SummarizationModel.SummarizeLong. Start from
+// DefaultLongSummaryConfig.
+type LongSummaryConfig struct {
+	// ChunkSize is the maximum number of tokens of each chunk summarized, at most the
+	// input length of the model: 1024 tokens for BART and Pegasus, 512 for T5.
+	ChunkSize int
+	// TargetLength is the maximum number of tokens of the final summary.
+	TargetLength int
+	// Options overrides the generation settings of the model when summarizing chunks.
+	Options SummarizeOptions
+}
+
+// DefaultLongSummaryConfig summarizes chunks of 480 tokens, which fit every supported
+// checkpoint, into a summary of at most 142 tokens, the default summary length of BART.
+func DefaultLongSummaryConfig() LongSummaryConfig {
+	return LongSummaryConfig{
+		ChunkSize:    480,
+		TargetLength: 142,
+	}
+}
+
+// LongSummary is the result of SummarizationModel.SummarizeLong.
+type LongSummary struct {
+	Summary string
+	// ChunkSummaries are the summaries of the chunks of the input text, in order.
+	ChunkSummaries []string
+}
+
 // SummarizationModel is a wrapper around the Rust Summarization model
 type SummarizationModel struct {
-	ptr *C.SummarizationModelWrapper
+	handle modelHandle[C.SummarizationModelWrapper]
 }
 
 // NewSummarizationModel creates a new Summarization model
 func NewSummarizationModel() (*SummarizationModel, error) {
-	if !initialized {
-		return nil, errors.New("library not initialized")
+	return NewSummarizationModelOnDevice(DeviceAuto)
+}
+
+// NewSummarizationModelOnDevice is like NewSummarizationModel but loads the model on device.
+func NewSummarizationModelOnDevice(device Device) (*SummarizationModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
 	}
 
-	ptr := C.call_new_summarization_model(fnNewSummarizationModel)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_summarization_model(fnNewSummarizationModel, C.int(device))
 	if ptr == nil {
-		return nil, errors.New("failed to create Summarization model")
+		return nil, lastError("NewSummarizationModel")
 	}
-	return &SummarizationModel{ptr: ptr}, nil
+	return &SummarizationModel{handle: modelHandle[C.SummarizationModelWrapper]{ptr: ptr}}, nil
+}
+
+// NewSummarizationModelWithConfig creates a Summarization model for cfg.Model,
+// generating with the options in cfg.
+func NewSummarizationModelWithConfig(cfg SummarizationConfig) (*SummarizationModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+
+	cOpts := cfg.GenerateOptions.toC()
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_summarization_model_with_config(fnNewSummarizationModelWithConfig, C.int(cfg.Model), &cOpts, C.int(cfg.Device))
+	if ptr == nil {
+		return nil, lastError("NewSummarizationModelWithConfig")
+	}
+	return &SummarizationModel{handle: modelHandle[C.SummarizationModelWrapper]{ptr: ptr}}, nil
 }
 
 // Summarize performs text summarization
 func (m *SummarizationModel) Summarize(text string) ([]string, error) {
-	if m.ptr == nil {
-		return nil, errors.New("model is closed")
-	}
+	return withModel(&m.handle, func(ptr *C.SummarizationModelWrapper) ([]string, error) {
+		cText := C.CString(text)
+		defer C.free(unsafe.Pointer(cText))
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
 
-	cText := C.CString(text)
-	defer C.free(unsafe.Pointer(cText))
+		res := C.call_summarize(fnSummarize, ptr, cText)
+		if res == nil {
+			return nil, lastError("SummarizationModel.Summarize")
+		}
+		defer C.call_free_summarization_result(fnFreeSummarizationResult, res)
+
+		return goStrings(res.summaries, res.count), nil
+	})
+}
+
+// SummarizeContext is like Summarize but returns ctx.Err() as soon as ctx is done.
+func (m *SummarizationModel) SummarizeContext(ctx context.Context, text string) ([]string, error) {
+	return m.SummarizeBatchContext(ctx, []string{text})
+}
 
-	res := C.call_summarize(fnSummarize, m.ptr, cText)
-	if res == nil {
-		return nil, errors.New("summarization failed")
+// SummarizeBatch summarizes several texts in a single generation pass. The
+// summary of texts[i] is returned at index i.
+func (m *SummarizationModel) SummarizeBatch(texts []string) ([]string, error) {
+	return m.SummarizeBatchContext(context.Background(), texts)
+}
+
+// SummarizeBatchContext is like SummarizeBatch but stops once ctx is done, returning ctx.Err().
+// As with TranslateBatchContext, a batch already started completes in the background.
+func (m *SummarizationModel) SummarizeBatchContext(ctx context.Context, texts []string) ([]string, error) {
+	return m.summarizeBatch(ctx, texts, nil)
+}
+
+// SummarizeWithOptions is like Summarize but overrides the model's generation
+// settings with opts for this call.
+func (m *SummarizationModel) SummarizeWithOptions(text string, opts SummarizeOptions) ([]string, error) {
+	return m.SummarizeWithOptionsContext(context.Background(), text, opts)
+}
+
+// SummarizeWithOptionsContext is like SummarizeWithOptions but stops once ctx is done, returning ctx.Err().
+func (m *SummarizationModel) SummarizeWithOptionsContext(ctx context.Context, text string, opts SummarizeOptions) ([]string, error) {
+	return m.SummarizeBatchWithOptionsContext(ctx, []string{text}, opts)
+}
+
+// SummarizeBatchWithOptions is like SummarizeBatch but overrides the model's
+// generation settings with opts for this call.
+func (m *SummarizationModel) SummarizeBatchWithOptions(texts []string, opts SummarizeOptions) ([]string, error) {
+	return m.SummarizeBatchWithOptionsContext(context.Background(), texts, opts)
+}
+
+// SummarizeBatchWithOptionsContext is like SummarizeBatchWithOptions but stops once ctx is done, returning ctx.Err().
+func (m *SummarizationModel) SummarizeBatchWithOptionsContext(ctx context.Context, texts []string, opts SummarizeOptions) ([]string, error) {
+	overrides := opts.toC()
+	return m.summarizeBatch(ctx, texts, &overrides)
+}
+
+// SummarizeLong summarizes a text longer than the model input, such as a meeting
+// transcript. The text is cut into chunks of at most cfg.ChunkSize tokens on sentence
+// and paragraph boundaries and the chunks are summarized as a batch. Their summaries
+// are then joined and summarized again, in chunks while they do not fit in one, until
+// the summary is at most cfg.TargetLength tokens long.
+func (m *SummarizationModel) SummarizeLong(text string, cfg LongSummaryConfig) (*LongSummary, error) {
+	return m.SummarizeLongContext(context.Background(), text, cfg)
+}
+
+// SummarizeLongContext is like SummarizeLong but stops once ctx is done, returning ctx.Err().
+func (m *SummarizationModel) SummarizeLongContext(ctx context.Context, text string, cfg LongSummaryConfig) (*LongSummary, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if cfg.ChunkSize < 1 || cfg.TargetLength < 1 {
+		return nil, invalidInput("SummarizationModel.SummarizeLong", fmt.Sprintf("chunk size and target length must be at least 1, got %d and %d", cfg.ChunkSize, cfg.TargetLength))
 	}
-	defer C.call_free_summarization_result(fnFreeSummarizationResult, res)
 
-	count := int(res.count)
-	if count == 0 {
+	return withContext(ctx, &m.handle, func(ptr *C.SummarizationModelWrapper, cancel *C.CancelToken) (*LongSummary, error) {
+		cText := C.CString(text)
+		defer C.free(unsafe.Pointer(cText))
+		cOpts := C.LongSummarizeOptions{
+			chunk_size:    C.size_t(cfg.ChunkSize),
+			target_length: C.size_t(cfg.TargetLength),
+		}
+		overrides := cfg.Options.toC()
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_summarize_long(fnSummarizeLong, ptr, cText, &cOpts, &overrides, cancel)
+		if res == nil {
+			return nil, lastError("SummarizationModel.SummarizeLong")
+		}
+		defer C.call_free_long_summary_result(fnFreeLongSummaryResult, res)
+
+		return &LongSummary{
+			Summary:        C.GoString(res.summary),
+			ChunkSummaries: goStrings(res.chunk_summaries, res.chunk_count),
+		}, nil
+	})
+}
+
+func (m *SummarizationModel) summarizeBatch(ctx context.Context, texts []string, overrides *C.SummarizeOverrides) ([]string, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if len(texts) == 0 {
 		return []string{}, nil
 	}
 
-	summaries := make([]string, count)
-	cSummaries := unsafe.Slice(res.summaries, count)
+	return withContext(ctx, &m.handle, func(ptr *C.SummarizationModelWrapper, cancel *C.CancelToken) ([]string, error) {
+		cTexts := cStringArray(texts)
+		defer freeCStringArray(cTexts)
 
-	for i := 0; i < count; i++ {
-		summaries[i] = C.GoString(cSummaries[i])
-	}
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
 
-	return summaries, nil
+		res := C.call_summarize_batch(fnSummarizeBatch, ptr, &cTexts[0], C.size_t(len(cTexts)), overrides, cancel)
+		if res == nil {
+			return nil, lastError("SummarizationModel.SummarizeBatch")
+		}
+		defer C.call_free_summarization_result(fnFreeSummarizationResult, res)
+
+		return goStrings(res.summaries, res.count), nil
+	})
 }
 
-// Close frees the underlying Rust model
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
 func (m *SummarizationModel) Close() {
-	if m.ptr != nil {
-		C.call_free_summarization_model(fnFreeSummarizationModel, m.ptr)
-		m.ptr = nil
-	}
+	m.handle.close(func(ptr *C.SummarizationModelWrapper) {
+		C.call_free_summarization_model(fnFreeSummarizationModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *SummarizationModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
 }
 
 // --- Zero-Shot Classification ---
@@ -828,58 +2011,205 @@ type ZeroShotLabel struct {
 	Score float64
 }
 
+// ZeroShotOptions selects how ZeroShotModel.PredictWithOptions builds and scores the
+// hypotheses. Start from DefaultZeroShotOptions.
+type ZeroShotOptions struct {
+	// Template is the hypothesis each label is tested with, with {} standing for the
+	// label, e.g. "This review is about {}.". Empty uses rust-bert's "This example is {}.".
+	Template string
+	// MultiLabel scores every label independently of the others, for texts that can
+	// belong to several labels, and returns them all best first. Otherwise only the
+	// best label is returned.
+	MultiLabel bool
+	// MaxLength is the number of tokens the text and hypothesis are truncated to.
+	MaxLength int
+}
+
+// DefaultZeroShotOptions returns the options Predict and PredictBatch use: rust-bert's
+// template, the best label only and inputs truncated to 128 tokens.
+func DefaultZeroShotOptions() ZeroShotOptions {
+	return ZeroShotOptions{MaxLength: 128}
+}
+
 // ZeroShotModel is a wrapper around the Rust Zero-Shot Classification model
 type ZeroShotModel struct {
-	ptr *C.ZeroShotClassificationModelWrapper
+	handle modelHandle[C.ZeroShotClassificationModelWrapper]
 }
 
 // NewZeroShotModel creates a new Zero-Shot Classification model
 func NewZeroShotModel() (*ZeroShotModel, error) {
-	if !initialized {
-		return nil, errors.New("library not initialized")
+	return NewZeroShotModelOnDevice(DeviceAuto)
+}
+
+// NewZeroShotModelOnDevice is like NewZeroShotModel but loads the model on device.
+func NewZeroShotModelOnDevice(device Device) (*ZeroShotModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
 	}
 
-	ptr := C.call_new_zero_shot_model(fnNewZeroShotModel)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_zero_shot_model(fnNewZeroShotModel, C.int(device))
 	if ptr == nil {
-		return nil, errors.New("failed to create Zero-Shot model")
+		return nil, lastError("NewZeroShotModel")
 	}
-	return &ZeroShotModel{ptr: ptr}, nil
+	return &ZeroShotModel{handle: modelHandle[C.ZeroShotClassificationModelWrapper]{ptr: ptr}}, nil
 }
 
 // Predict performs zero-shot classification
 func (m *ZeroShotModel) Predict(text string, labels []string) ([]ZeroShotLabel, error) {
-	if m.ptr == nil {
-		return nil, errors.New("model is closed")
-	}
+	return withModel(&m.handle, func(ptr *C.ZeroShotClassificationModelWrapper) ([]ZeroShotLabel, error) {
+		if len(labels) == 0 {
+			return nil, invalidInput("ZeroShotModel.Predict", "labels cannot be empty")
+		}
 
-	if len(labels) == 0 {
-		return nil, errors.New("labels cannot be empty")
-	}
+		cText := C.CString(text)
+		defer C.free(unsafe.Pointer(cText))
+
+		cLabels := make([]*C.char, len(labels))
+		for i, label := range labels {
+			cLabels[i] = C.CString(label)
+			defer C.free(unsafe.Pointer(cLabels[i]))
+		}
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_predict_zero_shot(
+			fnPredictZeroShot,
+			ptr,
+			cText,
+			&cLabels[0],
+			C.size_t(len(labels)),
+		)
+		if res == nil {
+			return nil, lastError("ZeroShotModel.Predict")
+		}
+		defer C.call_free_zero_shot_result(fnFreeZeroShotResult, res)
 
-	cText := C.CString(text)
-	defer C.free(unsafe.Pointer(cText))
+		return zeroShotLabels(res), nil
+	})
+}
 
-	cLabels := make([]*C.char, len(labels))
-	for i, label := range labels {
-		cLabels[i] = C.CString(label)
-		defer C.free(unsafe.Pointer(cLabels[i]))
+// PredictContext is like Predict but returns ctx.Err() as soon as ctx is done.
+func (m *ZeroShotModel) PredictContext(ctx context.Context, text string, labels []string) ([]ZeroShotLabel, error) {
+	results, err := m.PredictBatchContext(ctx, []string{text}, labels)
+	if err != nil {
+		return nil, err
 	}
+	return results[0], nil
+}
 
-	res := C.call_predict_zero_shot(
-		fnPredictZeroShot,
-		m.ptr,
-		cText,
-		&cLabels[0],
-		C.size_t(len(labels)),
-	)
-	if res == nil {
-		return nil, errors.New("zero-shot prediction failed")
+// PredictBatch classifies several texts against the same candidate labels in a
+// single forward pass. The labels for texts[i] are returned at index i.
+func (m *ZeroShotModel) PredictBatch(texts []string, labels []string) ([][]ZeroShotLabel, error) {
+	return m.PredictBatchContext(context.Background(), texts, labels)
+}
+
+// PredictBatchContext is like PredictBatch but stops once ctx is done, returning ctx.Err().
+func (m *ZeroShotModel) PredictBatchContext(ctx context.Context, texts []string, labels []string) ([][]ZeroShotLabel, error) {
+	return m.predictBatch(ctx, "ZeroShotModel.PredictBatch", texts, labels, nil)
+}
+
+// PredictWithOptions is like Predict but builds and scores the hypotheses with opts.
+func (m *ZeroShotModel) PredictWithOptions(text string, labels []string, opts ZeroShotOptions) ([]ZeroShotLabel, error) {
+	return m.PredictWithOptionsContext(context.Background(), text, labels, opts)
+}
+
+// PredictWithOptionsContext is like PredictWithOptions but stops once ctx is done, returning ctx.Err().
+func (m *ZeroShotModel) PredictWithOptionsContext(ctx context.Context, text string, labels []string, opts ZeroShotOptions) ([]ZeroShotLabel, error) {
+	return first(m.predictBatch(ctx, "ZeroShotModel.PredictWithOptions", []string{text}, labels, &opts))
+}
+
+// PredictBatchWithOptions is like PredictBatch but builds and scores the hypotheses
+// with opts.
+func (m *ZeroShotModel) PredictBatchWithOptions(texts []string, labels []string, opts ZeroShotOptions) ([][]ZeroShotLabel, error) {
+	return m.PredictBatchWithOptionsContext(context.Background(), texts, labels, opts)
+}
+
+// PredictBatchWithOptionsContext is like PredictBatchWithOptions but stops once ctx is done, returning ctx.Err().
+func (m *ZeroShotModel) PredictBatchWithOptionsContext(ctx context.Context, texts []string, labels []string, opts ZeroShotOptions) ([][]ZeroShotLabel, error) {
+	return m.predictBatch(ctx, "ZeroShotModel.PredictBatchWithOptions", texts, labels, &opts)
+}
+
+func (m *ZeroShotModel) predictBatch(ctx context.Context, op string, texts []string, labels []string, opts *ZeroShotOptions) ([][]ZeroShotLabel, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if len(labels) == 0 {
+		return nil, invalidInput(op, "labels cannot be empty")
+	}
+	if opts != nil {
+		if opts.Template != "" && !strings.Contains(opts.Template, "{}") {
+			return nil, invalidInput(op, fmt.Sprintf("template %q has no {} placeholder for the label", opts.Template))
+		}
+		if opts.MaxLength < 1 {
+			return nil, invalidInput(op, fmt.Sprintf("MaxLength must be at least 1, got %d", opts.MaxLength))
+		}
 	}
-	defer C.call_free_zero_shot_result(fnFreeZeroShotResult, res)
+	if len(texts) == 0 {
+		return [][]ZeroShotLabel{}, nil
+	}
+
+	return withContext(ctx, &m.handle, func(ptr *C.ZeroShotClassificationModelWrapper, cancel *C.CancelToken) ([][]ZeroShotLabel, error) {
+		cTexts := cStringArray(texts)
+		defer freeCStringArray(cTexts)
+		cLabels := cStringArray(labels)
+		defer freeCStringArray(cLabels)
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		var res *C.ZeroShotBatchResult
+		if opts == nil {
+			res = C.call_predict_zero_shot_batch(
+				fnPredictZeroShotBatch,
+				ptr,
+				&cTexts[0],
+				C.size_t(len(cTexts)),
+				&cLabels[0],
+				C.size_t(len(cLabels)),
+				cancel,
+			)
+		} else {
+			cOpts := C.ZeroShotOptions{
+				multi_label: C.bool(opts.MultiLabel),
+				max_length:  C.size_t(opts.MaxLength),
+			}
+			if opts.Template != "" {
+				cOpts.template = C.CString(opts.Template)
+				defer C.free(unsafe.Pointer(cOpts.template))
+			}
+			res = C.call_predict_zero_shot_with_options(
+				fnPredictZeroShotWithOptions,
+				ptr,
+				&cTexts[0],
+				C.size_t(len(cTexts)),
+				&cLabels[0],
+				C.size_t(len(cLabels)),
+				&cOpts,
+				cancel,
+			)
+		}
+		if res == nil {
+			return nil, lastError(op)
+		}
+		defer C.call_free_zero_shot_batch_result(fnFreeZeroShotBatchResult, res)
 
+		cResults := unsafe.Slice(res.results, int(res.count))
+		results := make([][]ZeroShotLabel, len(cResults))
+		for i := range cResults {
+			results[i] = zeroShotLabels(&cResults[i])
+		}
+		return results, nil
+	})
+}
+
+func zeroShotLabels(res *C.ZeroShotResult) []ZeroShotLabel {
 	count := int(res.count)
 	if count == 0 {
-		return []ZeroShotLabel{}, nil
+		return []ZeroShotLabel{}
 	}
 
 	results := make([]ZeroShotLabel, count)
@@ -892,138 +2222,536 @@ func (m *ZeroShotModel) Predict(text string, labels []string) ([]ZeroShotLabel,
 		}
 	}
 
-	return results, nil
+	return results
 }
 
-// Close frees the underlying Rust model
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
 func (m *ZeroShotModel) Close() {
-	if m.ptr != nil {
-		C.call_free_zero_shot_model(fnFreeZeroShotModel, m.ptr)
-		m.ptr = nil
-	}
+	m.handle.close(func(ptr *C.ZeroShotClassificationModelWrapper) {
+		C.call_free_zero_shot_model(fnFreeZeroShotModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *ZeroShotModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
 }
 
 // --- Translation ---
 
 // TranslationModel is a wrapper around the Rust Translation model
 type TranslationModel struct {
-	ptr *C.TranslationModelWrapper
+	handle modelHandle[C.TranslationModelWrapper]
 }
 
-// NewTranslationModel creates a new Translation model
+// NewTranslationModel creates a new Translation model translating English to French,
+// Spanish, Italian and Portuguese (Marian)
 func NewTranslationModel() (*TranslationModel, error) {
-	if !initialized {
-		return nil, errors.New("library not initialized")
+	return NewTranslationModelOnDevice(DeviceAuto)
+}
+
+// NewTranslationModelOnDevice is like NewTranslationModel but loads the model on device.
+func NewTranslationModelOnDevice(device Device) (*TranslationModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
 	}
 
-	ptr := C.call_new_translation_model(fnNewTranslationModel)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_translation_model(fnNewTranslationModel, C.int(device))
 	if ptr == nil {
-		return nil, errors.New("failed to create Translation model")
+		return nil, lastError("NewTranslationModel")
 	}
-	return &TranslationModel{ptr: ptr}, nil
+	return &TranslationModel{handle: modelHandle[C.TranslationModelWrapper]{ptr: ptr}}, nil
 }
 
-// Translate performs translation of text
-// sourceLang can be empty string for auto-detection or default behavior (though M2M100 usually needs it, generic binding supports optional)
-func (m *TranslationModel) Translate(text string, sourceLang string, targetLang string) (string, error) {
-	if m.ptr == nil {
-		return "", errors.New("model is closed")
+// TranslationModelBuilder configures a TranslationModel the way rust-bert's
+// TranslationModelBuilder does: pick the languages to support and, optionally, the
+// architecture, and let the builder select a matching pretrained checkpoint.
+//
+//	model, err := rustbert.NewTranslationModelBuilder().
+//		WithSourceLanguages("de").
+//		WithTargetLanguages("en").
+//		CreateModel()
+//
+// Languages are given as English names ("German", "Chinese Mandarin") or ISO 639-1/639-3
+// codes ("de", "deu"), case-insensitively.
+type TranslationModelBuilder struct {
+	modelType       int
+	sourceLanguages []string
+	targetLanguages []string
+	device          Device
+}
+
+// NewTranslationModelBuilder returns a builder that picks the model type and runs on
+// DeviceAuto.
+func NewTranslationModelBuilder() *TranslationModelBuilder {
+	return &TranslationModelBuilder{modelType: -1, device: DeviceAuto}
+}
+
+// WithModelType restricts the builder to ModelTypeMarian, ModelTypeM2M100,
+// ModelTypeMBart or ModelTypeNLLB. By default Marian is used when a checkpoint covers
+// every requested pair and M2M100 otherwise.
+func (b *TranslationModelBuilder) WithModelType(modelType int) *TranslationModelBuilder {
+	b.modelType = modelType
+	return b
+}
+
+// WithSourceLanguages sets the languages the model must translate from.
+func (b *TranslationModelBuilder) WithSourceLanguages(languages ...string) *TranslationModelBuilder {
+	b.sourceLanguages = languages
+	return b
+}
+
+// WithTargetLanguages sets the languages the model must translate to.
+func (b *TranslationModelBuilder) WithTargetLanguages(languages ...string) *TranslationModelBuilder {
+	b.targetLanguages = languages
+	return b
+}
+
+// WithDevice sets the device the model runs on.
+func (b *TranslationModelBuilder) WithDevice(device Device) *TranslationModelBuilder {
+	b.device = device
+	return b
+}
+
+// CreateModel downloads (if needed) and loads the checkpoint matching the builder
+// settings. Unknown languages and model types are rejected with ErrInvalidInput; a
+// language pair no checkpoint of the requested type supports fails with ErrModelLoad.
+func (b *TranslationModelBuilder) CreateModel() (*TranslationModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
 	}
 
-	if targetLang == "" {
-		return "", errors.New("target language cannot be empty")
+	cSources := cStringArray(b.sourceLanguages)
+	defer freeCStringArray(cSources)
+	cTargets := cStringArray(b.targetLanguages)
+	defer freeCStringArray(cTargets)
+
+	var sources, targets **C.char
+	if len(cSources) > 0 {
+		sources = &cSources[0]
+	}
+	if len(cTargets) > 0 {
+		targets = &cTargets[0]
 	}
 
-	cText := C.CString(text)
-	defer C.free(unsafe.Pointer(cText))
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_translation_model_with_builder(
+		fnNewTranslationModelWithBuilder,
+		C.int(b.modelType),
+		sources,
+		C.size_t(len(cSources)),
+		targets,
+		C.size_t(len(cTargets)),
+		C.int(b.device),
+	)
+	if ptr == nil {
+		return nil, lastError("TranslationModelBuilder.CreateModel")
+	}
+	return &TranslationModel{handle: modelHandle[C.TranslationModelWrapper]{ptr: ptr}}, nil
+}
+
+// Translate performs translation of text from sourceLang to targetLang, given as
+// language names or ISO codes like the TranslationModelBuilder languages.
+// sourceLang can be empty for models with a single source language. A language the
+// model was not built for is rejected with ErrInvalidInput. Models loaded from files
+// translate the pair they were trained on, whatever the languages requested.
+func (m *TranslationModel) Translate(text string, sourceLang string, targetLang string) (string, error) {
+	return withModel(&m.handle, func(ptr *C.TranslationModelWrapper) (string, error) {
+		if targetLang == "" {
+			return "", invalidInput("TranslationModel.Translate", "target language cannot be empty")
+		}
+
+		cText := C.CString(text)
+		defer C.free(unsafe.Pointer(cText))
+
+		cTarget := C.CString(targetLang)
+		defer C.free(unsafe.Pointer(cTarget))
+
+		var cSource *C.char
+		if sourceLang != "" {
+			cSource = C.CString(sourceLang)
+			defer C.free(unsafe.Pointer(cSource))
+		}
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		cRes := C.call_translate(
+			fnTranslate,
+			ptr,
+			cText,
+			cSource,
+			cTarget,
+		)
+		if cRes == nil {
+			return "", lastError("TranslationModel.Translate")
+		}
+		defer freeString(cRes)
 
-	cTarget := C.CString(targetLang)
-	defer C.free(unsafe.Pointer(cTarget))
+		return C.GoString(cRes), nil
+	})
+}
 
-	var cSource *C.char
-	if sourceLang != "" {
-		cSource = C.CString(sourceLang)
-		defer C.free(unsafe.Pointer(cSource))
+// TranslateContext is like Translate but returns ctx.Err() as soon as ctx is done.
+// rust-bert translates in a single call that cannot be interrupted: a translation
+// already started completes in the background and holds the model until it has.
+func (m *TranslationModel) TranslateContext(ctx context.Context, text string, sourceLang string, targetLang string) (string, error) {
+	results, err := m.TranslateBatchContext(ctx, []string{text}, sourceLang, targetLang)
+	if err != nil {
+		return "", err
 	}
+	return results[0], nil
+}
 
-	cRes := C.call_translate(
-		fnTranslate,
-		m.ptr,
-		cText,
-		cSource,
-		cTarget,
-	)
-	if cRes == nil {
-		return "", errors.New("translation failed")
+// TranslateBatch translates several texts in a single generation pass. The
+// translation of texts[i] is returned at index i. Languages are handled as in
+// Translate.
+func (m *TranslationModel) TranslateBatch(texts []string, sourceLang string, targetLang string) ([]string, error) {
+	return m.TranslateBatchContext(context.Background(), texts, sourceLang, targetLang)
+}
+
+// TranslateBatchContext is like TranslateBatch but stops once ctx is done, returning
+// ctx.Err(). As with TranslateContext, a batch already started completes in the
+// background.
+func (m *TranslationModel) TranslateBatchContext(ctx context.Context, texts []string, sourceLang string, targetLang string) ([]string, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+
+	if targetLang == "" {
+		return nil, invalidInput("TranslationModel.TranslateBatch", "target language cannot be empty")
 	}
-	defer C.free(unsafe.Pointer(cRes))
+	if len(texts) == 0 {
+		return []string{}, nil
+	}
+
+	return withContext(ctx, &m.handle, func(ptr *C.TranslationModelWrapper, cancel *C.CancelToken) ([]string, error) {
+		cTexts := cStringArray(texts)
+		defer freeCStringArray(cTexts)
+
+		cTarget := C.CString(targetLang)
+		defer C.free(unsafe.Pointer(cTarget))
+
+		var cSource *C.char
+		if sourceLang != "" {
+			cSource = C.CString(sourceLang)
+			defer C.free(unsafe.Pointer(cSource))
+		}
 
-	return C.GoString(cRes), nil
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_translate_batch(
+			fnTranslateBatch,
+			ptr,
+			&cTexts[0],
+			C.size_t(len(cTexts)),
+			cSource,
+			cTarget,
+			cancel,
+		)
+		if res == nil {
+			return nil, lastError("TranslationModel.TranslateBatch")
+		}
+		defer C.call_free_string_array(fnFreeStringArray, res)
+
+		return goStrings(res.items, res.count), nil
+	})
 }
 
-// Close frees the underlying Rust model
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
 func (m *TranslationModel) Close() {
-	if m.ptr != nil {
-		C.call_free_translation_model(fnFreeTranslationModel, m.ptr)
-		m.ptr = nil
-	}
+	m.handle.close(func(ptr *C.TranslationModelWrapper) {
+		C.call_free_translation_model(fnFreeTranslationModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *TranslationModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
 }
 
 // --- Text Generation ---
 
+// GenerateOptions controls decoding for a TextGenerationModel. Start from
+// DefaultGenerateOptions and change what you need; the zero value is not valid.
+type GenerateOptions struct {
+	// MaxLength is the maximum length of the output in tokens, prompt included.
+	// 0 means no explicit limit.
+	MaxLength int
+	// MinLength is the minimum length of the output in tokens.
+	MinLength int
+	// DoSample enables sampling. When false, decoding is greedy (or beam search
+	// with NumBeams > 1) and therefore deterministic.
+	DoSample bool
+	// EarlyStopping stops beam search once NumBeams finished sequences exist.
+	EarlyStopping bool
+	// Temperature rescales the logits before sampling.
+	Temperature float64
+	// TopK samples only from the k most likely tokens; 0 disables it.
+	TopK int
+	// TopP samples from the smallest token set whose probability reaches p.
+	TopP float64
+	// RepetitionPenalty penalizes tokens already present; 1.0 disables it.
+	RepetitionPenalty float64
+	// NoRepeatNgramSize forbids repeating n-grams of that size; 0 disables it.
+	NoRepeatNgramSize int
+	// NumBeams is the beam width; 1 disables beam search.
+	NumBeams int
+	// NumReturnSequences is the number of sequences generated per prompt.
+	// Without sampling it cannot exceed NumBeams.
+	NumReturnSequences int
+	// LengthPenalty is the exponential length penalty applied in beam search.
+	LengthPenalty float64
+	// Seed seeds the random generator before every call, making sampled output
+	// reproducible. Negative values leave it unseeded. libtorch has a single random
+	// generator per process, so seeded calls run one at a time, and the output is only
+	// reproducible while no unseeded sampling runs alongside them.
+	Seed int64
+}
+
+// DefaultGenerateOptions returns the options NewTextGenerationModel uses, which
+// match rust-bert's TextGenerationConfig defaults.
+func DefaultGenerateOptions() GenerateOptions {
+	return GenerateOptions{
+		MaxLength:          56,
+		MinLength:          0,
+		DoSample:           true,
+		EarlyStopping:      true,
+		Temperature:        1.0,
+		TopK:               0,
+		TopP:               0.9,
+		RepetitionPenalty:  1.0,
+		NoRepeatNgramSize:  3,
+		NumBeams:           5,
+		NumReturnSequences: 1,
+		LengthPenalty:      1.0,
+		Seed:               -1,
+	}
+}
+
+func (o GenerateOptions) toC() C.GenerateOptions {
+	return C.GenerateOptions{
+		max_length:           C.int64_t(o.MaxLength),
+		min_length:           C.int64_t(o.MinLength),
+		do_sample:            C.bool(o.DoSample),
+		early_stopping:       C.bool(o.EarlyStopping),
+		temperature:          C.double(o.Temperature),
+		top_k:                C.int64_t(o.TopK),
+		top_p:                C.double(o.TopP),
+		repetition_penalty:   C.double(o.RepetitionPenalty),
+		no_repeat_ngram_size: C.int64_t(o.NoRepeatNgramSize),
+		num_beams:            C.int64_t(o.NumBeams),
+		num_return_sequences: C.int64_t(o.NumReturnSequences),
+		length_penalty:       C.double(o.LengthPenalty),
+		seed:                 C.int64_t(o.Seed),
+	}
+}
+
 // TextGenerationModel is a wrapper around the Rust Text Generation model
 type TextGenerationModel struct {
-	ptr *C.TextGenerationModelWrapper
+	handle modelHandle[C.TextGenerationModelWrapper]
 }
 
 // NewTextGenerationModel creates a new TextGeneration model (GPT2 Medium by default)
 func NewTextGenerationModel() (*TextGenerationModel, error) {
-	if !initialized {
-		return nil, errors.New("library not initialized")
+	return NewTextGenerationModelOnDevice(DeviceAuto)
+}
+
+// NewTextGenerationModelOnDevice is like NewTextGenerationModel but loads the model on device.
+func NewTextGenerationModelOnDevice(device Device) (*TextGenerationModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
 	}
 
-	ptr := C.call_new_text_generation_model(fnNewTextGenerationModel)
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_text_generation_model(fnNewTextGenerationModel, C.int(device))
 	if ptr == nil {
-		return nil, errors.New("failed to create Text Generation model")
+		return nil, lastError("NewTextGenerationModel")
 	}
-	return &TextGenerationModel{ptr: ptr}, nil
+	return &TextGenerationModel{handle: modelHandle[C.TextGenerationModelWrapper]{ptr: ptr}}, nil
+}
+
+// NewTextGenerationModelWithOptions creates the default TextGeneration model (GPT2
+// Medium) generating with opts. Invalid options, such as NumBeams < 1 or
+// NumReturnSequences > NumBeams without sampling, are rejected with ErrInvalidInput.
+func NewTextGenerationModelWithOptions(opts GenerateOptions) (*TextGenerationModel, error) {
+	return NewTextGenerationModelWithOptionsOnDevice(opts, DeviceAuto)
+}
+
+// NewTextGenerationModelWithOptionsOnDevice is like NewTextGenerationModelWithOptions
+// but loads the model on device.
+func NewTextGenerationModelWithOptionsOnDevice(opts GenerateOptions, device Device) (*TextGenerationModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+
+	cOpts := opts.toC()
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_text_generation_model_with_options(fnNewTextGenerationModelWithOptions, &cOpts, C.int(device))
+	if ptr == nil {
+		return nil, lastError("NewTextGenerationModelWithOptions")
+	}
+	return &TextGenerationModel{handle: modelHandle[C.TextGenerationModelWrapper]{ptr: ptr}}, nil
 }
 
 // Generate generates text based on prompt
-// prefix can be empty string.
+// prefix can be empty string; XLNet models then use rust-bert's default prefix, as its
+// pipeline does. The prefix is not part of the output. When the model was created with
+// NumReturnSequences > 1 only the first sequence is returned; use
+// GenerateSequences to get all of them.
 func (m *TextGenerationModel) Generate(prompt string, prefix string) (string, error) {
-	if m.ptr == nil {
-		return "", errors.New("model is closed")
-	}
+	return withModel(&m.handle, func(ptr *C.TextGenerationModelWrapper) (string, error) {
+		cPrompt := C.CString(prompt)
+		defer C.free(unsafe.Pointer(cPrompt))
+
+		var cPrefix *C.char
+		if prefix != "" {
+			cPrefix = C.CString(prefix)
+			defer C.free(unsafe.Pointer(cPrefix))
+		}
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		cRes := C.call_generate_text(
+			fnGenerateText,
+			ptr,
+			cPrompt,
+			cPrefix,
+		)
+		if cRes == nil {
+			return "", lastError("TextGenerationModel.Generate")
+		}
+		defer freeString(cRes)
 
-	cPrompt := C.CString(prompt)
-	defer C.free(unsafe.Pointer(cPrompt))
+		return C.GoString(cRes), nil
+	})
+}
 
-	var cPrefix *C.char
-	if prefix != "" {
-		cPrefix = C.CString(prefix)
-		defer C.free(unsafe.Pointer(cPrefix))
+// GenerateContext is like Generate but returns ctx.Err() as soon as ctx is done.
+func (m *TextGenerationModel) GenerateContext(ctx context.Context, prompt string, prefix string) (string, error) {
+	results, err := m.GenerateBatchContext(ctx, []string{prompt}, prefix)
+	if err != nil {
+		return "", err
 	}
+	return results[0], nil
+}
 
-	// We pass dummy values for params we aren't supporting dynamically yet
-	cRes := C.call_generate_text(
-		fnGenerateText,
-		m.ptr,
-		cPrompt,
-		cPrefix,
-	)
-	if cRes == nil {
-		return "", errors.New("generation failed")
+// GenerateSequences generates NumReturnSequences continuations of prompt.
+// prefix can be empty string.
+func (m *TextGenerationModel) GenerateSequences(prompt string, prefix string) ([]string, error) {
+	return m.GenerateBatchContext(context.Background(), []string{prompt}, prefix)
+}
+
+// GenerateSequencesContext is like GenerateSequences but returns ctx.Err() as soon as ctx is done.
+func (m *TextGenerationModel) GenerateSequencesContext(ctx context.Context, prompt string, prefix string) ([]string, error) {
+	return m.GenerateBatchContext(ctx, []string{prompt}, prefix)
+}
+
+// GenerateBatch generates a continuation for each prompt in a single
+// generation pass. The output for prompts[i] is returned at index i, or with
+// NumReturnSequences = n > 1, the n outputs for prompts[i] start at index i*n.
+// prefix can be empty string.
+func (m *TextGenerationModel) GenerateBatch(prompts []string, prefix string) ([]string, error) {
+	return m.GenerateBatchContext(context.Background(), prompts, prefix)
+}
+
+// GenerateBatchContext is like GenerateBatch but stops once ctx is done, returning ctx.Err().
+func (m *TextGenerationModel) GenerateBatchContext(ctx context.Context, prompts []string, prefix string) ([]string, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
 	}
-	defer C.free(unsafe.Pointer(cRes))
+	if len(prompts) == 0 {
+		return []string{}, nil
+	}
+
+	return withContext(ctx, &m.handle, func(ptr *C.TextGenerationModelWrapper, cancel *C.CancelToken) ([]string, error) {
+		cPrompts := cStringArray(prompts)
+		defer freeCStringArray(cPrompts)
 
-	return C.GoString(cRes), nil
+		var cPrefix *C.char
+		if prefix != "" {
+			cPrefix = C.CString(prefix)
+			defer C.free(unsafe.Pointer(cPrefix))
+		}
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_generate_text_batch(
+			fnGenerateTextBatch,
+			ptr,
+			&cPrompts[0],
+			C.size_t(len(cPrompts)),
+			cPrefix,
+			cancel,
+		)
+		if res == nil {
+			return nil, lastError("TextGenerationModel.GenerateBatch")
+		}
+		defer C.call_free_string_array(fnFreeStringArray, res)
+
+		return goStrings(res.items, res.count), nil
+	})
 }
 
-// Close frees the underlying Rust model
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
 func (m *TextGenerationModel) Close() {
-	if m.ptr != nil {
-		C.call_free_text_generation_model(fnFreeTextGenerationModel, m.ptr)
-		m.ptr = nil
+	m.handle.close(func(ptr *C.TextGenerationModelWrapper) {
+		C.call_free_text_generation_model(fnFreeTextGenerationModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *TextGenerationModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
+}
+
+// --- Helpers ---
+
+// cStringArray copies strs into C memory. The result must be released with
+// freeCStringArray.
+func cStringArray(strs []string) []*C.char {
+	cStrs := make([]*C.char, len(strs))
+	for i, s := range strs {
+		cStrs[i] = C.CString(s)
+	}
+	return cStrs
+}
+
+func freeCStringArray(cStrs []*C.char) {
+	for _, s := range cStrs {
+		C.free(unsafe.Pointer(s))
+	}
+}
+
+// goStrings copies a C array of count strings into a Go slice.
+func goStrings(items **C.char, count C.size_t) []string {
+	n := int(count)
+	if n == 0 {
+		return []string{}
+	}
+
+	strs := make([]string, n)
+	for i, s := range unsafe.Slice(items, n) {
+		strs[i] = C.GoString(s)
 	}
+	return strs
 }
@@ -1,8 +1,17 @@
 package rustbert
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/gomlx/go-huggingface/hub"
 )
 
 func TestSentimentAnalysis(t *testing.T) {
@@ -45,6 +54,190 @@ func TestSentimentAnalysis(t *testing.T) {
 	}
 }
 
+func TestSentimentAnalysisBatch(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	model, err := NewSentimentModel()
+	if err != nil {
+		t.Fatalf("Failed to create sentiment model: %v", err)
+	}
+	defer model.Close()
+
+	texts := []string{"I love this library!", "This is terrible.", "Absolutely fantastic work."}
+	expected := []string{"POSITIVE", "NEGATIVE", "POSITIVE"}
+
+	results, err := model.PredictBatch(texts)
+	if err != nil {
+		t.Fatalf("PredictBatch failed: %v", err)
+	}
+	if len(results) != len(texts) {
+		t.Fatalf("Expected %d results, got %d", len(texts), len(results))
+	}
+	for i, result := range results {
+		if result.Label != expected[i] {
+			t.Errorf("Expected %s, got %s for text: %s", expected[i], result.Label, texts[i])
+		}
+	}
+
+	empty, err := model.PredictBatch(nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("PredictBatch(nil) = %v, %v; want empty result", empty, err)
+	}
+}
+
+func TestSentimentAnalysisContext(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	model, err := NewSentimentModel()
+	if err != nil {
+		t.Fatalf("Failed to create sentiment model: %v", err)
+	}
+	defer model.Close()
+
+	result, err := model.PredictContext(context.Background(), "I love this library!")
+	if err != nil {
+		t.Fatalf("PredictContext failed: %v", err)
+	}
+	if result.Label != "POSITIVE" {
+		t.Errorf("Expected POSITIVE, got %s", result.Label)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := model.PredictContext(ctx, "I love this library!"); !errors.Is(err, context.Canceled) {
+		t.Errorf("PredictContext with cancelled context: got %v, want context.Canceled", err)
+	}
+
+	texts := make([]string, 256)
+	for i := range texts {
+		texts[i] = "This is a fairly long sentence that keeps the model busy for a little while."
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := model.PredictBatchContext(ctx, texts); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("PredictBatchContext past deadline: got %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("PredictBatchContext returned %v after the deadline", elapsed)
+	}
+}
+
+func TestSentimentAnalysisConcurrent(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	model, err := NewSentimentModel()
+	if err != nil {
+		t.Fatalf("Failed to create sentiment model: %v", err)
+	}
+
+	// Close while calls are in flight: every call either completes or fails with
+	// ErrClosed, and none touches the freed model (run with -race).
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 4; j++ {
+				result, err := model.Predict("I love this library!")
+				if errors.Is(err, ErrClosed) {
+					return
+				}
+				if err != nil {
+					t.Errorf("Predict failed: %v", err)
+					return
+				}
+				if result.Label != "POSITIVE" {
+					t.Errorf("Expected POSITIVE, got %s", result.Label)
+				}
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	model.Close()
+	wg.Wait()
+
+	if _, err := model.Predict("I love this library!"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Predict after Close: got %v, want ErrClosed", err)
+	}
+	if _, err := model.PredictBatch([]string{"I love this library!"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("PredictBatch after Close: got %v, want ErrClosed", err)
+	}
+	model.Close()
+}
+
+func TestSentimentAnalysisBatcher(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	model, err := NewSentimentModel()
+	if err != nil {
+		t.Fatalf("Failed to create sentiment model: %v", err)
+	}
+	defer model.Close()
+
+	b := NewBatcher(model.PredictBatchContext, BatcherOptions{MaxBatchSize: 8, MaxLatency: 10 * time.Millisecond})
+	defer b.Close()
+
+	texts := []string{"I love this library!", "This is terrible.", "Absolutely fantastic work.", "I am very disappointed."}
+	expected := []string{"POSITIVE", "NEGATIVE", "POSITIVE", "NEGATIVE"}
+
+	var wg sync.WaitGroup
+	for i := range texts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := b.Predict(context.Background(), texts[i])
+			if err != nil {
+				t.Errorf("Predict failed: %v", err)
+				return
+			}
+			if result.Label != expected[i] {
+				t.Errorf("Expected %s, got %s for text: %s", expected[i], result.Label, texts[i])
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSentimentAnalysisLong(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	model, err := NewSentimentModel()
+	if err != nil {
+		t.Fatalf("Failed to create sentiment model: %v", err)
+	}
+	defer model.Close()
+
+	// Far beyond the 512 tokens of the model
+	text := strings.Repeat("I love this library, it works wonderfully. ", 200)
+	cfg := DefaultWindowConfig()
+	for _, aggregation := range []ScoreAggregation{ScoreMean, ScoreMax, ScoreFirst} {
+		cfg.Aggregation = aggregation
+		result, err := model.PredictLong(text, cfg)
+		if err != nil {
+			t.Fatalf("PredictLong(aggregation %d) failed: %v", aggregation, err)
+		}
+		if result.Label != "POSITIVE" {
+			t.Errorf("PredictLong(aggregation %d) = %s, want POSITIVE", aggregation, result.Label)
+		}
+	}
+
+	cfg.Stride = cfg.WindowSize
+	if _, err := model.PredictLong(text, cfg); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("PredictLong with stride == window size: got %v, want ErrInvalidInput", err)
+	}
+}
+
 func TestPOSTagging(t *testing.T) {
 	if err := Init(); err != nil {
 		t.Fatalf("Failed to initialize library: %v", err)
@@ -132,6 +325,45 @@ func TestNER(t *testing.T) {
 	}
 }
 
+func TestNERLong(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	model, err := NewNERModel()
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	sentence := "My name is Amy. I live in Paris. "
+	text := strings.Repeat(sentence, 60)
+	// Small windows so that every sentence is seen by two of them
+	cfg := WindowConfig{WindowSize: 64, Stride: 32}
+
+	entities, err := model.PredictLong(text, cfg)
+	if err != nil {
+		t.Fatalf("PredictLong(NER) error = %v", err)
+	}
+
+	// Character offsets, the text is ASCII
+	paris := 0
+	for i, e := range entities {
+		if got := text[e.Offset.Begin:e.Offset.End]; got != e.Word {
+			t.Errorf("entity %q at %d-%d covers %q", e.Word, e.Offset.Begin, e.Offset.End, got)
+		}
+		if i > 0 && e.Offset.Begin < entities[i-1].Offset.End {
+			t.Errorf("entity %q at %d overlaps the previous one", e.Word, e.Offset.Begin)
+		}
+		if e.Word == "Paris" {
+			paris++
+		}
+	}
+	if paris != 60 {
+		t.Errorf("found Paris %d times, want 60", paris)
+	}
+}
+
 func TestQA(t *testing.T) {
 	if err := Init(); err != nil {
 		t.Fatalf("Failed to initialize library: %v", err)
@@ -174,6 +406,100 @@ func TestQA(t *testing.T) {
 	}
 }
 
+func TestQABatch(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	model, err := NewQAModel()
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	inputs := []QAInput{
+		{Question: "Where does Amy live?", Context: "Amy lives in Amsterdam."},
+		{Question: "What is Bob's job?", Context: "Bob works as a carpenter in Berlin."},
+	}
+	expected := []string{"Amsterdam", "carpenter"}
+
+	results, err := model.PredictBatch(inputs)
+	if err != nil {
+		t.Fatalf("PredictBatch(QA) error = %v", err)
+	}
+	if len(results) != len(inputs) {
+		t.Fatalf("Expected %d results, got %d", len(inputs), len(results))
+	}
+	for i, answers := range results {
+		if len(answers) == 0 || !strings.Contains(answers[0].Answer, expected[i]) {
+			t.Errorf("Expected answer containing %q for %q, got %v", expected[i], inputs[i].Question, answers)
+		}
+	}
+}
+
+func TestQAOptions(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	cfg := DefaultQAConfig()
+	cfg.MaxAnswerLength = 10
+	cfg.Options.TopK = 3
+	model, err := NewQAModelWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	passage := "Amy lives in Amsterdam. She moved there from Berlin in 2019 to work as a carpenter."
+
+	// The config options apply to Predict
+	answers, err := model.Predict("Where does Amy live?", passage)
+	if err != nil {
+		t.Fatalf("Predict(QA) error = %v", err)
+	}
+	if len(answers) != 3 {
+		t.Fatalf("Expected 3 answers, got %v", answers)
+	}
+	for i, a := range answers {
+		if got := string([]rune(passage)[a.Start:a.End]); got != a.Answer {
+			t.Errorf("Answer %q at %d-%d covers %q", a.Answer, a.Start, a.End, got)
+		}
+		if i > 0 && a.Score > answers[i-1].Score {
+			t.Errorf("Answers are not ranked: %v", answers)
+		}
+	}
+	if !strings.Contains(answers[0].Answer, "Amsterdam") {
+		t.Errorf("Expected best answer containing Amsterdam, got %q", answers[0].Answer)
+	}
+
+	prediction, err := model.PredictWithOptions("Where does Amy live?", passage, QAOptions{TopK: 1, MinScore: 0.1})
+	if err != nil {
+		t.Fatalf("PredictWithOptions error = %v", err)
+	}
+	if len(prediction.Answers) != 1 {
+		t.Errorf("Expected one answer, got %+v", prediction)
+	}
+
+	// Nothing in the passage comes close to answering
+	prediction, err = model.PredictWithOptions("What is the boiling point of mercury?", passage, QAOptions{TopK: 1, MinScore: 0.99})
+	if err != nil {
+		t.Fatalf("PredictWithOptions error = %v", err)
+	}
+	if len(prediction.Answers) != 0 {
+		t.Errorf("Expected every answer below MinScore to be dropped, got %+v", prediction)
+	}
+
+	if _, err := model.PredictWithOptions("Where does Amy live?", passage, QAOptions{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for TopK 0, got %v", err)
+	}
+	bad := DefaultQAConfig()
+	bad.DocStride = bad.MaxSeqLength
+	if _, err := NewQAModelWithConfig(bad); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for DocStride >= MaxSeqLength, got %v", err)
+	}
+}
+
 func TestSummarization(t *testing.T) {
 	if err := Init(); err != nil {
 		t.Fatalf("Failed to initialize library: %v", err)
@@ -205,6 +531,101 @@ func TestSummarization(t *testing.T) {
 	}
 }
 
+func TestSummarizationWithConfig(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	cfg := DefaultSummarizationConfig()
+	cfg.Model = SummarizationDistilBartCNN
+	cfg.MinLength = 10
+	cfg.MaxLength = 60
+
+	model, err := NewSummarizationModelWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	text := "In findings published Tuesday in Cornell University's arXiv by a team of scientists from the University of Montreal and a separate report published Wednesday in Nature Astronomy by a team from University College London (UCL), the presence of water vapour was confirmed in the atmosphere of K2-18b, a planet circling a star in the constellation Leo. This is the first such discovery in a planet in its star's habitable zone — not too hot and not too cold for liquid water to exist."
+
+	summaries, err := model.Summarize(text)
+	if err != nil {
+		t.Fatalf("Summarize error = %v", err)
+	}
+	if len(summaries) != 1 || summaries[0] == "" {
+		t.Fatalf("Expected one summary, got %v", summaries)
+	}
+
+	short, err := model.SummarizeWithOptions(text, SummarizeOptions{MinLength: 5, MaxLength: 15})
+	if err != nil {
+		t.Fatalf("SummarizeWithOptions error = %v", err)
+	}
+	if len(short) != 1 {
+		t.Fatalf("Expected one summary, got %v", short)
+	}
+	t.Logf("Default: %s", summaries[0])
+	t.Logf("Short: %s", short[0])
+	// 15 tokens never decode to more than 15 words
+	if words := len(strings.Fields(short[0])); words > 15 {
+		t.Errorf("Summary exceeds MaxLength: %d words", words)
+	}
+
+	if _, err := model.SummarizeWithOptions(text, SummarizeOptions{MinLength: 20, MaxLength: 10}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for MinLength > MaxLength, got %v", err)
+	}
+}
+
+func TestSummarizeLong(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	cfg := DefaultSummarizationConfig()
+	cfg.Model = SummarizationDistilBartCNN
+	model, err := NewSummarizationModelWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	paragraphs := []string{
+		"Alice: The release is scheduled for Friday. QA still has to sign off on the payment changes. Bob will run the load tests on Wednesday.",
+		"Bob: The load tests found a memory leak in the cache last week. It is fixed, but the fix needs another review before we ship.",
+		"Carol: Marketing wants the announcement ready by Thursday. The blog post draft is done and only needs the final numbers.",
+	}
+	// Thousands of tokens, far beyond the 1024 the model accepts
+	text := strings.Repeat(strings.Join(paragraphs, "\n\n")+"\n\n", 40)
+
+	long := DefaultLongSummaryConfig()
+	long.ChunkSize = 256
+	long.TargetLength = 60
+	long.Options = SummarizeOptions{MinLength: 10, MaxLength: 60}
+
+	summary, err := model.SummarizeLong(text, long)
+	if err != nil {
+		t.Fatalf("SummarizeLong error = %v", err)
+	}
+	t.Logf("Summary: %s", summary.Summary)
+	t.Logf("%d chunk summaries", len(summary.ChunkSummaries))
+
+	if summary.Summary == "" {
+		t.Error("SummarizeLong returned an empty summary")
+	}
+	if len(summary.ChunkSummaries) < 2 {
+		t.Errorf("Expected several chunk summaries, got %d", len(summary.ChunkSummaries))
+	}
+	// 60 tokens never decode to more than 60 words
+	if words := len(strings.Fields(summary.Summary)); words > long.TargetLength {
+		t.Errorf("Summary exceeds TargetLength: %d words", words)
+	}
+
+	long.ChunkSize = 0
+	if _, err := model.SummarizeLong(text, long); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for a zero chunk size, got %v", err)
+	}
+}
+
 func TestZeroShot(t *testing.T) {
 	if err := Init(); err != nil {
 		t.Fatalf("Failed to initialize library: %v", err)
@@ -248,6 +669,63 @@ func TestZeroShot(t *testing.T) {
 	}
 }
 
+func TestZeroShotOptions(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	model, err := NewZeroShotModel()
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	texts := []string{
+		"The new stadium will be paid for by raising property taxes.",
+		"The striker scored twice in the final.",
+	}
+	labels := []string{"politics", "economics", "sports", "cooking"}
+
+	opts := DefaultZeroShotOptions()
+	opts.Template = "This news article is about {}."
+	opts.MultiLabel = true
+	results, err := model.PredictBatchWithOptions(texts, labels, opts)
+	if err != nil {
+		t.Fatalf("PredictBatchWithOptions error = %v", err)
+	}
+	if len(results) != len(texts) {
+		t.Fatalf("Expected %d results, got %d", len(texts), len(results))
+	}
+	for i, scores := range results {
+		t.Logf("%q: %v", texts[i], scores)
+		if len(scores) != len(labels) {
+			t.Fatalf("Expected a score for each of the %d labels, got %v", len(labels), scores)
+		}
+		for j := 1; j < len(scores); j++ {
+			if scores[j].Score > scores[j-1].Score {
+				t.Errorf("Labels are not ranked: %v", scores)
+			}
+		}
+	}
+	if results[1][0].Text != "sports" {
+		t.Errorf("Expected sports first for %q, got %v", texts[1], results[1])
+	}
+
+	opts.MultiLabel = false
+	best, err := model.PredictWithOptions(texts[1], labels, opts)
+	if err != nil {
+		t.Fatalf("PredictWithOptions error = %v", err)
+	}
+	if len(best) != 1 || best[0].Text != "sports" {
+		t.Errorf("Expected the single label sports, got %v", best)
+	}
+
+	opts.Template = "This news article is about sports."
+	if _, err := model.PredictWithOptions(texts[1], labels, opts); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for a template without placeholder, got %v", err)
+	}
+}
+
 func TestTranslation(t *testing.T) {
 	if err := Init(); err != nil {
 		t.Fatalf("Failed to initialize library: %v", err)
@@ -290,30 +768,337 @@ func TestTranslation(t *testing.T) {
 	t.Logf("Translated (ES): %s", translated)
 }
 
-func TestTextGeneration(t *testing.T) {
+func TestTranslationBuilder(t *testing.T) {
 	if err := Init(); err != nil {
 		t.Fatalf("Failed to initialize library: %v", err)
 	}
 
-	model, err := NewTextGenerationModel()
+	model, err := NewTranslationModelBuilder().
+		WithModelType(ModelTypeMarian).
+		WithSourceLanguages("German").
+		WithTargetLanguages("en").
+		WithDevice(DeviceCPU).
+		CreateModel()
 	if err != nil {
 		t.Fatalf("Failed to create model: %v", err)
 	}
 	defer model.Close()
 
-	prompt := "The dog"
-
-	// First run might download GPT2 (~500MB)
-	generated, err := model.Generate(prompt, "")
+	translated, err := model.Translate("Guten Morgen, wie geht es dir?", "de", "en")
 	if err != nil {
-		t.Fatalf("Generate error = %v", err)
+		t.Fatalf("Translate error = %v", err)
+	}
+	t.Logf("Translated (EN): %s", translated)
+	if !contains(translated, "morning") {
+		t.Errorf("Expected an English translation, got %q", translated)
 	}
 
-	t.Logf("Prompt: %s", prompt)
-	t.Logf("Generated: %s", generated)
+	if _, err := model.Translate("Hello", "en", "fr"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for an unsupported pair, got %v", err)
+	}
+	if _, err := model.Translate("Hallo", "de", "klingon"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for an unknown language, got %v", err)
+	}
 
-	if len(generated) == 0 {
-		t.Error("Generation returned empty string")
+	_, err = NewTranslationModelBuilder().
+		WithModelType(ModelTypeBert).
+		WithSourceLanguages("en").
+		WithTargetLanguages("fr").
+		CreateModel()
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for a non-translation model type, got %v", err)
+	}
+}
+
+func TestSentenceEmbeddings(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	cfg := DefaultSentenceEmbeddingsConfig()
+	cfg.Model = SentenceEmbeddingsAllMiniLmL6V2
+	model, err := NewSentenceEmbeddingsModel(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	texts := []string{
+		"A man is playing a guitar.",
+		"Someone plays an instrument.",
+		"The stock market fell sharply today.",
+	}
+	embeddings, err := model.Encode(texts)
+	if err != nil {
+		t.Fatalf("Encode error = %v", err)
+	}
+	if len(embeddings) != len(texts) {
+		t.Fatalf("Expected %d embeddings, got %d", len(texts), len(embeddings))
+	}
+
+	dot := func(a, b []float32) float32 {
+		var sum float32
+		for i := range a {
+			sum += a[i] * b[i]
+		}
+		return sum
+	}
+	for i, e := range embeddings {
+		if len(e) != 384 {
+			t.Errorf("Embedding %d has %d dimensions, expected 384", i, len(e))
+		}
+		if norm := dot(e, e); norm < 0.99 || norm > 1.01 {
+			t.Errorf("Embedding %d is not normalized, squared norm %f", i, norm)
+		}
+	}
+	related, unrelated := dot(embeddings[0], embeddings[1]), dot(embeddings[0], embeddings[2])
+	t.Logf("Similarity related: %.3f, unrelated: %.3f", related, unrelated)
+	if related <= unrelated {
+		t.Errorf("Expected related sentences to be closer (%.3f <= %.3f)", related, unrelated)
+	}
+
+	cfg.Pooling = PoolingCLS
+	cfg.Normalize = false
+	cls, err := NewSentenceEmbeddingsModel(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create model with CLS pooling: %v", err)
+	}
+	defer cls.Close()
+	clsEmbeddings, err := cls.Encode(texts[:1])
+	if err != nil {
+		t.Fatalf("Encode error = %v", err)
+	}
+	if dot(clsEmbeddings[0], embeddings[0]) == dot(embeddings[0], embeddings[0]) {
+		t.Error("Expected CLS pooling to change the embedding")
+	}
+
+	if _, err := NewSentenceEmbeddingsModelFromDir(t.TempDir(), cfg); !errors.Is(err, ErrModelLoad) {
+		t.Errorf("Expected ErrModelLoad for a directory without a model, got %v", err)
+	}
+}
+
+func TestKeywordExtraction(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	cfg := DefaultKeywordExtractionConfig()
+	cfg.MaxNgram = 2
+	cfg.Scorer = KeywordScorerMMR
+	model, err := NewKeywordExtractionModel(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	text := "Rust is a multi-paradigm programming language focused on performance and safety. " +
+		"Rust enforces memory safety without a garbage collector."
+	keywords, err := model.Extract(text)
+	if err != nil {
+		t.Fatalf("Extract error = %v", err)
+	}
+	if len(keywords) == 0 || len(keywords) > cfg.MaxKeywords {
+		t.Fatalf("Expected 1 to %d keywords, got %d", cfg.MaxKeywords, len(keywords))
+	}
+
+	runes := []rune(text)
+	for _, k := range keywords {
+		t.Logf("%s (%.3f) at %v", k.Text, k.Score, k.Offsets)
+		if len(k.Offsets) == 0 {
+			t.Errorf("Keyword %q has no offsets", k.Text)
+		}
+		for _, o := range k.Offsets {
+			if o.Begin < 0 || o.End > len(runes) || o.Begin >= o.End {
+				t.Errorf("Keyword %q has invalid offset %v", k.Text, o)
+			} else if !strings.EqualFold(string(runes[o.Begin:o.End]), k.Text) {
+				t.Errorf("Offset %v of keyword %q points at %q", o, k.Text, string(runes[o.Begin:o.End]))
+			}
+		}
+	}
+
+	bad := DefaultKeywordExtractionConfig()
+	bad.MinNgram, bad.MaxNgram = 3, 1
+	if _, err := NewKeywordExtractionModel(bad); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for an empty n-gram range, got %v", err)
+	}
+}
+
+func TestFillMask(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	model, err := NewMaskedLanguageModel()
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	masks, err := model.FillMask("The capital of France is [MASK].")
+	if err != nil {
+		t.Fatalf("FillMask error = %v", err)
+	}
+	if len(masks) != 1 || len(masks[0]) != DefaultFillMaskTopK {
+		t.Fatalf("Expected 1 mask with %d candidates, got %v", DefaultFillMaskTopK, masks)
+	}
+	t.Logf("Candidates: %v", masks[0])
+	if masks[0][0].Token != "paris" {
+		t.Errorf("Expected 'paris' first, got %q", masks[0][0].Token)
+	}
+	for i := 1; i < len(masks[0]); i++ {
+		if masks[0][i].Score > masks[0][i-1].Score {
+			t.Errorf("Candidates are not sorted by score: %v", masks[0])
+		}
+	}
+
+	results, err := model.FillMaskBatch([]string{"I [MASK] to the [MASK] yesterday.", "No mask here."}, 3)
+	if err != nil {
+		t.Fatalf("FillMaskBatch error = %v", err)
+	}
+	if len(results) != 2 || len(results[0]) != 2 || len(results[1]) != 0 {
+		t.Fatalf("Expected 2 masks then none, got %v", results)
+	}
+	for _, candidates := range results[0] {
+		if len(candidates) != 3 {
+			t.Errorf("Expected 3 candidates, got %v", candidates)
+		}
+	}
+
+	if _, err := model.FillMaskBatch([]string{"[MASK]"}, 0); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for topK 0, got %v", err)
+	}
+}
+
+func TestSequenceClassification(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	model, err := NewSequenceClassificationModel()
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	labels, err := model.Predict("This movie was absolutely wonderful.")
+	if err != nil {
+		t.Fatalf("Predict error = %v", err)
+	}
+	t.Logf("Labels: %v", labels)
+	if len(labels) != 2 {
+		t.Fatalf("Expected the 2 labels of the model, got %v", labels)
+	}
+	if labels[0].Text != "POSITIVE" {
+		t.Errorf("Expected POSITIVE first, got %q", labels[0].Text)
+	}
+	if sum := labels[0].Score + labels[1].Score; sum < 0.99 || sum > 1.01 {
+		t.Errorf("Expected softmax scores to sum to 1, got %f", sum)
+	}
+
+	batch, err := model.PredictMultiLabelBatch([]string{"Great!", "Terrible."})
+	if err != nil {
+		t.Fatalf("PredictMultiLabelBatch error = %v", err)
+	}
+	if len(batch) != 2 || len(batch[0]) != 2 || len(batch[1]) != 2 {
+		t.Fatalf("Expected 2 labels per text, got %v", batch)
+	}
+	for _, l := range batch[1] {
+		if l.Score < 0 || l.Score > 1 {
+			t.Errorf("Sigmoid score out of range: %v", l)
+		}
+	}
+	if batch[1][0].Text != "NEGATIVE" {
+		t.Errorf("Expected NEGATIVE first for the second text, got %v", batch[1])
+	}
+}
+
+func TestTokenClassification(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	cfg := DefaultTokenClassificationConfig()
+	cfg.Aggregation = AggregationMax
+	cfg.IgnoreOutside = true
+	model, err := NewTokenClassificationModel(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	text := "My name is Amélie. I live in Paris."
+	tokens, err := model.Predict(text)
+	if err != nil {
+		t.Fatalf("Predict error = %v", err)
+	}
+	t.Logf("Tokens: %v", tokens)
+	if len(tokens) == 0 {
+		t.Fatal("Expected at least one labeled token")
+	}
+	runes := []rune(text)
+	var paris *LabeledToken
+	for i, tok := range tokens {
+		if tok.Label == "O" {
+			t.Errorf("Expected no O label with IgnoreOutside, got %v", tok)
+		}
+		if tok.Offset.Begin < 0 || tok.Offset.End > len(runes) || tok.Offset.Begin >= tok.Offset.End {
+			t.Errorf("Invalid offset for %v", tok)
+		}
+		if tok.Text == "Paris" {
+			paris = &tokens[i]
+		}
+	}
+	if paris == nil {
+		t.Fatalf("Expected Paris to be labeled, got %v", tokens)
+	}
+	if paris.Label != "I-LOC" {
+		t.Errorf("Expected I-LOC for Paris, got %q", paris.Label)
+	}
+	if paris.Sentence != 1 {
+		t.Errorf("Expected Paris in sentence 1, got %d", paris.Sentence)
+	}
+
+	all, err := NewTokenClassificationModel(DefaultTokenClassificationConfig())
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer all.Close()
+	batch, err := all.PredictBatch([]string{"Hello world", text})
+	if err != nil {
+		t.Fatalf("PredictBatch error = %v", err)
+	}
+	if len(batch) != 2 || len(batch[0]) == 0 {
+		t.Fatalf("Expected tokens for both texts, got %v", batch)
+	}
+	if batch[0][0].Label != "O" {
+		t.Errorf("Expected O labels to be kept, got %v", batch[0])
+	}
+}
+
+func TestTextGeneration(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	model, err := NewTextGenerationModel()
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	prompt := "The dog"
+
+	// First run might download GPT2 (~500MB)
+	generated, err := model.Generate(prompt, "")
+	if err != nil {
+		t.Fatalf("Generate error = %v", err)
+	}
+
+	t.Logf("Prompt: %s", prompt)
+	t.Logf("Generated: %s", generated)
+
+	if len(generated) == 0 {
+		t.Error("Generation returned empty string")
 	}
 
 	if !contains(generated, "dog") {
@@ -322,6 +1107,204 @@ func TestTextGeneration(t *testing.T) {
 	}
 }
 
+func TestTextGenerationOptions(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	invalid := DefaultGenerateOptions()
+	invalid.DoSample = false
+	invalid.NumBeams = 1
+	invalid.NumReturnSequences = 2
+	if _, err := NewTextGenerationModelWithOptions(invalid); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for NumReturnSequences > NumBeams, got %v", err)
+	}
+
+	greedy := DefaultGenerateOptions()
+	greedy.DoSample = false
+	greedy.NumBeams = 1
+	greedy.MaxLength = 24
+
+	model, err := NewTextGenerationModelWithOptions(greedy)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	first, err := model.Generate("The dog", "")
+	if err != nil {
+		t.Fatalf("Generate error = %v", err)
+	}
+	second, err := model.Generate("The dog", "")
+	if err != nil {
+		t.Fatalf("Generate error = %v", err)
+	}
+	if first != second {
+		t.Errorf("Greedy generation is not deterministic: %q != %q", first, second)
+	}
+
+	sampled := DefaultGenerateOptions()
+	sampled.NumReturnSequences = 3
+	sampled.MaxLength = 24
+	sampled.Seed = 42
+
+	sampler, err := NewTextGenerationModelWithOptions(sampled)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer sampler.Close()
+
+	sequences, err := sampler.GenerateSequences("The dog", "")
+	if err != nil {
+		t.Fatalf("GenerateSequences error = %v", err)
+	}
+	if len(sequences) != 3 {
+		t.Fatalf("Expected 3 sequences, got %d", len(sequences))
+	}
+	again, err := sampler.GenerateSequences("The dog", "")
+	if err != nil {
+		t.Fatalf("GenerateSequences error = %v", err)
+	}
+	for i := range sequences {
+		if sequences[i] != again[i] {
+			t.Errorf("Seeded sampling is not reproducible: %q != %q", sequences[i], again[i])
+		}
+	}
+}
+
+func TestTextGenerationStream(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	model, err := NewTextGenerationModel()
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	opts := DefaultStreamOptions()
+	opts.DoSample = false
+	opts.MaxLength = 24
+
+	var pieces []string
+	for piece, err := range model.GenerateStream(context.Background(), "The dog", opts) {
+		if err != nil {
+			t.Fatalf("GenerateStream error = %v", err)
+		}
+		pieces = append(pieces, piece)
+	}
+	t.Logf("Pieces: %q", pieces)
+	if len(pieces) < 2 {
+		t.Fatalf("Expected several pieces, got %q", pieces)
+	}
+
+	for _, err := range model.GenerateStream(context.Background(), "The dog", DefaultGenerateOptions()) {
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput with beam search, got %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	count := 0
+	var streamErr error
+	for _, err := range model.GenerateStream(ctx, "The dog", opts) {
+		if err != nil {
+			streamErr = err
+			break
+		}
+		count++
+		cancel()
+	}
+	if count != 1 || !errors.Is(streamErr, context.Canceled) {
+		t.Errorf("Expected one piece then context.Canceled, got %d pieces and %v", count, streamErr)
+	}
+}
+
+func TestConversation(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	opts := DefaultConversationOptions()
+	opts.Seed = 42
+	model, err := NewConversationModelWithOptions(opts)
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+	defer model.Close()
+
+	mgr, err := NewConversationManager()
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	defer mgr.Close()
+
+	first, err := mgr.Create("Hello, how are you?")
+	if err != nil {
+		t.Fatalf("Create error = %v", err)
+	}
+	second, err := mgr.Create("What is your favourite movie?")
+	if err != nil {
+		t.Fatalf("Create error = %v", err)
+	}
+	idle, err := mgr.CreateEmpty()
+	if err != nil {
+		t.Fatalf("CreateEmpty error = %v", err)
+	}
+
+	responses, err := model.GenerateResponses(mgr)
+	if err != nil {
+		t.Fatalf("GenerateResponses error = %v", err)
+	}
+	t.Logf("Responses: %v", responses)
+	if len(responses) != 2 {
+		t.Fatalf("Expected responses for the 2 active conversations, got %v", responses)
+	}
+	if _, ok := responses[idle]; ok {
+		t.Errorf("Expected no response for the empty conversation")
+	}
+
+	if err := mgr.AddUserInput(first, "Do you like music?"); err != nil {
+		t.Fatalf("AddUserInput error = %v", err)
+	}
+	if err := mgr.AddUserInput(first, "Which band?"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for a second pending input, got %v", err)
+	}
+	if _, err := model.GenerateResponses(mgr); err != nil {
+		t.Fatalf("GenerateResponses error = %v", err)
+	}
+
+	conv, err := mgr.Get(first)
+	if err != nil {
+		t.Fatalf("Get error = %v", err)
+	}
+	if len(conv.Turns) != 2 || conv.Turns[1].User != "Do you like music?" || conv.PendingInput != "" {
+		t.Errorf("Unexpected history: %+v", conv)
+	}
+	if err := mgr.Trim(first, 1); err != nil {
+		t.Fatalf("Trim error = %v", err)
+	}
+	if conv, _ = mgr.Get(first); len(conv.Turns) != 1 {
+		t.Errorf("Expected 1 turn after Trim, got %+v", conv)
+	}
+
+	if err := mgr.Remove(second); err != nil {
+		t.Fatalf("Remove error = %v", err)
+	}
+	ids, err := mgr.IDs()
+	if err != nil {
+		t.Fatalf("IDs error = %v", err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("Expected 2 conversations left, got %v", ids)
+	}
+	if _, err := mgr.Get(second); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for a removed conversation, got %v", err)
+	}
+}
+
 func TestSentimentAnalysisFromFiles(t *testing.T) {
 	if err := Init(); err != nil {
 		t.Fatalf("Failed to initialize library: %v", err)
@@ -377,8 +1360,8 @@ func TestNERFromFiles(t *testing.T) {
 	if err := Init(); err != nil {
 		t.Fatalf("Failed to initialize library: %v", err)
 	}
-	// Reuse sentiment model artifacts
-	repoID := "distilbert-base-uncased-finetuned-sst-2-english"
+	// Same checkpoint as the default NER pipeline, loaded from local files
+	repoID := "dbmdz/bert-large-cased-finetuned-conll03-english"
 	// Use default cache to avoid repeated downloads and potential crashes
 	cacheDir := ""
 
@@ -387,8 +1370,7 @@ func TestNERFromFiles(t *testing.T) {
 		t.Fatalf("Failed to download artifacts: %v", err)
 	}
 
-	// Specify DistilBert because SST-2 is a DistilBert model.
-	model, err := NewNERModelFromFiles(modelPath, configPath, vocabPath, mergesPath, ModelTypeDistilBert)
+	model, err := NewNERModelFromFiles(modelPath, configPath, vocabPath, mergesPath, ModelTypeBert)
 	if err != nil {
 		t.Fatalf("Failed to create NER model from files: %v", err)
 	}
@@ -403,6 +1385,167 @@ func TestNERFromFiles(t *testing.T) {
 	for _, e := range entities {
 		t.Logf("Entity: %+v", e)
 	}
+
+	foundParis := false
+	for _, e := range entities {
+		if e.Word == "Paris" {
+			foundParis = true
+		}
+	}
+	if !foundParis {
+		t.Error("Did not find entity Paris")
+	}
+}
+
+func TestFromFilesErrors(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	t.Run("missing files", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := NewSentimentModelFromFiles(dir+"/rust_model.ot", dir+"/config.json", dir+"/vocab.txt", "", ModelTypeDistilBert)
+		if !errors.Is(err, ErrModelLoad) {
+			t.Fatalf("Expected a model load error for missing files, got %v", err)
+		}
+		t.Logf("Error: %v", err)
+	})
+
+	t.Run("incompatible model type", func(t *testing.T) {
+		repoID := "distilbert-base-uncased-finetuned-sst-2-english"
+		modelPath, configPath, vocabPath, mergesPath, err := DownloadArtifacts(repoID, "")
+		if err != nil {
+			t.Fatalf("Failed to download artifacts: %v", err)
+		}
+
+		// config.json declares a distilbert model
+		_, err = NewSentimentModelFromFiles(modelPath, configPath, vocabPath, mergesPath, ModelTypeBert)
+		if !errors.Is(err, ErrModelLoad) {
+			t.Fatalf("Expected a model load error for an incompatible model type, got %v", err)
+		}
+		t.Logf("Error: %v", err)
+	})
+}
+
+func TestTokenizer(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	_, _, vocabPath, mergesPath, err := DownloadArtifacts("distilbert-base-uncased-finetuned-sst-2-english", "")
+	if err != nil {
+		t.Fatalf("Failed to download artifacts: %v", err)
+	}
+
+	cfg := DefaultTokenizerConfig()
+	cfg.MaxLength = 8
+	tok, err := NewTokenizerFromFiles(vocabPath, mergesPath, ModelTypeDistilBert, cfg)
+	if err != nil {
+		t.Fatalf("Failed to create tokenizer: %v", err)
+	}
+	defer tok.Close()
+
+	enc, err := tok.Encode("Hello world")
+	if err != nil {
+		t.Fatalf("Encode error = %v", err)
+	}
+	t.Logf("Encoding: %+v", enc)
+	if want := []string{"[CLS]", "hello", "world", "[SEP]"}; strings.Join(enc.Tokens, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected tokens %v, got %v", want, enc.Tokens)
+	}
+	if !enc.SpecialTokensMask[0] || enc.SpecialTokensMask[1] || !enc.SpecialTokensMask[3] {
+		t.Errorf("Unexpected special tokens mask %v", enc.SpecialTokensMask)
+	}
+	if enc.Offsets[2] != (Offset{Begin: 6, End: 11}) {
+		t.Errorf("Expected world at 6..11, got %v", enc.Offsets[2])
+	}
+
+	text, err := tok.Decode(enc.IDs)
+	if err != nil {
+		t.Fatalf("Decode error = %v", err)
+	}
+	if text != "hello world" {
+		t.Errorf("Expected decoded text %q, got %q", "hello world", text)
+	}
+
+	subwords, err := tok.Encode("tokenization")
+	if err != nil {
+		t.Fatalf("Encode error = %v", err)
+	}
+	if !slices.ContainsFunc(subwords.Tokens, func(token string) bool { return strings.HasPrefix(token, "##") }) {
+		t.Errorf("Expected a ## continuation token, got %v", subwords.Tokens)
+	}
+
+	long, err := tok.Encode("one two three four five six seven eight nine ten")
+	if err != nil {
+		t.Fatalf("Encode error = %v", err)
+	}
+	if len(long.IDs) != cfg.MaxLength || long.NumTruncated != 4 {
+		t.Errorf("Expected %d tokens and 4 truncated, got %d and %d", cfg.MaxLength, len(long.IDs), long.NumTruncated)
+	}
+
+	pair, err := tok.EncodePair("Who?", "Me.")
+	if err != nil {
+		t.Fatalf("EncodePair error = %v", err)
+	}
+	if pair.TypeIDs[0] != 0 || pair.TypeIDs[len(pair.TypeIDs)-1] != 1 {
+		t.Errorf("Expected type ids from 0 to 1, got %v", pair.TypeIDs)
+	}
+
+	if _, err := NewTokenizerFromFiles(vocabPath, "", ModelTypeGPT2, cfg); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput without merges for GPT-2, got %v", err)
+	}
+}
+
+func TestTokenizerCasingFallback(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+	t.Cleanup(func() {
+		initMu.Lock()
+		defer initMu.Unlock()
+		setDefaultCasing(CasingAuto)
+		initCasing = CasingAuto
+	})
+
+	_, _, vocabPath, _, err := DownloadArtifacts("distilbert-base-uncased-finetuned-sst-2-english", "")
+	if err != nil {
+		t.Fatalf("Failed to download artifacts: %v", err)
+	}
+	// A BERT vocabulary alone does not tell whether the checkpoint is cased
+	data, err := os.ReadFile(vocabPath)
+	if err != nil {
+		t.Fatalf("ReadFile error = %v", err)
+	}
+	vocab := filepath.Join(t.TempDir(), "vocab.txt")
+	if err := os.WriteFile(vocab, data, 0o644); err != nil {
+		t.Fatalf("WriteFile error = %v", err)
+	}
+
+	tokens := func() []string {
+		t.Helper()
+		tok, err := NewTokenizerFromFiles(vocab, "", ModelTypeBert, DefaultTokenizerConfig())
+		if err != nil {
+			t.Fatalf("Failed to create tokenizer: %v", err)
+		}
+		defer tok.Close()
+		enc, err := tok.Encode("Hello")
+		if err != nil {
+			t.Fatalf("Encode error = %v", err)
+		}
+		return enc.Tokens
+	}
+
+	// Assumed cased: the uncased vocabulary has no "Hello"
+	if got := tokens(); slices.Contains(got, "hello") {
+		t.Errorf("Expected the input kept cased, got %v", got)
+	}
+	if err := InitWithOptions(InitOptions{Casing: CasingUncased}); err != nil {
+		t.Fatalf("InitWithOptions error = %v", err)
+	}
+	if got := tokens(); !slices.Contains(got, "hello") {
+		t.Errorf("Expected the input lower cased, got %v", got)
+	}
 }
 
 func TestQAFromFiles(t *testing.T) {
@@ -500,10 +1643,41 @@ func TestZeroShotFromFiles(t *testing.T) {
 }
 
 func TestTranslationFromFiles(t *testing.T) {
-	// Skipping Translation but now I could potentially test it if I had a Marian model.
-	// Helsinki-NLP/opus-mt-en-fr is Marian. It definitely has .ot (auto-converted often) or is supported.
-	// But to avoid risk of another large download/crash, I will keep skip.
-	t.Skip("Skipping Translation test: M2M100/Marian models are large")
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+	repoID := "Helsinki-NLP/opus-mt-en-de"
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("UserHomeDir error = %v", err)
+	}
+	cacheDir := filepath.Join(home, ".cache", "rustbert")
+
+	modelPath, configPath, vocabPath, _, err := DownloadArtifacts(repoID, cacheDir)
+	if err != nil {
+		t.Fatalf("Failed to download artifacts: %v", err)
+	}
+	// Marian tokenizes with the SentencePiece model of the source language, passed as
+	// mergesPath
+	spmPath, err := hub.New(repoID).WithCacheDir(cacheDir).DownloadFile("source.spm")
+	if err != nil {
+		t.Fatalf("Failed to download source.spm: %v", err)
+	}
+
+	model, err := NewTranslationModelFromFiles(modelPath, configPath, vocabPath, spmPath, ModelTypeMarian)
+	if err != nil {
+		t.Fatalf("Failed to create Translation model from files: %v", err)
+	}
+	defer model.Close()
+
+	translated, err := model.Translate("Hello, how are you?", "", "de")
+	if err != nil {
+		t.Fatalf("Translate error = %v", err)
+	}
+	t.Logf("Translated (DE): %s", translated)
+	if !contains(translated, "wie") {
+		t.Errorf("Expected a German translation, got %q", translated)
+	}
 }
 
 func TestTextGenerationFromFiles(t *testing.T) {
@@ -538,3 +1712,124 @@ func TestTextGenerationFromFiles(t *testing.T) {
 func contains(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
 }
+
+func TestDevices(t *testing.T) {
+	if err := Init(); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+
+	devices, err := AvailableDevices()
+	if err != nil {
+		t.Fatalf("AvailableDevices error = %v", err)
+	}
+	t.Logf("Available devices: %v", devices)
+	if len(devices) == 0 || devices[0] != DeviceCPU {
+		t.Fatalf("Expected the CPU first, got %v", devices)
+	}
+
+	model, err := NewSentimentModelOnDevice(DeviceCPU)
+	if err != nil {
+		t.Fatalf("Failed to create model on the CPU: %v", err)
+	}
+	defer model.Close()
+	if result, err := model.Predict("I love this library!"); err != nil || result.Label != "POSITIVE" {
+		t.Errorf("Predict = %+v, %v", result, err)
+	}
+
+	missing := DeviceCUDA(len(devices))
+	if _, err := NewSentimentModelOnDevice(missing); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for %v, got %v", missing, err)
+	}
+
+	for device, want := range map[Device]string{DeviceAuto: "auto", DeviceCPU: "cpu", DeviceMPS: "mps", DeviceCUDA(1): "cuda:1"} {
+		if got := device.String(); got != want {
+			t.Errorf("Device(%d).String() = %q, want %q", int(device), got, want)
+		}
+	}
+}
+
+func TestThreads(t *testing.T) {
+	if err := InitWithOptions(InitOptions{Threads: ThreadConfig{NumThreads: 2}}); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+	initial := defaultNumThreads
+	defer SetNumThreads(int(initial))
+
+	threads, err := Threads()
+	if err != nil {
+		t.Fatalf("Threads error = %v", err)
+	}
+	if threads.NumThreads != 2 {
+		t.Errorf("NumThreads = %d, want 2", threads.NumThreads)
+	}
+	t.Logf("Threads: %+v", threads)
+
+	model, err := NewSentimentModel()
+	if err != nil {
+		t.Fatalf("Failed to create sentiment model: %v", err)
+	}
+	defer model.Close()
+	if err := model.SetNumThreads(1); err != nil {
+		t.Fatalf("SetNumThreads error = %v", err)
+	}
+	if result, err := model.Predict("I love this library!"); err != nil || result.Label != "POSITIVE" {
+		t.Errorf("Predict = %+v, %v", result, err)
+	}
+
+	if err := SetNumThreads(0); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for 0 threads, got %v", err)
+	}
+	if err := model.SetNumThreads(-1); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for -1 threads, got %v", err)
+	}
+}
+
+func TestInitWithOptions(t *testing.T) {
+	if err := InitWithOptions(InitOptions{Device: DeviceCPU}); err != nil {
+		t.Fatalf("Failed to initialize library: %v", err)
+	}
+	defer func() {
+		initMu.Lock()
+		defer initMu.Unlock()
+		setDefaultDevice(DeviceAuto)
+		initDevice = DeviceAuto
+	}()
+
+	model, err := NewSentimentModel()
+	if err != nil {
+		t.Fatalf("Failed to create model on the default device: %v", err)
+	}
+	defer model.Close()
+	if result, err := model.Predict("I love this library!"); err != nil || result.Label != "POSITIVE" {
+		t.Errorf("Predict = %+v, %v", result, err)
+	}
+
+	devices, err := AvailableDevices()
+	if err != nil {
+		t.Fatalf("AvailableDevices error = %v", err)
+	}
+	if err := setDefaultDevice(DeviceCUDA(len(devices))); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for a missing device, got %v", err)
+	}
+
+	// Options conflicting with those in use are rejected, zero ones accept them.
+	if err := InitWithOptions(InitOptions{Device: DeviceCPU}); err != nil {
+		t.Errorf("Repeated InitWithOptions error = %v", err)
+	}
+	if err := Init(); err != nil {
+		t.Errorf("Init after InitWithOptions error = %v", err)
+	}
+	for _, opts := range []InitOptions{
+		{Device: DeviceMPS},
+		{LibDir: t.TempDir()},
+		{ExtractDir: t.TempDir()},
+		{SkipEmbedded: true},
+	} {
+		if err := InitWithOptions(opts); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for %+v, got %v", opts, err)
+		}
+	}
+	if err := InitWithOptions(InitOptions{Threads: ThreadConfig{NumThreads: -1}}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for negative threads, got %v", err)
+	}
+}
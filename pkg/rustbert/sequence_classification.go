@@ -0,0 +1,247 @@
+package rustbert
+
+/*
+#include <stdbool.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef struct CancelToken CancelToken;
+
+typedef struct {
+    void* model;
+} SequenceClassificationModelWrapper;
+
+typedef struct {
+    char* label;
+    int64_t id;
+    float score;
+} ClassificationLabel;
+
+typedef struct {
+    ClassificationLabel* labels;
+    size_t count;
+} ClassificationResult;
+
+typedef struct {
+    ClassificationResult* results;
+    size_t count;
+} ClassificationBatchResult;
+
+typedef SequenceClassificationModelWrapper* (*new_sequence_classification_model_t)(int);
+typedef SequenceClassificationModelWrapper* (*new_sequence_classification_model_from_files_t)(const char*, const char*, const char*, const char*, int, int);
+typedef ClassificationBatchResult* (*classify_batch_t)(SequenceClassificationModelWrapper*, const char**, size_t, bool, CancelToken*);
+typedef void (*free_sequence_classification_model_t)(SequenceClassificationModelWrapper*);
+typedef void (*free_classification_batch_result_t)(ClassificationBatchResult*);
+
+SequenceClassificationModelWrapper* call_new_sequence_classification_model(void* f, int device) {
+    return ((new_sequence_classification_model_t)f)(device);
+}
+
+void* call_new_sequence_classification_model_from_files(void* f, const char* m, const char* c, const char* v, const char* me, int t, int device) {
+    return ((new_sequence_classification_model_from_files_t)f)(m, c, v, me, t, device);
+}
+
+ClassificationBatchResult* call_classify_batch(
+    void* f,
+    SequenceClassificationModelWrapper* w,
+    const char** texts,
+    size_t count,
+    bool multi_label,
+    CancelToken* cancel
+) {
+    return ((classify_batch_t)f)(w, texts, count, multi_label, cancel);
+}
+
+void call_free_sequence_classification_model(void* f, SequenceClassificationModelWrapper* w) {
+    ((free_sequence_classification_model_t)f)(w);
+}
+
+void call_free_classification_batch_result(void* f, ClassificationBatchResult* r) {
+    ((free_classification_batch_result_t)f)(r);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"runtime"
+	"unsafe"
+)
+
+var (
+	fnNewSequenceClassificationModel          unsafe.Pointer
+	fnNewSequenceClassificationModelFromFiles unsafe.Pointer
+	fnClassifyBatch                           unsafe.Pointer
+	fnFreeSequenceClassificationModel         unsafe.Pointer
+	fnFreeClassificationBatchResult           unsafe.Pointer
+)
+
+// ClassificationLabel is the score of one label of a SequenceClassificationModel.
+type ClassificationLabel struct {
+	// Text is the label name from the id2label map of the model config.
+	Text string
+	// ID is the index of the label in the model output.
+	ID    int64
+	Score float64
+}
+
+// SequenceClassificationModel classifies texts with any fine-tuned sequence
+// classification checkpoint (topic, toxicity, intent...), reporting the score of every
+// label the model was trained with.
+type SequenceClassificationModel struct {
+	handle modelHandle[C.SequenceClassificationModelWrapper]
+}
+
+// NewSequenceClassificationModel creates a SequenceClassificationModel with rust-bert's
+// default checkpoint, DistilBERT fine-tuned on SST-2 (labels NEGATIVE and POSITIVE).
+func NewSequenceClassificationModel() (*SequenceClassificationModel, error) {
+	return NewSequenceClassificationModelOnDevice(DeviceAuto)
+}
+
+// NewSequenceClassificationModelOnDevice is like NewSequenceClassificationModel but
+// loads the model on device.
+func NewSequenceClassificationModelOnDevice(device Device) (*SequenceClassificationModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_sequence_classification_model(fnNewSequenceClassificationModel, C.int(device))
+	if ptr == nil {
+		return nil, lastError("NewSequenceClassificationModel")
+	}
+	return &SequenceClassificationModel{handle: modelHandle[C.SequenceClassificationModelWrapper]{ptr: ptr}}, nil
+}
+
+// NewSequenceClassificationModelFromFiles creates a SequenceClassificationModel using
+// local files. Label names are read from the id2label map of config.json.
+// See NewSentimentModelFromFiles for the expected files and error conditions.
+func NewSequenceClassificationModelFromFiles(modelPath, configPath, vocabPath, mergesPath string, modelType int) (*SequenceClassificationModel, error) {
+	return NewSequenceClassificationModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath, modelType, DeviceAuto)
+}
+
+// NewSequenceClassificationModelFromFilesOnDevice is like
+// NewSequenceClassificationModelFromFiles but loads the model on device.
+func NewSequenceClassificationModelFromFilesOnDevice(modelPath, configPath, vocabPath, mergesPath string, modelType int, device Device) (*SequenceClassificationModel, error) {
+	ptr, err := callNewModelFromFiles("NewSequenceClassificationModelFromFiles", fnNewSequenceClassificationModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return C.call_new_sequence_classification_model_from_files(fn, m, c, v, me, t, C.int(device))
+	}, modelPath, configPath, vocabPath, mergesPath, modelType)
+	if err != nil {
+		return nil, err
+	}
+	return &SequenceClassificationModel{handle: modelHandle[C.SequenceClassificationModelWrapper]{ptr: (*C.SequenceClassificationModelWrapper)(ptr)}}, nil
+}
+
+// Predict returns every label of the model for text, best first. Scores are a softmax
+// over the labels and sum to 1, for models trained on mutually exclusive classes.
+func (m *SequenceClassificationModel) Predict(text string) ([]ClassificationLabel, error) {
+	return m.PredictContext(context.Background(), text)
+}
+
+// PredictContext is like Predict but returns ctx.Err() as soon as ctx is done.
+func (m *SequenceClassificationModel) PredictContext(ctx context.Context, text string) ([]ClassificationLabel, error) {
+	return first(m.classify(ctx, []string{text}, false))
+}
+
+// PredictBatch is like Predict for several texts in a single forward pass. The labels
+// of texts[i] are returned at index i.
+func (m *SequenceClassificationModel) PredictBatch(texts []string) ([][]ClassificationLabel, error) {
+	return m.PredictBatchContext(context.Background(), texts)
+}
+
+// PredictBatchContext is like PredictBatch but stops once ctx is done, returning ctx.Err().
+func (m *SequenceClassificationModel) PredictBatchContext(ctx context.Context, texts []string) ([][]ClassificationLabel, error) {
+	return m.classify(ctx, texts, false)
+}
+
+// PredictMultiLabel returns every label of the model for text, best first, each scored
+// independently with a sigmoid, for multi-label models where several labels can apply.
+func (m *SequenceClassificationModel) PredictMultiLabel(text string) ([]ClassificationLabel, error) {
+	return m.PredictMultiLabelContext(context.Background(), text)
+}
+
+// PredictMultiLabelContext is like PredictMultiLabel but returns ctx.Err() as soon as
+// ctx is done.
+func (m *SequenceClassificationModel) PredictMultiLabelContext(ctx context.Context, text string) ([]ClassificationLabel, error) {
+	return first(m.classify(ctx, []string{text}, true))
+}
+
+// PredictMultiLabelBatch is like PredictMultiLabel for several texts in a single
+// forward pass. The labels of texts[i] are returned at index i.
+func (m *SequenceClassificationModel) PredictMultiLabelBatch(texts []string) ([][]ClassificationLabel, error) {
+	return m.PredictMultiLabelBatchContext(context.Background(), texts)
+}
+
+// PredictMultiLabelBatchContext is like PredictMultiLabelBatch but stops once ctx is
+// done, returning ctx.Err().
+func (m *SequenceClassificationModel) PredictMultiLabelBatchContext(ctx context.Context, texts []string) ([][]ClassificationLabel, error) {
+	return m.classify(ctx, texts, true)
+}
+
+func (m *SequenceClassificationModel) classify(ctx context.Context, texts []string, multiLabel bool) ([][]ClassificationLabel, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if len(texts) == 0 {
+		return [][]ClassificationLabel{}, nil
+	}
+
+	return withContext(ctx, &m.handle, func(ptr *C.SequenceClassificationModelWrapper, cancel *C.CancelToken) ([][]ClassificationLabel, error) {
+		cTexts := cStringArray(texts)
+		defer freeCStringArray(cTexts)
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_classify_batch(fnClassifyBatch, ptr, &cTexts[0], C.size_t(len(cTexts)), C.bool(multiLabel), cancel)
+		if res == nil {
+			return nil, lastError("SequenceClassificationModel.Predict")
+		}
+		defer C.call_free_classification_batch_result(fnFreeClassificationBatchResult, res)
+
+		cResults := unsafe.Slice(res.results, int(res.count))
+		results := make([][]ClassificationLabel, len(cResults))
+		for i := range cResults {
+			results[i] = classificationLabels(&cResults[i])
+		}
+		return results, nil
+	})
+}
+
+func classificationLabels(res *C.ClassificationResult) []ClassificationLabel {
+	cLabels := unsafe.Slice(res.labels, int(res.count))
+	labels := make([]ClassificationLabel, len(cLabels))
+	for i, l := range cLabels {
+		labels[i] = ClassificationLabel{
+			Text:  C.GoString(l.label),
+			ID:    int64(l.id),
+			Score: float64(l.score),
+		}
+	}
+	return labels
+}
+
+// first returns the only element of a single-input batch result.
+func first[T any](results []T, err error) (T, error) {
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return results[0], nil
+}
+
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
+func (m *SequenceClassificationModel) Close() {
+	m.handle.close(func(ptr *C.SequenceClassificationModelWrapper) {
+		C.call_free_sequence_classification_model(fnFreeSequenceClassificationModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *SequenceClassificationModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
+}
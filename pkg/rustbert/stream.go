@@ -0,0 +1,144 @@
+package rustbert
+
+/*
+#include <stdbool.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef struct {
+    void* model;
+    int64_t seed;
+} TextGenerationModelWrapper;
+
+typedef struct {
+    int64_t max_length;
+    int64_t min_length;
+    bool do_sample;
+    bool early_stopping;
+    double temperature;
+    int64_t top_k;
+    double top_p;
+    double repetition_penalty;
+    int64_t no_repeat_ngram_size;
+    int64_t num_beams;
+    int64_t num_return_sequences;
+    double length_penalty;
+    int64_t seed;
+} GenerateOptions;
+
+typedef struct CancelToken CancelToken;
+
+// Defined in stream_callback.go.
+extern bool goTextStreamCallback(uintptr_t user_data, char* text);
+
+typedef bool (*text_callback_t)(uintptr_t, const char*);
+typedef bool (*generate_text_stream_t)(TextGenerationModelWrapper*, const char*, const GenerateOptions*, text_callback_t, uintptr_t, CancelToken*);
+
+bool call_generate_text_stream(
+    void* f,
+    TextGenerationModelWrapper* w,
+    const char* prompt,
+    const GenerateOptions* o,
+    uintptr_t user_data,
+    CancelToken* cancel
+) {
+    return ((generate_text_stream_t)f)(w, prompt, o, (text_callback_t)goTextStreamCallback, user_data, cancel);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"iter"
+	"runtime"
+	"runtime/cgo"
+	"unsafe"
+)
+
+var fnGenerateTextStream unsafe.Pointer
+
+// textStream is the state of a GenerateStream call, reached from the native callback
+// through a cgo.Handle.
+type textStream struct {
+	ctx   context.Context
+	yield func(string, error) bool
+	// stopped is set once the caller has left the loop, cancelled once ctx is done.
+	stopped   bool
+	cancelled bool
+}
+
+// push hands a piece of text to the caller and reports whether generation should go on.
+func (s *textStream) push(text string) bool {
+	if s.ctx.Err() != nil {
+		s.cancelled = true
+		return false
+	}
+	if !s.yield(text, nil) {
+		s.stopped = true
+		return false
+	}
+	return true
+}
+
+// DefaultStreamOptions returns DefaultGenerateOptions adjusted for GenerateStream:
+// sampling with a single beam and a single returned sequence.
+func DefaultStreamOptions() GenerateOptions {
+	opts := DefaultGenerateOptions()
+	opts.NumBeams = 1
+	opts.NumReturnSequences = 1
+	return opts
+}
+
+// GenerateStream continues prompt and yields the generated text piece by piece as it
+// is decoded, typically a word or word fragment per step, so that a UI can display it
+// right away. Concatenating the pieces gives the continuation without the prompt.
+//
+// opts override the decoding settings of the model for this call; start from
+// DefaultStreamOptions. Only greedy decoding and sampling can be streamed: NumBeams
+// and NumReturnSequences must be 1, otherwise the sequence yields a single
+// ErrInvalidInput error, as it does for T5 models. DefaultGenerateOptions uses 5 beams
+// and is rejected as is.
+//
+// Generation stops when the loop is left, and after the decoding step in flight when
+// ctx is done, in which case ctx.Err() is yielded. Errors end the sequence.
+//
+// The loop body runs while m is locked by the stream. Calling any method of m that runs
+// the model, or Close, from inside it deadlocks; collect the pieces and make such
+// calls once the loop has ended.
+func (m *TextGenerationModel) GenerateStream(ctx context.Context, prompt string, opts GenerateOptions) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield("", err)
+			return
+		}
+
+		stream := &textStream{ctx: ctx, yield: yield}
+		h := cgo.NewHandle(stream)
+		defer h.Delete()
+
+		cPrompt := C.CString(prompt)
+		defer C.free(unsafe.Pointer(cPrompt))
+		cOpts := opts.toC()
+		token, release := cancelOnDone(ctx)
+		defer release()
+
+		_, err := withModel(&m.handle, func(ptr *C.TextGenerationModelWrapper) (struct{}, error) {
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+
+			if !C.call_generate_text_stream(fnGenerateTextStream, ptr, cPrompt, &cOpts, C.uintptr_t(h), token) {
+				return struct{}{}, lastError("TextGenerationModel.GenerateStream")
+			}
+			return struct{}{}, nil
+		})
+		switch {
+		case stream.stopped:
+		case isCancelled(err):
+			yield("", ctx.Err())
+		case err != nil:
+			yield("", err)
+		case stream.cancelled:
+			yield("", ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package rustbert
+
+// The preamble of a file with //export directives may only hold declarations, hence
+// this file apart from stream.go.
+
+/*
+#include <stdbool.h>
+#include <stdint.h>
+*/
+import "C"
+
+import "runtime/cgo"
+
+//export goTextStreamCallback
+func goTextStreamCallback(userData C.uintptr_t, text *C.char) C.bool {
+	stream := cgo.Handle(userData).Value().(*textStream)
+	return C.bool(stream.push(C.GoString(text)))
+}
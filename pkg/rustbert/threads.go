@@ -0,0 +1,173 @@
+package rustbert
+
+/*
+#include <stdbool.h>
+
+typedef bool (*set_num_threads_t)(int);
+typedef int (*get_num_threads_t)(void);
+
+bool call_set_num_threads(void* f, int count) {
+    return ((set_num_threads_t)f)(count);
+}
+
+int call_get_num_threads(void* f) {
+    return ((get_num_threads_t)f)();
+}
+
+bool call_set_num_interop_threads(void* f, int count) {
+    return ((set_num_threads_t)f)(count);
+}
+
+int call_get_num_interop_threads(void* f) {
+    return ((get_num_threads_t)f)();
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+)
+
+var (
+	fnSetNumThreads        unsafe.Pointer
+	fnGetNumThreads        unsafe.Pointer
+	fnSetNumInteropThreads unsafe.Pointer
+	fnGetNumInteropThreads unsafe.Pointer
+)
+
+var (
+	// threadsConfigured is set once a thread count has been given, process-wide or to
+	// a model. Until then calls leave libtorch's own counts alone.
+	threadsConfigured atomic.Bool
+	// numThreads is the intra-op count of SetNumThreads, 0 until it is called.
+	numThreads atomic.Int32
+	// defaultNumThreads is libtorch's intra-op count when the library was loaded.
+	defaultNumThreads int32
+)
+
+// ThreadConfig sizes libtorch's thread pools. A zero count leaves it to libtorch, which
+// uses one thread per physical core.
+type ThreadConfig struct {
+	// NumThreads is the number of threads a single operator is split over (intra-op).
+	NumThreads int
+	// NumInteropThreads is the number of threads running independent operators
+	// concurrently. It can only be set before the first model runs.
+	NumInteropThreads int
+}
+
+// apply sets the non-zero counts of c.
+func (c ThreadConfig) apply() error {
+	if c.NumThreads < 0 || c.NumInteropThreads < 0 {
+		return invalidInput("ThreadConfig", fmt.Sprintf("thread counts must not be negative, got %d and %d", c.NumThreads, c.NumInteropThreads))
+	}
+	if c.NumInteropThreads > 0 {
+		if err := SetNumInteropThreads(c.NumInteropThreads); err != nil {
+			return err
+		}
+	}
+	if c.NumThreads > 0 {
+		return SetNumThreads(c.NumThreads)
+	}
+	return nil
+}
+
+// SetNumThreads sets the number of intra-op threads of every model that has no count of
+// its own. With several models per process, or next to a busy Go scheduler, a count
+// below the number of cores keeps them from oversubscribing the CPUs.
+func SetNumThreads(n int) error {
+	if err := ensureInit(); err != nil {
+		return err
+	}
+	if n < 1 {
+		return invalidInput("SetNumThreads", fmt.Sprintf("number of threads must be at least 1, got %d", n))
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if !C.call_set_num_threads(fnSetNumThreads, C.int(n)) {
+		return lastError("SetNumThreads")
+	}
+	numThreads.Store(int32(n))
+	threadsConfigured.Store(true)
+	return nil
+}
+
+// SetNumInteropThreads sets the number of inter-op threads. libtorch only allows it
+// once, before any model has run; later calls return ErrInvalidInput.
+func SetNumInteropThreads(n int) error {
+	if err := ensureInit(); err != nil {
+		return err
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if !C.call_set_num_interop_threads(fnSetNumInteropThreads, C.int(n)) {
+		return lastError("SetNumInteropThreads")
+	}
+	return nil
+}
+
+// Threads returns the current sizes of libtorch's thread pools. NumThreads is the count
+// of the models without one of their own.
+func Threads() (ThreadConfig, error) {
+	if err := ensureInit(); err != nil {
+		return ThreadConfig{}, err
+	}
+	threads := int(numThreads.Load())
+	if threads == 0 {
+		threads = int(defaultNumThreads)
+	}
+	return ThreadConfig{
+		NumThreads:        threads,
+		NumInteropThreads: int(C.call_get_num_interop_threads(fnGetNumInteropThreads)),
+	}, nil
+}
+
+// libtorchNumThreads returns the intra-op count of the calling thread.
+func libtorchNumThreads() int32 {
+	return int32(C.call_get_num_threads(fnGetNumThreads))
+}
+
+// setNumThreads sets the intra-op count of the calls on h; 0 goes back to the count of
+// SetNumThreads.
+func (h *modelHandle[T]) setNumThreads(n int) error {
+	if n < 0 {
+		return invalidInput("SetNumThreads", fmt.Sprintf("number of threads must not be negative, got %d", n))
+	}
+	h.threads.Store(int32(n))
+	if n > 0 {
+		threadsConfigured.Store(true)
+	}
+	return nil
+}
+
+// useThreads sets the intra-op count of a call on the calling OS thread, which it keeps
+// locked until the returned function restores the previous count. The count is the
+// model's own, else the one of SetNumThreads, else libtorch's default, so that a thread
+// last used by another model does not keep its count. It does nothing until a count has
+// been configured.
+func useThreads(modelThreads int32) (release func()) {
+	if !threadsConfigured.Load() {
+		return func() {}
+	}
+	n := modelThreads
+	if n == 0 {
+		n = numThreads.Load()
+	}
+	if n == 0 {
+		n = defaultNumThreads
+	}
+
+	runtime.LockOSThread()
+	previous := libtorchNumThreads()
+	C.call_set_num_threads(fnSetNumThreads, C.int(n))
+	return func() {
+		C.call_set_num_threads(fnSetNumThreads, C.int(previous))
+		runtime.UnlockOSThread()
+	}
+}
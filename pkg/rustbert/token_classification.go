@@ -0,0 +1,273 @@
+package rustbert
+
+/*
+#include <stdbool.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef struct CancelToken CancelToken;
+
+typedef struct {
+    void* model;
+} TokenClassificationModelWrapper;
+
+typedef struct {
+    int aggregation;
+    bool ignore_outside;
+    int device;
+} TokenClassificationOptions;
+
+typedef struct {
+    char* text;
+    char* label;
+    int64_t label_index;
+    float score;
+    size_t sentence;
+    size_t offset_begin;
+    size_t offset_end;
+} TokenItem;
+
+typedef struct {
+    TokenItem* tokens;
+    size_t count;
+} TokenClassificationResult;
+
+typedef struct {
+    TokenClassificationResult* results;
+    size_t count;
+} TokenClassificationBatchResult;
+
+typedef TokenClassificationModelWrapper* (*new_token_classification_model_t)(const TokenClassificationOptions*);
+typedef TokenClassificationModelWrapper* (*new_token_classification_model_from_files_t)(const char*, const char*, const char*, const char*, int, const TokenClassificationOptions*);
+typedef TokenClassificationBatchResult* (*classify_tokens_batch_t)(TokenClassificationModelWrapper*, const char**, size_t, CancelToken*);
+typedef void (*free_token_classification_model_t)(TokenClassificationModelWrapper*);
+typedef void (*free_token_classification_batch_result_t)(TokenClassificationBatchResult*);
+
+TokenClassificationModelWrapper* call_new_token_classification_model(void* f, const TokenClassificationOptions* o) {
+    return ((new_token_classification_model_t)f)(o);
+}
+
+void* call_new_token_classification_model_from_files(
+    void* f,
+    const char* m,
+    const char* c,
+    const char* v,
+    const char* me,
+    int t,
+    const TokenClassificationOptions* o
+) {
+    return ((new_token_classification_model_from_files_t)f)(m, c, v, me, t, o);
+}
+
+TokenClassificationBatchResult* call_classify_tokens_batch(
+    void* f,
+    TokenClassificationModelWrapper* w,
+    const char** texts,
+    size_t count,
+    CancelToken* cancel
+) {
+    return ((classify_tokens_batch_t)f)(w, texts, count, cancel);
+}
+
+void call_free_token_classification_model(void* f, TokenClassificationModelWrapper* w) {
+    ((free_token_classification_model_t)f)(w);
+}
+
+void call_free_token_classification_batch_result(void* f, TokenClassificationBatchResult* r) {
+    ((free_token_classification_batch_result_t)f)(r);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"runtime"
+	"unsafe"
+)
+
+var (
+	fnNewTokenClassificationModel          unsafe.Pointer
+	fnNewTokenClassificationModelFromFiles unsafe.Pointer
+	fnClassifyTokensBatch                  unsafe.Pointer
+	fnFreeTokenClassificationModel         unsafe.Pointer
+	fnFreeTokenClassificationBatchResult   unsafe.Pointer
+)
+
+// LabelAggregation selects how the labels of the sub-tokens of a word are combined
+// into the label of the word.
+type LabelAggregation int
+
+const (
+	// AggregationFirst labels a word with the label of its first sub-token.
+	AggregationFirst LabelAggregation = iota
+	// AggregationLast labels a word with the label of its last sub-token.
+	AggregationLast
+	// AggregationAverage labels a word with the label of highest average score over
+	// its sub-tokens.
+	AggregationAverage
+	// AggregationMax labels a word with the label of its most confident sub-token.
+	AggregationMax
+	// AggregationMode labels a word with the most frequent label of its sub-tokens.
+	AggregationMode
+	// AggregationNone labels every sub-token separately.
+	AggregationNone
+)
+
+// TokenClassificationConfig configures a TokenClassificationModel. Start from
+// DefaultTokenClassificationConfig.
+type TokenClassificationConfig struct {
+	Aggregation LabelAggregation
+	// IgnoreOutside drops the tokens labelled "O", i.e. outside of any entity.
+	IgnoreOutside bool
+	// Device is the hardware the model runs on.
+	Device Device
+}
+
+// DefaultTokenClassificationConfig labels words with the label of their first
+// sub-token and keeps every token.
+func DefaultTokenClassificationConfig() TokenClassificationConfig {
+	return TokenClassificationConfig{
+		Aggregation: AggregationFirst,
+		Device:      DeviceAuto,
+	}
+}
+
+func (cfg TokenClassificationConfig) toC() C.TokenClassificationOptions {
+	return C.TokenClassificationOptions{
+		aggregation:    C.int(cfg.Aggregation),
+		ignore_outside: C.bool(cfg.IgnoreOutside),
+		device:         C.int(cfg.Device),
+	}
+}
+
+// LabeledToken is a word, or a sub-token with AggregationNone, and its label.
+type LabeledToken struct {
+	Text  string
+	Label string
+	// LabelIndex is the index of Label in the id2label map of the model config.
+	LabelIndex int64
+	Score      float64
+	// Sentence is the index of the sentence of the token in the input text.
+	Sentence int
+	// Offset locates the token in the input text.
+	Offset Offset
+}
+
+// TokenClassificationModel labels every token of a text with any token
+// classification checkpoint, e.g. a custom NER or PII tagger. Unlike NERModel and
+// POSModel it returns every label of the checkpoint, "O" included unless
+// TokenClassificationConfig.IgnoreOutside is set.
+type TokenClassificationModel struct {
+	handle modelHandle[C.TokenClassificationModelWrapper]
+}
+
+// NewTokenClassificationModel creates a TokenClassificationModel with BERT fine-tuned
+// on CoNLL-03 NER.
+func NewTokenClassificationModel(cfg TokenClassificationConfig) (*TokenClassificationModel, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+
+	cOpts := cfg.toC()
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_token_classification_model(fnNewTokenClassificationModel, &cOpts)
+	if ptr == nil {
+		return nil, lastError("NewTokenClassificationModel")
+	}
+	return &TokenClassificationModel{handle: modelHandle[C.TokenClassificationModelWrapper]{ptr: ptr}}, nil
+}
+
+// NewTokenClassificationModelFromFiles creates a TokenClassificationModel using local
+// files of a token classification checkpoint. Labels are read from the id2label map
+// of the config file.
+// See NewSentimentModelFromFiles for the expected files and error conditions.
+func NewTokenClassificationModelFromFiles(modelPath, configPath, vocabPath, mergesPath string, modelType int, cfg TokenClassificationConfig) (*TokenClassificationModel, error) {
+	cOpts := cfg.toC()
+	ptr, err := callNewModelFromFiles("NewTokenClassificationModelFromFiles", fnNewTokenClassificationModelFromFiles, func(fn unsafe.Pointer, m, c, v, me *C.char, t C.int) unsafe.Pointer {
+		return C.call_new_token_classification_model_from_files(fn, m, c, v, me, t, &cOpts)
+	}, modelPath, configPath, vocabPath, mergesPath, modelType)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenClassificationModel{handle: modelHandle[C.TokenClassificationModelWrapper]{ptr: (*C.TokenClassificationModelWrapper)(ptr)}}, nil
+}
+
+// Predict labels the tokens of text, in order of appearance.
+func (m *TokenClassificationModel) Predict(text string) ([]LabeledToken, error) {
+	return m.PredictContext(context.Background(), text)
+}
+
+// PredictContext is like Predict but returns ctx.Err() as soon as ctx is done.
+func (m *TokenClassificationModel) PredictContext(ctx context.Context, text string) ([]LabeledToken, error) {
+	return first(m.PredictBatchContext(ctx, []string{text}))
+}
+
+// PredictBatch labels the tokens of several texts. The tokens of texts[i] are
+// returned at index i.
+func (m *TokenClassificationModel) PredictBatch(texts []string) ([][]LabeledToken, error) {
+	return m.PredictBatchContext(context.Background(), texts)
+}
+
+// PredictBatchContext is like PredictBatch but stops once ctx is done, returning ctx.Err().
+func (m *TokenClassificationModel) PredictBatchContext(ctx context.Context, texts []string) ([][]LabeledToken, error) {
+	if m.handle.closed() {
+		return nil, ErrClosed
+	}
+	if len(texts) == 0 {
+		return [][]LabeledToken{}, nil
+	}
+
+	return withContext(ctx, &m.handle, func(ptr *C.TokenClassificationModelWrapper, cancel *C.CancelToken) ([][]LabeledToken, error) {
+		cTexts := cStringArray(texts)
+		defer freeCStringArray(cTexts)
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_classify_tokens_batch(fnClassifyTokensBatch, ptr, &cTexts[0], C.size_t(len(cTexts)), cancel)
+		if res == nil {
+			return nil, lastError("TokenClassificationModel.PredictBatch")
+		}
+		defer C.call_free_token_classification_batch_result(fnFreeTokenClassificationBatchResult, res)
+
+		cResults := unsafe.Slice(res.results, int(res.count))
+		results := make([][]LabeledToken, len(cResults))
+		for i := range cResults {
+			results[i] = labeledTokens(&cResults[i])
+		}
+		return results, nil
+	})
+}
+
+func labeledTokens(res *C.TokenClassificationResult) []LabeledToken {
+	cTokens := unsafe.Slice(res.tokens, int(res.count))
+	tokens := make([]LabeledToken, len(cTokens))
+	for i, t := range cTokens {
+		tokens[i] = LabeledToken{
+			Text:       C.GoString(t.text),
+			Label:      C.GoString(t.label),
+			LabelIndex: int64(t.label_index),
+			Score:      float64(t.score),
+			Sentence:   int(t.sentence),
+			Offset:     Offset{Begin: int(t.offset_begin), End: int(t.offset_end)},
+		}
+	}
+	return tokens
+}
+
+// Close frees the underlying Rust model once in-flight calls have returned. Later
+// calls return ErrClosed.
+func (m *TokenClassificationModel) Close() {
+	m.handle.close(func(ptr *C.TokenClassificationModelWrapper) {
+		C.call_free_token_classification_model(fnFreeTokenClassificationModel, ptr)
+	})
+}
+
+// SetNumThreads sets the number of intra-op threads of the model's calls in place of
+// the process-wide count of SetNumThreads; 0 goes back to it.
+func (m *TokenClassificationModel) SetNumThreads(n int) error {
+	return m.handle.setNumThreads(n)
+}
@@ -0,0 +1,289 @@
+package rustbert
+
+/*
+#include <stdbool.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef struct {
+    void* tokenizer;
+} TokenizerWrapper;
+
+typedef struct {
+    int64_t id;
+    char* token;
+    size_t offset_begin;
+    size_t offset_end;
+    bool special;
+    int8_t segment;
+} EncodedTokenItem;
+
+typedef struct {
+    EncodedTokenItem* tokens;
+    size_t count;
+    size_t num_truncated;
+    int64_t* overflowing;
+    size_t overflowing_count;
+} EncodingResult;
+
+typedef TokenizerWrapper* (*new_tokenizer_from_files_t)(const char*, const char*, int);
+typedef bool (*set_default_casing_t)(int);
+typedef EncodingResult* (*encode_text_t)(TokenizerWrapper*, const char*, const char*, size_t, int, size_t);
+typedef char* (*decode_ids_t)(TokenizerWrapper*, const int64_t*, size_t, bool);
+typedef void (*free_tokenizer_t)(TokenizerWrapper*);
+typedef void (*free_encoding_result_t)(EncodingResult*);
+
+TokenizerWrapper* call_new_tokenizer_from_files(void* f, const char* v, const char* me, int t) {
+    return ((new_tokenizer_from_files_t)f)(v, me, t);
+}
+
+bool call_set_default_casing(void* f, int code) {
+    return ((set_default_casing_t)f)(code);
+}
+
+EncodingResult* call_encode_text(
+    void* f,
+    TokenizerWrapper* w,
+    const char* first,
+    const char* second,
+    size_t max_length,
+    int truncation,
+    size_t stride
+) {
+    return ((encode_text_t)f)(w, first, second, max_length, truncation, stride);
+}
+
+char* call_decode_ids(void* f, TokenizerWrapper* w, const int64_t* ids, size_t count, bool skip_special) {
+    return ((decode_ids_t)f)(w, ids, count, skip_special);
+}
+
+void call_free_tokenizer(void* f, TokenizerWrapper* w) {
+    ((free_tokenizer_t)f)(w);
+}
+
+void call_free_encoding_result(void* f, EncodingResult* r) {
+    ((free_encoding_result_t)f)(r);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+var (
+	fnNewTokenizerFromFiles unsafe.Pointer
+	fnEncodeText            unsafe.Pointer
+	fnDecodeIDs             unsafe.Pointer
+	fnFreeTokenizer         unsafe.Pointer
+	fnFreeEncodingResult    unsafe.Pointer
+	fnSetDefaultCasing      unsafe.Pointer
+)
+
+// Truncation selects how inputs longer than TokenizerConfig.MaxLength are shortened.
+type Truncation int
+
+const (
+	// TruncateLongestFirst removes tokens from the longer text of a pair, one at a time.
+	TruncateLongestFirst Truncation = iota
+	// TruncateOnlyFirst removes tokens from the first text only.
+	TruncateOnlyFirst
+	// TruncateOnlySecond removes tokens from the second text of a pair only.
+	TruncateOnlySecond
+	// NoTruncation keeps every token, ignoring TokenizerConfig.MaxLength.
+	NoTruncation
+)
+
+// Casing tells the *FromFiles constructors and NewTokenizerFromFiles whether to lower
+// case the input of a BERT, DistilBERT or ELECTRA checkpoint, which come both cased and
+// uncased, when its files do not tell. They are read first: do_lower_case in the
+// tokenizer_config.json next to the vocabulary or in config.json, then the checkpoint
+// name config.json was saved from, such as "bert-base-uncased". Set it with
+// InitOptions.Casing.
+type Casing int
+
+const (
+	// CasingAuto assumes the checkpoint is cased, as rust-bert's pipelines do, and
+	// logs a warning to InitOptions.Logger.
+	CasingAuto Casing = iota
+	// CasingCased keeps the case of the input.
+	CasingCased
+	// CasingUncased lower cases the input.
+	CasingUncased
+)
+
+// setDefaultCasing sets the casing of the checkpoints whose files do not tell.
+func setDefaultCasing(casing Casing) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if !C.call_set_default_casing(fnSetDefaultCasing, C.int(casing)) {
+		return lastError("InitOptions.Casing")
+	}
+	return nil
+}
+
+// TokenizerConfig configures how a Tokenizer encodes. Start from DefaultTokenizerConfig.
+type TokenizerConfig struct {
+	// MaxLength is the maximum number of tokens of an encoding, special tokens included.
+	MaxLength  int
+	Truncation Truncation
+	// Stride is the number of tokens kept before the truncated ones in
+	// Encoding.Overflowing, as overlap between the two.
+	Stride int
+}
+
+// DefaultTokenizerConfig truncates to the 512 tokens the pipelines feed their models,
+// longest text first.
+func DefaultTokenizerConfig() TokenizerConfig {
+	return TokenizerConfig{
+		MaxLength:  512,
+		Truncation: TruncateLongestFirst,
+	}
+}
+
+// Encoding is a text, or pair of texts, split into tokens. The slices have one entry
+// per token.
+type Encoding struct {
+	IDs []int64
+	// Tokens are the vocabulary entries of the ids, subword markers such as "##" and
+	// "Ġ" included.
+	Tokens []string
+	// Offsets locate the tokens in their input. Special tokens have a zero Offset.
+	Offsets []Offset
+	// SpecialTokensMask is true for the tokens added by the tokenizer, such as [CLS]
+	// and [SEP].
+	SpecialTokensMask []bool
+	// TypeIDs is 0 for the tokens of the first text and 1 for those of the second
+	// text of a pair.
+	TypeIDs []int
+	// NumTruncated is the number of tokens removed to fit MaxLength.
+	NumTruncated int
+	// Overflowing holds the ids of the removed tokens, preceded by
+	// TokenizerConfig.Stride tokens of overlap.
+	Overflowing []int64
+}
+
+// Tokenizer splits text into the tokens a checkpoint was trained on, with the same
+// rust-tokenizers implementation the pipelines use. It lets callers count tokens,
+// truncate inputs ahead of time or align model outputs with words.
+type Tokenizer struct {
+	handle modelHandle[C.TokenizerWrapper]
+	cfg    TokenizerConfig
+}
+
+// NewTokenizerFromFiles loads the tokenizer of a checkpoint from the vocabPath and
+// mergesPath passed to its *FromFiles constructor. mergesPath may be empty for model
+// types without merges. Flags such as lower casing are read from the
+// tokenizer_config.json next to the vocabulary, falling back to the model type and the
+// config.json there, as the constructors do.
+func NewTokenizerFromFiles(vocabPath, mergesPath string, modelType int, cfg TokenizerConfig) (*Tokenizer, error) {
+	if err := ensureInit(); err != nil {
+		return nil, err
+	}
+	if cfg.MaxLength < 1 && cfg.Truncation != NoTruncation {
+		return nil, invalidInput("NewTokenizerFromFiles", fmt.Sprintf("MaxLength must be at least 1, got %d", cfg.MaxLength))
+	}
+	if cfg.Stride < 0 {
+		return nil, invalidInput("NewTokenizerFromFiles", fmt.Sprintf("Stride cannot be negative, got %d", cfg.Stride))
+	}
+
+	cVocab := C.CString(vocabPath)
+	defer C.free(unsafe.Pointer(cVocab))
+	var cMerges *C.char
+	if mergesPath != "" {
+		cMerges = C.CString(mergesPath)
+		defer C.free(unsafe.Pointer(cMerges))
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	ptr := C.call_new_tokenizer_from_files(fnNewTokenizerFromFiles, cVocab, cMerges, C.int(modelType))
+	if ptr == nil {
+		return nil, lastError("NewTokenizerFromFiles")
+	}
+	logWarning("NewTokenizerFromFiles")
+	return &Tokenizer{handle: modelHandle[C.TokenizerWrapper]{ptr: ptr, auxiliary: true}, cfg: cfg}, nil
+}
+
+// Encode splits text into tokens, adding the special tokens of the model.
+func (t *Tokenizer) Encode(text string) (Encoding, error) {
+	return t.encode("Tokenizer.Encode", text, nil)
+}
+
+// EncodePair encodes two texts as a single input, e.g. a question and its context,
+// with the separators of the model between them.
+func (t *Tokenizer) EncodePair(first, second string) (Encoding, error) {
+	cSecond := C.CString(second)
+	defer C.free(unsafe.Pointer(cSecond))
+	return t.encode("Tokenizer.EncodePair", first, cSecond)
+}
+
+func (t *Tokenizer) encode(op, first string, cSecond *C.char) (Encoding, error) {
+	cFirst := C.CString(first)
+	defer C.free(unsafe.Pointer(cFirst))
+
+	return withModel(&t.handle, func(ptr *C.TokenizerWrapper) (Encoding, error) {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_encode_text(fnEncodeText, ptr, cFirst, cSecond, C.size_t(t.cfg.MaxLength), C.int(t.cfg.Truncation), C.size_t(t.cfg.Stride))
+		if res == nil {
+			return Encoding{}, lastError(op)
+		}
+		defer C.call_free_encoding_result(fnFreeEncodingResult, res)
+
+		cTokens := unsafe.Slice(res.tokens, int(res.count))
+		enc := Encoding{
+			IDs:               make([]int64, len(cTokens)),
+			Tokens:            make([]string, len(cTokens)),
+			Offsets:           make([]Offset, len(cTokens)),
+			SpecialTokensMask: make([]bool, len(cTokens)),
+			TypeIDs:           make([]int, len(cTokens)),
+			NumTruncated:      int(res.num_truncated),
+			Overflowing:       make([]int64, int(res.overflowing_count)),
+		}
+		for i, id := range unsafe.Slice(res.overflowing, int(res.overflowing_count)) {
+			enc.Overflowing[i] = int64(id)
+		}
+		for i, tok := range cTokens {
+			enc.IDs[i] = int64(tok.id)
+			enc.Tokens[i] = C.GoString(tok.token)
+			enc.Offsets[i] = Offset{Begin: int(tok.offset_begin), End: int(tok.offset_end)}
+			enc.SpecialTokensMask[i] = bool(tok.special)
+			enc.TypeIDs[i] = int(tok.segment)
+		}
+		return enc, nil
+	})
+}
+
+// Decode turns ids back into text, leaving out special tokens.
+func (t *Tokenizer) Decode(ids []int64) (string, error) {
+	return withModel(&t.handle, func(ptr *C.TokenizerWrapper) (string, error) {
+		var cIDs *C.int64_t
+		if len(ids) > 0 {
+			cIDs = (*C.int64_t)(unsafe.Pointer(&ids[0]))
+		}
+
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		res := C.call_decode_ids(fnDecodeIDs, ptr, cIDs, C.size_t(len(ids)), C.bool(true))
+		if res == nil {
+			return "", lastError("Tokenizer.Decode")
+		}
+		defer freeString(res)
+		return C.GoString(res), nil
+	})
+}
+
+// Close frees the underlying Rust tokenizer once in-flight calls have returned. Later
+// calls return ErrClosed.
+func (t *Tokenizer) Close() {
+	t.handle.close(func(ptr *C.TokenizerWrapper) {
+		C.call_free_tokenizer(fnFreeTokenizer, ptr)
+	})
+}
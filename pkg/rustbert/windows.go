@@ -0,0 +1,69 @@
+package rustbert
+
+/*
+#include <stddef.h>
+
+typedef struct {
+    size_t size;
+    size_t stride;
+    int aggregation;
+} WindowOptions;
+*/
+import "C"
+
+import "fmt"
+
+// ScoreAggregation selects how the classification scores of the windows of a long text
+// are combined into one result.
+type ScoreAggregation int
+
+const (
+	// ScoreMean averages the probabilities of the windows.
+	ScoreMean ScoreAggregation = iota
+	// ScoreMax keeps the result of the most confident window.
+	ScoreMax
+	// ScoreFirst keeps the result of the first window.
+	ScoreFirst
+)
+
+// WindowConfig configures how the PredictLong methods split texts longer than the
+// model accepts. Start from DefaultWindowConfig.
+type WindowConfig struct {
+	// WindowSize is the number of tokens per window, at most 510 so that the window
+	// and the special tokens of the model fit in 512 tokens.
+	WindowSize int
+	// Stride is the number of tokens shared by consecutive windows, so that text cut
+	// at the end of a window is seen whole in the next one. It must be smaller than
+	// WindowSize.
+	Stride int
+	// Aggregation combines the scores of the windows of a classified text.
+	Aggregation ScoreAggregation
+}
+
+// DefaultWindowConfig splits texts into windows of 384 tokens overlapping by 128 and
+// averages their scores.
+func DefaultWindowConfig() WindowConfig {
+	return WindowConfig{
+		WindowSize:  384,
+		Stride:      128,
+		Aggregation: ScoreMean,
+	}
+}
+
+func (cfg WindowConfig) validate(op string) error {
+	if cfg.WindowSize < 1 {
+		return invalidInput(op, fmt.Sprintf("window size must be at least 1, got %d", cfg.WindowSize))
+	}
+	if cfg.Stride < 0 || cfg.Stride >= cfg.WindowSize {
+		return invalidInput(op, fmt.Sprintf("stride must be between 0 and the window size %d, got %d", cfg.WindowSize, cfg.Stride))
+	}
+	return nil
+}
+
+func (cfg WindowConfig) toC() C.WindowOptions {
+	return C.WindowOptions{
+		size:        C.size_t(cfg.WindowSize),
+		stride:      C.size_t(cfg.Stride),
+		aggregation: C.int(cfg.Aggregation),
+	}
+}
@@ -0,0 +1,93 @@
+//! Cooperative cancellation for long running calls.
+//!
+//! The Go side allocates a `CancelToken` per call, passes it to the batch functions and
+//! flips it from another thread when its `context.Context` is done. The token is checked
+//! before a batch starts, between the batches of the pipelines that split their inputs,
+//! and between the decoding steps of greedy and sampled text generation, so a cancelled
+//! call stops soon after instead of running to completion with the model locked.
+
+use std::sync::atomic::{AtomicBool, Ordering};
+
+use crate::error::{ErrorCode, FfiError};
+
+/// Cancellation flag shared between the Go caller and the thread running inference.
+pub struct CancelToken {
+    cancelled: AtomicBool,
+}
+
+impl CancelToken {
+    fn is_cancelled(&self) -> bool {
+        self.cancelled.load(Ordering::Acquire)
+    }
+}
+
+fn cancelled_error() -> FfiError {
+    FfiError::new(ErrorCode::Cancelled, "operation cancelled")
+}
+
+/// Returns a `Cancelled` error if `token` has been cancelled. A NULL token never is.
+pub fn check_cancelled(token: *const CancelToken) -> Result<(), FfiError> {
+    match unsafe { token.as_ref() } {
+        Some(token) if token.is_cancelled() => Err(cancelled_error()),
+        _ => Ok(()),
+    }
+}
+
+/// Runs `f` over the whole of `inputs` once `token` has been checked. For pipelines that
+/// run their inputs as a single batch, where cutting it up would only slow them down.
+pub fn run_batched<I, O, F>(token: *const CancelToken, inputs: &[I], f: F) -> Result<Vec<O>, FfiError>
+where
+    F: FnOnce(&[I]) -> Result<Vec<O>, FfiError>,
+{
+    check_cancelled(token)?;
+    f(inputs)
+}
+
+/// Runs `f` over `inputs` in batches of `batch_size`, the size the pipeline batches its
+/// inputs in anyway, checking `token` before each one, and concatenates the outputs.
+pub fn run_in_batches<I, O, F>(
+    token: *const CancelToken,
+    inputs: &[I],
+    batch_size: usize,
+    mut f: F,
+) -> Result<Vec<O>, FfiError>
+where
+    F: FnMut(&[I]) -> Result<Vec<O>, FfiError>,
+{
+    let mut outputs = Vec::with_capacity(inputs.len());
+    for batch in inputs.chunks(batch_size.max(1)) {
+        check_cancelled(token)?;
+        outputs.extend(f(batch)?);
+    }
+    Ok(outputs)
+}
+
+// ============================================================================
+// Cancellation FFI Functions
+// ============================================================================
+
+/// Create a new, not yet cancelled token. Release it with `free_cancel_token`.
+#[no_mangle]
+pub extern "C" fn new_cancel_token() -> *mut CancelToken {
+    Box::into_raw(Box::new(CancelToken {
+        cancelled: AtomicBool::new(false),
+    }))
+}
+
+/// Mark a token as cancelled. Safe to call from any thread while the token is in use.
+#[no_mangle]
+pub extern "C" fn cancel_token_cancel(token: *mut CancelToken) {
+    if let Some(token) = unsafe { token.as_ref() } {
+        token.cancelled.store(true, Ordering::Release);
+    }
+}
+
+/// Free a cancellation token
+#[no_mangle]
+pub extern "C" fn free_cancel_token(token: *mut CancelToken) {
+    if !token.is_null() {
+        unsafe {
+            drop(Box::from_raw(token));
+        }
+    }
+}
@@ -0,0 +1,31 @@
+//! Lookup and trimming of the conversations held by a `ConversationManager`.
+
+use rust_bert::pipelines::conversation::{Conversation, ConversationManager};
+use uuid::Uuid;
+
+use crate::error::FfiError;
+
+/// Returns the conversation with the given UUID, or an invalid input error if the id is
+/// malformed or unknown.
+pub fn find<'a>(manager: &'a mut ConversationManager, id: &str) -> Result<&'a mut Conversation, FfiError> {
+    let uuid = parse_id(id)?;
+    manager
+        .get(&uuid)
+        .ok_or_else(|| FfiError::invalid_input(format!("unknown conversation {}", id)))
+}
+
+pub fn parse_id(id: &str) -> Result<Uuid, FfiError> {
+    Uuid::parse_str(id).map_err(|_| FfiError::invalid_input(format!("invalid conversation id {:?}", id)))
+}
+
+/// Keeps the last `turns` exchanges of `conversation`, dropping older ones from both the
+/// text and the token history the model is prompted with. A pending user input is kept.
+pub fn trim(conversation: &mut Conversation, turns: usize) {
+    let excess = conversation.past_user_inputs.len().saturating_sub(turns);
+    conversation.past_user_inputs.drain(..excess);
+    let excess = conversation.generated_responses.len().saturating_sub(turns);
+    conversation.generated_responses.drain(..excess);
+    // Each exchange adds the ids of the user input and of the response.
+    let excess = conversation.history.len().saturating_sub(2 * turns);
+    conversation.history.drain(..excess);
+}
@@ -0,0 +1,70 @@
+//! Device selection shared by the model constructors.
+//!
+//! Every model constructor takes the code of the Go `Device` to load its model on as an
+//! argument. `DeviceAuto` resolves to the process default of `set_default_device`, else
+//! to CUDA when available.
+
+use std::sync::atomic::{AtomicI32, Ordering};
+
+use tch::{Cuda, Device};
+
+use crate::error::{ffi_call, FfiError};
+
+/// Device code of the models built with `DeviceAuto`, 0 when none was set
+static DEFAULT_DEVICE: AtomicI32 = AtomicI32::new(0);
+
+/// Decodes the device code used by the Go `Device` type: 0 is the process default, or
+/// CUDA when available, -1 is the CPU, -2 is MPS and n > 0 is CUDA device n - 1. Devices
+/// that are not present are rejected rather than failing on first use.
+pub fn device_from_code(code: i32) -> Result<Device, FfiError> {
+    match code {
+        0 => match DEFAULT_DEVICE.load(Ordering::Relaxed) {
+            0 => Ok(Device::cuda_if_available()),
+            code => device_from_code(code),
+        },
+        -1 => Ok(Device::Cpu),
+        -2 if tch::utils::has_mps() => Ok(Device::Mps),
+        -2 => Err(FfiError::invalid_input("MPS is not available")),
+        n if n > 0 => {
+            let index = (n - 1) as usize;
+            let count = cuda_device_count() as usize;
+            if index < count {
+                Ok(Device::Cuda(index))
+            } else {
+                Err(FfiError::invalid_input(format!(
+                    "CUDA device {} is not available ({} found)",
+                    index, count
+                )))
+            }
+        }
+        _ => Err(FfiError::invalid_input(format!("unknown device code {}", code))),
+    }
+}
+
+/// Sets the device of the models built with `DeviceAuto`; 0 restores the default.
+#[no_mangle]
+pub extern "C" fn set_default_device(code: i32) -> bool {
+    ffi_call(false, || {
+        if code != 0 {
+            device_from_code(code)?;
+        }
+        DEFAULT_DEVICE.store(code, Ordering::Relaxed);
+        Ok(true)
+    })
+}
+
+/// Number of usable CUDA devices, 0 when CUDA is not available.
+#[no_mangle]
+pub extern "C" fn cuda_device_count() -> i64 {
+    if Cuda::is_available() {
+        Cuda::device_count()
+    } else {
+        0
+    }
+}
+
+/// Whether the Metal Performance Shaders backend is available.
+#[no_mangle]
+pub extern "C" fn mps_available() -> bool {
+    tch::utils::has_mps()
+}
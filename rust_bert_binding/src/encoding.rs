@@ -0,0 +1,89 @@
+//! Building blocks for pipelines that run a rust-bert model directly rather than
+//! through its pipeline type, when the pipeline hides outputs we need.
+
+use rust_bert::pipelines::common::{ModelResource, ModelType, TokenizerOption};
+use rust_bert::resources::ResourceProvider;
+use rust_bert::RustBertError;
+use rust_tokenizers::tokenizer::TruncationStrategy;
+use rust_tokenizers::TokenizedInput;
+use tch::nn::VarStore;
+use tch::{Device, Kind, Tensor};
+
+/// Longest input, in tokens, passed to the models.
+pub const MAX_LENGTH: usize = 512;
+
+/// Loads the tokenizer described by the resources of a pipeline config.
+pub fn load_tokenizer(
+    model_type: ModelType,
+    vocab: &(dyn ResourceProvider + Send),
+    merges: Option<&(dyn ResourceProvider + Send)>,
+    lower_case: bool,
+    strip_accents: Option<bool>,
+    add_prefix_space: Option<bool>,
+) -> Result<TokenizerOption, RustBertError> {
+    let vocab_path = vocab.get_local_path()?;
+    let merges_path = match merges {
+        Some(merges) => Some(merges.get_local_path()?),
+        None => None,
+    };
+    TokenizerOption::from_file(
+        model_type,
+        vocab_path.to_str().unwrap_or_default(),
+        merges_path.as_ref().and_then(|p| p.to_str()),
+        lower_case,
+        strip_accents,
+        add_prefix_space,
+    )
+}
+
+/// Loads the weights of `model` into `var_store`, whose variables must already be
+/// declared by the model.
+pub fn load_weights(var_store: &mut VarStore, model: &ModelResource, kind: Option<Kind>) -> Result<(), RustBertError> {
+    #[allow(unreachable_patterns)]
+    let weights = match model {
+        ModelResource::Torch(weights) => weights.get_local_path()?,
+        _ => {
+            return Err(RustBertError::InvalidConfigurationError(
+                "only Torch weights are supported".to_string(),
+            ))
+        }
+    };
+    var_store.load(weights)?;
+    if let Some(kind) = kind {
+        var_store.set_kind(kind);
+    }
+    Ok(())
+}
+
+/// Tokenized batch, padded to its longest input.
+pub struct Batch {
+    pub inputs: Vec<TokenizedInput>,
+    pub input_ids: Tensor,
+    pub attention_mask: Tensor,
+}
+
+/// Tokenizes `texts` and pads them into input and attention mask tensors on `device`.
+pub fn encode_batch<S>(tokenizer: &TokenizerOption, texts: &[S], device: Device) -> Batch
+where
+    S: AsRef<str> + Sync,
+{
+    let inputs = tokenizer.encode_list(texts, MAX_LENGTH, &TruncationStrategy::LongestFirst, 0);
+    let max_len = inputs.iter().map(|t| t.token_ids.len()).max().unwrap_or(0);
+    let pad_id = tokenizer.get_pad_id().unwrap_or(0);
+
+    let mut ids = Vec::with_capacity(inputs.len());
+    let mut masks = Vec::with_capacity(inputs.len());
+    for input in &inputs {
+        let mut token_ids = input.token_ids.clone();
+        let mut attention = vec![1i64; token_ids.len()];
+        token_ids.resize(max_len, pad_id);
+        attention.resize(max_len, 0);
+        ids.push(Tensor::from_slice(&token_ids));
+        masks.push(Tensor::from_slice(&attention));
+    }
+    Batch {
+        inputs,
+        input_ids: Tensor::stack(&ids, 0).to(device),
+        attention_mask: Tensor::stack(&masks, 0).to(device),
+    }
+}
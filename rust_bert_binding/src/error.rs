@@ -0,0 +1,164 @@
+//! Thread-local error reporting for the FFI surface.
+//!
+//! Every exported function that can fail clears the last error on entry and records
+//! a code and message before returning its failure value (usually NULL). The Go side
+//! reads them back on the same OS thread through `last_error_code` and
+//! `last_error_message`. A call that succeeds in a degraded way, such as guessing a
+//! setting its files leave out, records a warning instead, read with `take_last_warning`.
+
+use libc::c_char;
+use rust_bert::RustBertError;
+use std::any::Any;
+use std::cell::RefCell;
+use std::panic::{self, AssertUnwindSafe};
+
+use crate::string_to_cstr;
+
+/// Error categories shared with the Go `ErrorCode` type. Values must stay in sync.
+#[repr(i32)]
+#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+pub enum ErrorCode {
+    None = 0,
+    Unknown = 1,
+    InvalidInput = 2,
+    ModelLoad = 3,
+    Tokenization = 4,
+    Inference = 5,
+    OutOfMemory = 6,
+    Cancelled = 7,
+}
+
+/// Error recorded for the calling thread.
+#[derive(Debug)]
+pub struct FfiError {
+    pub code: ErrorCode,
+    pub message: String,
+}
+
+impl FfiError {
+    pub fn new(code: ErrorCode, message: impl Into<String>) -> FfiError {
+        FfiError {
+            code,
+            message: message.into(),
+        }
+    }
+
+    pub fn invalid_input(message: impl Into<String>) -> FfiError {
+        FfiError::new(ErrorCode::InvalidInput, message)
+    }
+
+    pub fn model_load(message: impl Into<String>) -> FfiError {
+        FfiError::new(ErrorCode::ModelLoad, message)
+    }
+
+    /// Classifies a rust-bert error. `fallback` is used when the error kind alone does
+    /// not tell what went wrong, e.g. a `TchError` is a load failure during construction
+    /// but an inference failure afterwards.
+    pub fn from_rust_bert(err: RustBertError, fallback: ErrorCode) -> FfiError {
+        let message = err.to_string();
+        let code = if is_out_of_memory(&message) {
+            ErrorCode::OutOfMemory
+        } else {
+            match err {
+                RustBertError::TokenizerError(_) => ErrorCode::Tokenization,
+                RustBertError::ValueError(_) => ErrorCode::InvalidInput,
+                RustBertError::FileDownloadError(_)
+                | RustBertError::IOError(_)
+                | RustBertError::InvalidConfigurationError(_) => ErrorCode::ModelLoad,
+                _ => fallback,
+            }
+        };
+        FfiError::new(code, message)
+    }
+
+    fn from_panic(payload: Box<dyn Any + Send>) -> FfiError {
+        let message = if let Some(s) = payload.downcast_ref::<&str>() {
+            s.to_string()
+        } else if let Some(s) = payload.downcast_ref::<String>() {
+            s.clone()
+        } else {
+            "unknown panic".to_string()
+        };
+        let code = if is_out_of_memory(&message) {
+            ErrorCode::OutOfMemory
+        } else {
+            ErrorCode::Inference
+        };
+        FfiError::new(code, format!("panic: {}", message))
+    }
+}
+
+fn is_out_of_memory(message: &str) -> bool {
+    let message = message.to_lowercase();
+    message.contains("out of memory") || message.contains("failed to allocate")
+}
+
+thread_local! {
+    static LAST_ERROR: RefCell<Option<FfiError>> = const { RefCell::new(None) };
+    static LAST_WARNING: RefCell<Option<String>> = const { RefCell::new(None) };
+}
+
+/// Records a warning for the calling thread, replacing an earlier one of the same call.
+pub fn warn(message: impl Into<String>) {
+    LAST_WARNING.with(|w| *w.borrow_mut() = Some(message.into()));
+}
+
+fn set_last_error(err: FfiError) {
+    LAST_ERROR.with(|e| *e.borrow_mut() = Some(err));
+}
+
+fn clear_last_error() {
+    LAST_ERROR.with(|e| *e.borrow_mut() = None);
+}
+
+/// Runs the body of an exported function. Errors and panics are recorded as the
+/// thread's last error and `failure` is returned in their place, so no panic ever
+/// unwinds across the FFI boundary.
+pub fn ffi_call<T, F>(failure: T, f: F) -> T
+where
+    F: FnOnce() -> Result<T, FfiError>,
+{
+    clear_last_error();
+    LAST_WARNING.with(|w| *w.borrow_mut() = None);
+    match panic::catch_unwind(AssertUnwindSafe(f)) {
+        Ok(Ok(value)) => value,
+        Ok(Err(err)) => {
+            set_last_error(err);
+            failure
+        }
+        Err(payload) => {
+            set_last_error(FfiError::from_panic(payload));
+            failure
+        }
+    }
+}
+
+// ============================================================================
+// Error FFI Functions
+// ============================================================================
+
+/// Code of the last error recorded on the calling thread, 0 if the last call succeeded
+#[no_mangle]
+pub extern "C" fn last_error_code() -> i32 {
+    LAST_ERROR.with(|e| e.borrow().as_ref().map_or(ErrorCode::None, |e| e.code) as i32)
+}
+
+/// Message of the last error recorded on the calling thread, NULL if there is none.
+/// The returned string must be released with `free_string`.
+#[no_mangle]
+pub extern "C" fn last_error_message() -> *mut c_char {
+    LAST_ERROR.with(|e| match e.borrow().as_ref() {
+        Some(err) => string_to_cstr(&err.message),
+        None => std::ptr::null_mut(),
+    })
+}
+
+/// Warning recorded by the last call on the calling thread, NULL if there is none. The
+/// warning is cleared; the returned string must be released with `free_string`.
+#[no_mangle]
+pub extern "C" fn take_last_warning() -> *mut c_char {
+    LAST_WARNING.with(|w| match w.borrow_mut().take() {
+        Some(message) => string_to_cstr(&message),
+        None => std::ptr::null_mut(),
+    })
+}
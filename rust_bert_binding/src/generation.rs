@@ -0,0 +1,132 @@
+//! Generation parameters passed in from Go.
+
+use std::sync::{Mutex, PoisonError};
+
+use rust_bert::pipelines::conversation::ConversationConfig;
+use rust_bert::pipelines::summarization::SummarizationConfig;
+use rust_bert::pipelines::text_generation::TextGenerationConfig;
+
+use crate::error::FfiError;
+
+/// Copies generation options onto a pipeline config. The pipeline configs share field
+/// names but no trait, hence the macro.
+macro_rules! apply_options {
+    ($options:expr, $config:expr) => {
+        $config.max_length = $options.max_length();
+        $config.min_length = $options.min_length;
+        $config.do_sample = $options.do_sample;
+        $config.early_stopping = $options.early_stopping;
+        $config.temperature = $options.temperature;
+        $config.top_k = $options.top_k;
+        $config.top_p = $options.top_p;
+        $config.repetition_penalty = $options.repetition_penalty;
+        $config.no_repeat_ngram_size = $options.no_repeat_ngram_size;
+        $config.num_beams = $options.num_beams;
+        $config.num_return_sequences = $options.num_return_sequences;
+        $config.length_penalty = $options.length_penalty;
+    };
+}
+
+/// Generation parameters. Must match the Go `GenerateOptions` mirror struct.
+#[repr(C)]
+#[derive(Clone, Copy, Debug)]
+pub struct GenerateOptions {
+    /// Maximum sequence length, <= 0 for no explicit limit
+    pub max_length: i64,
+    pub min_length: i64,
+    pub do_sample: bool,
+    pub early_stopping: bool,
+    pub temperature: f64,
+    pub top_k: i64,
+    pub top_p: f64,
+    pub repetition_penalty: f64,
+    pub no_repeat_ngram_size: i64,
+    pub num_beams: i64,
+    pub num_return_sequences: i64,
+    pub length_penalty: f64,
+    /// Seed for the torch RNG, applied before every call. Negative leaves it unseeded.
+    pub seed: i64,
+}
+
+impl GenerateOptions {
+    /// Reads options passed by pointer, rejecting NULL and inconsistent values.
+    pub fn from_ptr(options: *const GenerateOptions) -> Result<GenerateOptions, FfiError> {
+        let options = match unsafe { options.as_ref() } {
+            Some(options) => *options,
+            None => return Err(FfiError::invalid_input("generate options are NULL")),
+        };
+        options.validate()?;
+        Ok(options)
+    }
+
+    fn validate(&self) -> Result<(), FfiError> {
+        if self.num_beams < 1 {
+            return Err(FfiError::invalid_input("num_beams must be at least 1"));
+        }
+        if self.num_return_sequences < 1 {
+            return Err(FfiError::invalid_input("num_return_sequences must be at least 1"));
+        }
+        if !self.do_sample && self.num_return_sequences > self.num_beams {
+            return Err(FfiError::invalid_input(format!(
+                "num_return_sequences ({}) cannot exceed num_beams ({}) without sampling",
+                self.num_return_sequences, self.num_beams
+            )));
+        }
+        if self.min_length < 0 {
+            return Err(FfiError::invalid_input("min_length cannot be negative"));
+        }
+        if self.max_length > 0 && self.min_length > self.max_length {
+            return Err(FfiError::invalid_input(format!(
+                "min_length ({}) exceeds max_length ({})",
+                self.min_length, self.max_length
+            )));
+        }
+        if self.temperature <= 0.0 {
+            return Err(FfiError::invalid_input("temperature must be positive"));
+        }
+        if self.top_k < 0 {
+            return Err(FfiError::invalid_input("top_k cannot be negative"));
+        }
+        if self.top_p <= 0.0 || self.top_p > 1.0 {
+            return Err(FfiError::invalid_input("top_p must be in (0, 1]"));
+        }
+        if self.repetition_penalty < 1.0 {
+            return Err(FfiError::invalid_input("repetition_penalty must be at least 1.0"));
+        }
+        if self.no_repeat_ngram_size < 0 {
+            return Err(FfiError::invalid_input("no_repeat_ngram_size cannot be negative"));
+        }
+        Ok(())
+    }
+
+    pub fn max_length(&self) -> Option<i64> {
+        (self.max_length > 0).then_some(self.max_length)
+    }
+
+    pub fn apply_to_text_generation(&self, config: &mut TextGenerationConfig) {
+        apply_options!(self, config);
+    }
+
+    pub fn apply_to_summarization(&self, config: &mut SummarizationConfig) {
+        apply_options!(self, config);
+    }
+
+    pub fn apply_to_conversation(&self, config: &mut ConversationConfig) {
+        apply_options!(self, config);
+    }
+}
+
+/// Serialises the seeded calls: the torch RNG is global to the process, so two seeded
+/// calls running at once would consume each other's random numbers.
+static SEEDED: Mutex<()> = Mutex::new(());
+
+/// Runs `generate` with the torch RNG seeded so sampled generation is reproducible,
+/// holding `SEEDED` for its duration. Negative seeds run it unseeded and concurrently.
+pub fn seeded<T>(seed: i64, generate: impl FnOnce() -> T) -> T {
+    if seed < 0 {
+        return generate();
+    }
+    let _guard = SEEDED.lock().unwrap_or_else(PoisonError::into_inner);
+    tch::manual_seed(seed);
+    generate()
+}
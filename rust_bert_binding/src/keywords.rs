@@ -0,0 +1,128 @@
+//! Keyword and keyphrase extraction on top of a sentence embeddings model.
+
+use libc::{c_char, size_t};
+use rust_bert::pipelines::keywords_extraction::{
+    Keyword, KeywordExtractionConfig, KeywordExtractionModel, KeywordScorerType,
+};
+use rust_bert::pipelines::sentence_embeddings::SentenceEmbeddingsConfig;
+use std::collections::HashSet;
+
+use crate::device::device_from_code;
+use crate::error::FfiError;
+use crate::{inference_error, input_strings, load_error, sentence_embeddings};
+
+/// Options for `new_keyword_extraction_model`, mirrored by the Go `KeywordExtractionConfig`.
+#[repr(C)]
+pub struct KeywordOptions {
+    /// Sentence embeddings model kind, see `sentence_embeddings::model_type`
+    pub embeddings_model: i32,
+    /// Device code, see `device_from_code`
+    pub device: i32,
+    pub ngram_min: i64,
+    pub ngram_max: i64,
+    pub max_keywords: i64,
+    /// 0 cosine similarity, 1 maximal marginal relevance, 2 max sum
+    pub scorer: i32,
+    /// Diversity of the maximal marginal relevance scorer, between 0 and 1
+    pub diversity: f64,
+    /// Number of candidates the max sum scorer picks keywords from
+    pub max_sum_candidates: i64,
+    /// Stopwords replacing the built-in English list when `custom_stopwords` is set
+    pub stopwords: *const *const c_char,
+    pub stopwords_count: size_t,
+    pub custom_stopwords: bool,
+}
+
+pub struct KeywordExtractor {
+    // Declared first so that it is dropped before the stopwords it borrows.
+    model: KeywordExtractionModel<'static>,
+    _stopwords: Vec<String>,
+}
+
+impl KeywordExtractor {
+    pub fn new(options: *const KeywordOptions) -> Result<KeywordExtractor, FfiError> {
+        let options = unsafe { options.as_ref() }
+            .ok_or_else(|| FfiError::invalid_input("keyword extraction options are NULL"))?;
+
+        let ngram_min = positive(options.ngram_min, "minimum n-gram length")?;
+        let ngram_max = positive(options.ngram_max, "maximum n-gram length")?;
+        if ngram_min > ngram_max {
+            return Err(FfiError::invalid_input(format!(
+                "minimum n-gram length {} is larger than the maximum {}",
+                ngram_min, ngram_max
+            )));
+        }
+        let num_keywords = positive(options.max_keywords, "maximum number of keywords")?;
+        let (scorer_type, diversity, max_sum_candidates) = match options.scorer {
+            0 => (KeywordScorerType::CosineSimilarity, None, None),
+            1 => {
+                if !(0.0..=1.0).contains(&options.diversity) {
+                    return Err(FfiError::invalid_input(format!(
+                        "diversity must be between 0 and 1, got {}",
+                        options.diversity
+                    )));
+                }
+                (KeywordScorerType::MaximalMarginRelevance, Some(options.diversity), None)
+            }
+            2 => {
+                let candidates = positive(options.max_sum_candidates, "number of max sum candidates")?;
+                if candidates < num_keywords {
+                    return Err(FfiError::invalid_input(format!(
+                        "max sum needs at least as many candidates ({}) as keywords ({})",
+                        candidates, num_keywords
+                    )));
+                }
+                (KeywordScorerType::MaxSum, None, Some(candidates))
+            }
+            other => return Err(FfiError::invalid_input(format!("unknown keyword scorer {}", other))),
+        };
+
+        let stopwords = if options.custom_stopwords {
+            input_strings(options.stopwords, options.stopwords_count, "stopword")?
+        } else {
+            Vec::new()
+        };
+        // The strings are owned by the extractor and never modified, so their contents
+        // outlive the model built from them.
+        let tokenizer_stopwords = options.custom_stopwords.then(|| {
+            stopwords
+                .iter()
+                .map(|s| unsafe { &*(s.as_str() as *const str) })
+                .collect::<HashSet<&'static str>>()
+        });
+
+        let mut sentence_embeddings_config =
+            SentenceEmbeddingsConfig::from(sentence_embeddings::model_type(options.embeddings_model)?);
+        sentence_embeddings_config.device = device_from_code(options.device)?;
+
+        let config = KeywordExtractionConfig {
+            sentence_embeddings_config,
+            tokenizer_stopwords,
+            scorer_type,
+            ngram_range: (ngram_min, ngram_max),
+            num_keywords,
+            diversity,
+            max_sum_candidates,
+            ..Default::default()
+        };
+        Ok(KeywordExtractor {
+            model: KeywordExtractionModel::new(config).map_err(load_error)?,
+            _stopwords: stopwords,
+        })
+    }
+
+    /// Extracts the keywords of each text, best first.
+    pub fn extract<S>(&self, texts: &[S]) -> Result<Vec<Vec<Keyword>>, FfiError>
+    where
+        S: AsRef<str> + Sync,
+    {
+        self.model.predict(texts).map_err(inference_error)
+    }
+}
+
+fn positive(value: i64, name: &str) -> Result<usize, FfiError> {
+    if value < 1 {
+        return Err(FfiError::invalid_input(format!("{} must be at least 1, got {}", name, value)));
+    }
+    Ok(value as usize)
+}
@@ -1,18 +1,63 @@
 //! FFI bindings for rust-bert, exposing C-compatible functions for Go integration.
 
+mod cancel;
+mod conversation;
+mod device;
+mod error;
+mod generation;
+mod encoding;
+mod keywords;
+mod masked_language;
+mod question_answering;
+mod sentence_embeddings;
+mod sequence_classification;
+mod summarization;
+mod text_generation;
+mod threads;
+mod token_classification;
+mod tokenizer;
+mod translation;
+mod windows;
+mod zero_shot;
+
+use cancel::{check_cancelled, run_batched, run_in_batches, CancelToken};
+use device::device_from_code;
+use error::{ffi_call, warn, ErrorCode, FfiError};
+use generation::{seeded, GenerateOptions};
+use keywords::{KeywordExtractor, KeywordOptions};
+use masked_language::{FillMask, MaskCandidate};
+use question_answering::{QAModelOptions, QAOptions};
+use sentence_embeddings::{EmbeddingsOptions, SentenceEmbedder};
+use sequence_classification::{Classifier, LabelScore};
+use summarization::{preset_config, LongSummarizeOptions, SummarizeOverrides, Summarizer};
+use text_generation::TextGenerator;
+use token_classification::{TokenClassificationOptions, TokenTagger};
+use tokenizer::{truncation_strategy, Encoding, Tokenizer};
+use translation::{parse_language, Translator};
+use windows::{aggregate_sentiments, merge_entities, WindowOptions, Windower};
+use zero_shot::{ZeroShotOptions, ZeroShotSettings};
 use libc::{c_char, size_t};
-use rust_bert::pipelines::common::ModelType;
-use rust_bert::pipelines::ner::NERModel;
-use rust_bert::pipelines::token_classification::TokenClassificationConfig;
-use rust_bert::pipelines::pos_tagging::{POSModel, POSConfig};
-use rust_bert::pipelines::question_answering::{QaInput, QuestionAnsweringModel, QuestionAnsweringConfig};
-use rust_bert::pipelines::sentiment::{SentimentModel, SentimentPolarity, SentimentConfig};
-use rust_bert::pipelines::summarization::{SummarizationModel, SummarizationConfig};
-use rust_bert::pipelines::text_generation::{TextGenerationModel, TextGenerationConfig};
-use rust_bert::pipelines::translation::{TranslationModel, TranslationModelBuilder, Language};
+use rust_bert::pipelines::common::{ModelResource, ModelType};
+use rust_bert::pipelines::conversation::{ConversationConfig, ConversationManager, ConversationModel};
+use rust_bert::pipelines::keywords_extraction::Keyword;
+use rust_bert::pipelines::masked_language::MaskedLanguageConfig;
+use rust_bert::pipelines::ner::{Entity as NerEntity, NERModel};
+use rust_bert::pipelines::token_classification::{LabelAggregationOption, Token, TokenClassificationConfig};
+use rust_bert::pipelines::pos_tagging::{POSModel, POSConfig, POSTag as PosTag};
+use rust_bert::pipelines::question_answering::{Answer, QaInput, QuestionAnsweringModel, QuestionAnsweringConfig};
+use rust_bert::pipelines::sentiment::{Sentiment, SentimentModel, SentimentPolarity, SentimentConfig};
+use rust_bert::pipelines::sequence_classification::{Label, SequenceClassificationConfig};
+use rust_bert::pipelines::summarization::SummarizationConfig;
+use rust_bert::pipelines::text_generation::TextGenerationConfig;
+use rust_bert::pipelines::translation::{TranslationConfig, TranslationModel, TranslationModelBuilder, Language};
 use rust_bert::pipelines::zero_shot_classification::{ZeroShotClassificationModel, ZeroShotClassificationConfig};
+use rust_bert::resources::LocalResource;
+use rust_bert::RustBertError;
 use std::ffi::{CStr, CString};
+use std::fs;
+use std::path::{Path, PathBuf};
 use std::ptr;
+use std::sync::atomic::{AtomicI32, Ordering};
 
 // ============================================================================
 // FFI Structs - All must use #[repr(C)] to match Go CGO definitions
@@ -22,6 +67,8 @@ use std::ptr;
 #[repr(C)]
 pub struct SentimentModelWrapper {
     model: *mut SentimentModel,
+    /// Splits long inputs with the tokenizer of the model
+    windower: *mut Windower,
 }
 
 /// Result of sentiment analysis
@@ -31,6 +78,13 @@ pub struct SentimentResult {
     pub score: f32,
 }
 
+/// Results of batched sentiment analysis, one per input text
+#[repr(C)]
+pub struct SentimentBatchResult {
+    pub results: *mut SentimentResult,
+    pub count: size_t,
+}
+
 /// Wrapper for POSModel
 #[repr(C)]
 pub struct POSModelWrapper {
@@ -52,10 +106,19 @@ pub struct POSResult {
     pub count: size_t,
 }
 
+/// Results of batched POS tagging, one per input text
+#[repr(C)]
+pub struct POSBatchResult {
+    pub results: *mut POSResult,
+    pub count: size_t,
+}
+
 /// Wrapper for NERModel
 #[repr(C)]
 pub struct NERModelWrapper {
     model: *mut NERModel,
+    /// Splits long inputs with the tokenizer of the model
+    windower: *mut Windower,
 }
 
 /// Single named entity
@@ -75,10 +138,19 @@ pub struct NERResult {
     pub count: size_t,
 }
 
+/// Results of batched NER, one per input text
+#[repr(C)]
+pub struct NERBatchResult {
+    pub results: *mut NERResult,
+    pub count: size_t,
+}
+
 /// Wrapper for QuestionAnsweringModel
 #[repr(C)]
 pub struct QAModelWrapper {
     model: *mut QuestionAnsweringModel,
+    /// Options of the calls that do not pass their own
+    options: QAOptions,
 }
 
 /// Single QA answer
@@ -97,10 +169,19 @@ pub struct QAResult {
     pub count: size_t,
 }
 
+/// Results of batched QA, one per question/context pair
+#[repr(C)]
+pub struct QABatchResult {
+    pub results: *mut QAResult,
+    pub count: size_t,
+}
+
 /// Wrapper for SummarizationModel
 #[repr(C)]
 pub struct SummarizationModelWrapper {
-    model: *mut SummarizationModel,
+    model: *mut Summarizer,
+    /// Seed applied before every call, negative if unset
+    seed: i64,
 }
 
 /// Result of summarization
@@ -110,6 +191,15 @@ pub struct SummarizationResult {
     pub count: size_t,
 }
 
+/// Result of hierarchical summarization
+#[repr(C)]
+pub struct LongSummaryResult {
+    pub summary: *mut c_char,
+    /// Summaries of the chunks of the input, in order
+    pub chunk_summaries: *mut *mut c_char,
+    pub chunk_count: size_t,
+}
+
 /// Wrapper for ZeroShotClassificationModel
 #[repr(C)]
 pub struct ZeroShotClassificationModelWrapper {
@@ -130,16 +220,243 @@ pub struct ZeroShotResult {
     pub count: size_t,
 }
 
+/// Results of batched zero-shot classification, one per input text
+#[repr(C)]
+pub struct ZeroShotBatchResult {
+    pub results: *mut ZeroShotResult,
+    pub count: size_t,
+}
+
 /// Wrapper for TranslationModel
 #[repr(C)]
 pub struct TranslationModelWrapper {
-    model: *mut TranslationModel,
+    model: *mut Translator,
 }
 
-/// Wrapper for TextGenerationModel
+/// Wrapper for the text generation model
 #[repr(C)]
 pub struct TextGenerationModelWrapper {
-    model: *mut TextGenerationModel,
+    model: *mut TextGenerator,
+    /// Seed applied before every call, negative if unset
+    seed: i64,
+}
+
+/// Wrapper for SentenceEmbeddingsModel
+#[repr(C)]
+pub struct SentenceEmbeddingsModelWrapper {
+    model: *mut SentenceEmbedder,
+}
+
+/// Embeddings of a batch of sentences, stored row-major in one buffer of `count * dim` floats
+#[repr(C)]
+pub struct EmbeddingsResult {
+    pub data: *mut f32,
+    pub count: size_t,
+    pub dim: size_t,
+}
+
+/// Wrapper for KeywordExtractionModel
+#[repr(C)]
+pub struct KeywordExtractionModelWrapper {
+    model: *mut KeywordExtractor,
+}
+
+/// Character offsets of one occurrence of a keyword
+#[repr(C)]
+pub struct KeywordOffset {
+    pub begin: size_t,
+    pub end: size_t,
+}
+
+/// Single extracted keyword
+#[repr(C)]
+pub struct KeywordItem {
+    pub text: *mut c_char,
+    pub score: f32,
+    pub offsets: *mut KeywordOffset,
+    pub offsets_count: size_t,
+}
+
+/// Keywords of one text, best first
+#[repr(C)]
+pub struct KeywordResult {
+    pub keywords: *mut KeywordItem,
+    pub count: size_t,
+}
+
+/// Results of batched keyword extraction, one per input text
+#[repr(C)]
+pub struct KeywordBatchResult {
+    pub results: *mut KeywordResult,
+    pub count: size_t,
+}
+
+/// Wrapper for the fill-mask model
+#[repr(C)]
+pub struct MaskedLanguageModelWrapper {
+    model: *mut FillMask,
+}
+
+/// Candidate token for a mask
+#[repr(C)]
+pub struct MaskCandidateItem {
+    pub token: *mut c_char,
+    pub id: i64,
+    pub score: f32,
+}
+
+/// Candidates of one mask, most likely first
+#[repr(C)]
+pub struct MaskPrediction {
+    pub candidates: *mut MaskCandidateItem,
+    pub count: size_t,
+}
+
+/// Predictions of every mask of one text, in order of appearance
+#[repr(C)]
+pub struct FillMaskResult {
+    pub masks: *mut MaskPrediction,
+    pub count: size_t,
+}
+
+/// Results of batched fill-mask, one per input text
+#[repr(C)]
+pub struct FillMaskBatchResult {
+    pub results: *mut FillMaskResult,
+    pub count: size_t,
+}
+
+/// Wrapper for the sequence classification model
+#[repr(C)]
+pub struct SequenceClassificationModelWrapper {
+    model: *mut Classifier,
+}
+
+/// Score of one label
+#[repr(C)]
+pub struct ClassificationLabel {
+    pub label: *mut c_char,
+    pub id: i64,
+    pub score: f32,
+}
+
+/// Scores of every label for one text, best first
+#[repr(C)]
+pub struct ClassificationResult {
+    pub labels: *mut ClassificationLabel,
+    pub count: size_t,
+}
+
+/// Results of batched sequence classification, one per input text
+#[repr(C)]
+pub struct ClassificationBatchResult {
+    pub results: *mut ClassificationResult,
+    pub count: size_t,
+}
+
+/// Wrapper for the generic token classification model
+#[repr(C)]
+pub struct TokenClassificationModelWrapper {
+    model: *mut TokenTagger,
+}
+
+/// Label of one token, or of one word when sub-tokens are aggregated
+#[repr(C)]
+pub struct TokenItem {
+    pub text: *mut c_char,
+    pub label: *mut c_char,
+    pub label_index: i64,
+    pub score: f32,
+    pub sentence: size_t,
+    pub offset_begin: size_t,
+    pub offset_end: size_t,
+}
+
+/// Labelled tokens of one text, in order of appearance
+#[repr(C)]
+pub struct TokenClassificationResult {
+    pub tokens: *mut TokenItem,
+    pub count: size_t,
+}
+
+/// Results of batched token classification, one per input text
+#[repr(C)]
+pub struct TokenClassificationBatchResult {
+    pub results: *mut TokenClassificationResult,
+    pub count: size_t,
+}
+
+/// Wrapper for ConversationModel
+#[repr(C)]
+pub struct ConversationModelWrapper {
+    model: *mut ConversationModel,
+    /// Seed applied before every call, negative if unset
+    seed: i64,
+}
+
+/// Wrapper for ConversationManager
+#[repr(C)]
+pub struct ConversationManagerWrapper {
+    manager: *mut ConversationManager,
+}
+
+/// Exchanges of one conversation, oldest first, and the user input awaiting a response
+#[repr(C)]
+pub struct ConversationHistory {
+    pub user_inputs: *mut *mut c_char,
+    pub responses: *mut *mut c_char,
+    pub count: size_t,
+    /// NULL when every user input has been answered
+    pub pending_input: *mut c_char,
+}
+
+/// Response generated for one conversation
+#[repr(C)]
+pub struct ConversationResponse {
+    pub id: *mut c_char,
+    pub text: *mut c_char,
+}
+
+/// Responses of one generation round, one per conversation that had a pending input
+#[repr(C)]
+pub struct ConversationResponses {
+    pub responses: *mut ConversationResponse,
+    pub count: size_t,
+}
+
+/// Wrapper for a standalone tokenizer
+#[repr(C)]
+pub struct TokenizerWrapper {
+    tokenizer: *mut Tokenizer,
+}
+
+/// Token of an encoded input
+#[repr(C)]
+pub struct EncodedTokenItem {
+    pub id: i64,
+    pub token: *mut c_char,
+    /// Character span in the input, 0..0 for special tokens
+    pub offset_begin: size_t,
+    pub offset_end: size_t,
+    pub special: bool,
+    pub segment: i8,
+}
+
+/// Tokens of an encoded input or pair of inputs
+#[repr(C)]
+pub struct EncodingResult {
+    pub tokens: *mut EncodedTokenItem,
+    pub count: size_t,
+    pub num_truncated: size_t,
+    pub overflowing: *mut i64,
+    pub overflowing_count: size_t,
+}
+
+/// Array of strings, used for batched translation and generation outputs
+#[repr(C)]
+pub struct StringArray {
+    pub items: *mut *mut c_char,
+    pub count: size_t,
 }
 
 // ============================================================================
@@ -159,57 +476,377 @@ fn string_to_cstr(s: &str) -> *mut c_char {
         .unwrap_or(ptr::null_mut())
 }
 
-#[allow(dead_code)]
-fn model_type_from_int(t: i32) -> ModelType {
+/// Reads a required string argument.
+fn input_string(s: *const c_char, name: &str) -> Result<String, FfiError> {
+    cstr_to_string(s).ok_or_else(|| FfiError::invalid_input(format!("{} is NULL or not valid UTF-8", name)))
+}
+
+/// Reads a required array of strings.
+fn input_strings(items: *const *const c_char, count: size_t, name: &str) -> Result<Vec<String>, FfiError> {
+    if items.is_null() && count > 0 {
+        return Err(FfiError::invalid_input(format!("{} is NULL", name)));
+    }
+    (0..count)
+        .map(|i| input_string(unsafe { *items.add(i) }, name))
+        .collect()
+}
+
+/// Hands a vector over to C as a pointer and length. Empty vectors become NULL.
+fn into_raw_parts<T>(items: Vec<T>) -> (*mut T, size_t) {
+    if items.is_empty() {
+        return (ptr::null_mut(), 0);
+    }
+    let count = items.len();
+    (Box::into_raw(items.into_boxed_slice()) as *mut T, count)
+}
+
+/// Takes back ownership of a pointer and length produced by `into_raw_parts`.
+///
+/// # Safety
+/// `items` and `count` must come from `into_raw_parts` and not have been freed yet.
+unsafe fn from_raw_parts<T>(items: *mut T, count: size_t) -> Vec<T> {
+    if items.is_null() || count == 0 {
+        return Vec::new();
+    }
+    Vec::from_raw_parts(items, count, count)
+}
+
+fn free_cstr(s: *mut c_char) {
+    if !s.is_null() {
+        unsafe {
+            drop(CString::from_raw(s));
+        }
+    }
+}
+
+/// Dereferences a wrapper handle passed in from Go.
+fn handle<'a, T>(wrapper: *mut T) -> Result<&'a T, FfiError> {
+    unsafe { wrapper.as_ref() }.ok_or_else(|| FfiError::invalid_input("model handle is NULL"))
+}
+
+fn load_error(err: RustBertError) -> FfiError {
+    FfiError::from_rust_bert(err, ErrorCode::ModelLoad)
+}
+
+fn inference_error(err: RustBertError) -> FfiError {
+    FfiError::from_rust_bert(err, ErrorCode::Inference)
+}
+
+fn model_type_from_int(t: i32) -> Option<ModelType> {
     match t {
-        0 => ModelType::Bert,
-        1 => ModelType::DistilBert,
-        2 => ModelType::Roberta,
-        3 => ModelType::XLMRoberta,
-        4 => ModelType::Electra,
-        5 => ModelType::Albert,
-        6 => ModelType::XLNet,
-        7 => ModelType::Bart,
-        8 => ModelType::Marian,
-        9 => ModelType::T5,
-        10 => ModelType::GPT2,
-        _ => ModelType::Bert,
+        0 => Some(ModelType::Bert),
+        1 => Some(ModelType::DistilBert),
+        2 => Some(ModelType::Roberta),
+        3 => Some(ModelType::XLMRoberta),
+        4 => Some(ModelType::Electra),
+        5 => Some(ModelType::Albert),
+        6 => Some(ModelType::XLNet),
+        7 => Some(ModelType::Bart),
+        8 => Some(ModelType::Marian),
+        9 => Some(ModelType::T5),
+        10 => Some(ModelType::GPT2),
+        11 => Some(ModelType::Pegasus),
+        12 => Some(ModelType::ProphetNet),
+        13 => Some(ModelType::LongT5),
+        14 => Some(ModelType::M2M100),
+        15 => Some(ModelType::MBart),
+        16 => Some(ModelType::NLLB),
+        _ => None,
+    }
+}
+
+/// Values of the `model_type` field in a Hugging Face `config.json` that are
+/// compatible with the given rust-bert model type.
+fn config_model_type_names(model_type: ModelType) -> &'static [&'static str] {
+    match model_type {
+        ModelType::Bert => &["bert"],
+        ModelType::DistilBert => &["distilbert"],
+        ModelType::Roberta => &["roberta"],
+        ModelType::XLMRoberta => &["xlm-roberta"],
+        ModelType::Electra => &["electra"],
+        ModelType::Albert => &["albert"],
+        ModelType::XLNet => &["xlnet"],
+        ModelType::Bart => &["bart"],
+        ModelType::Marian => &["marian"],
+        ModelType::T5 => &["t5"],
+        ModelType::GPT2 => &["gpt2"],
+        ModelType::Pegasus => &["pegasus"],
+        ModelType::ProphetNet => &["prophetnet"],
+        ModelType::LongT5 => &["longt5"],
+        ModelType::M2M100 | ModelType::NLLB => &["m2m_100"],
+        ModelType::MBart => &["mbart"],
+        _ => &[],
+    }
+}
+
+/// Whether the tokenizer of the given model type needs a second resource
+/// (BPE merges, the SentencePiece model for Marian and M2M100, or the special
+/// tokens map for NLLB).
+fn requires_merges(model_type: ModelType) -> bool {
+    matches!(
+        model_type,
+        ModelType::Roberta
+            | ModelType::Bart
+            | ModelType::Marian
+            | ModelType::GPT2
+            | ModelType::M2M100
+            | ModelType::NLLB
+    )
+}
+
+/// Casing of the checkpoints whose files do not tell, set by `set_default_casing`: 0 to
+/// assume cased with a warning, 1 for cased and 2 for uncased, as the Go `Casing`.
+static DEFAULT_CASING: AtomicI32 = AtomicI32::new(0);
+
+/// Sets the casing assumed for the checkpoints whose files do not tell whether they
+/// are cased; 0 restores the default of cased with a warning.
+#[no_mangle]
+pub extern "C" fn set_default_casing(code: i32) -> bool {
+    ffi_call(false, || {
+        if !(0..=2).contains(&code) {
+            return Err(FfiError::invalid_input(format!("unknown casing {}", code)));
+        }
+        DEFAULT_CASING.store(code, Ordering::Relaxed);
+        Ok(true)
+    })
+}
+
+/// Tokenizer flags read from the `tokenizer_config.json` stored next to the vocabulary.
+struct TokenizerFlags {
+    lower_case: bool,
+    strip_accents: Option<bool>,
+    add_prefix_space: Option<bool>,
+}
+
+impl TokenizerFlags {
+    /// Reads the flags of the tokenizer of `vocab_path`. Lower casing not set by
+    /// `tokenizer_config.json` follows the model type when all its checkpoints agree, and
+    /// `config_path` otherwise. A checkpoint that says nothing about it gets the casing
+    /// of `set_default_casing`, or is assumed cased, as rust-bert's pipelines do, with a
+    /// warning.
+    fn from_files(vocab_path: &Path, config_path: Option<&Path>, model_type: ModelType) -> TokenizerFlags {
+        let config = read_json(vocab_path.parent().map(|dir| dir.join("tokenizer_config.json")));
+        let flag = |name: &str| config.as_ref().and_then(|c| c.get(name)).and_then(|v| v.as_bool());
+
+        let lower_case = flag("do_lower_case")
+            .or_else(|| lower_case_convention(model_type))
+            .or_else(|| lower_case_from_config(read_json(config_path.map(Path::to_path_buf))))
+            .unwrap_or_else(|| match DEFAULT_CASING.load(Ordering::Relaxed) {
+                1 => false,
+                2 => true,
+                _ => {
+                    warn(format!(
+                        "cannot tell whether the {:?} checkpoint of {} is cased, assuming it is: add a tokenizer_config.json setting do_lower_case next to it or set the default casing",
+                        model_type,
+                        vocab_path.display()
+                    ));
+                    false
+                }
+            });
+        TokenizerFlags {
+            lower_case,
+            strip_accents: flag("strip_accents"),
+            add_prefix_space: flag("add_prefix_space"),
+        }
+    }
+}
+
+/// Parses the JSON file at `path`, if there is one.
+fn read_json(path: Option<PathBuf>) -> Option<serde_json::Value> {
+    let data = fs::read_to_string(path?).ok()?;
+    serde_json::from_str(&data).ok()
+}
+
+/// Lower casing shared by all checkpoints of a model type. BERT, DistilBERT and ELECTRA
+/// come both cased and uncased.
+fn lower_case_convention(model_type: ModelType) -> Option<bool> {
+    match model_type {
+        ModelType::Bert | ModelType::DistilBert | ModelType::Electra => None,
+        ModelType::Albert | ModelType::ProphetNet => Some(true),
+        _ => Some(false),
+    }
+}
+
+/// Lower casing declared by a model config.json, either directly or by the name of the
+/// checkpoint it was saved from ("bert-base-uncased").
+fn lower_case_from_config(config: Option<serde_json::Value>) -> Option<bool> {
+    let config = config?;
+    if let Some(lower_case) = config.get("do_lower_case").and_then(|v| v.as_bool()) {
+        return Some(lower_case);
+    }
+    let name = config.get("_name_or_path").and_then(|v| v.as_str())?.to_lowercase();
+    if name.contains("uncased") {
+        Some(true)
+    } else if name.contains("cased") {
+        Some(false)
+    } else {
+        None
+    }
+}
+
+/// Model artifacts stored on the local filesystem, as passed to the `*_from_files` constructors.
+struct LocalModelFiles {
+    model_type: ModelType,
+    model: LocalResource,
+    config: LocalResource,
+    vocab: LocalResource,
+    merges: Option<LocalResource>,
+    tokenizer: TokenizerFlags,
+}
+
+impl LocalModelFiles {
+    /// Validates the FFI arguments: every required path must point to an existing file,
+    /// the model type must be known and must match the `model_type` declared in config.json.
+    fn from_ffi(
+        model_path: *const c_char,
+        config_path: *const c_char,
+        vocab_path: *const c_char,
+        merges_path: *const c_char,
+        model_type: i32,
+    ) -> Result<LocalModelFiles, FfiError> {
+        let model_type = model_type_from_int(model_type)
+            .ok_or_else(|| FfiError::invalid_input(format!("unknown model type {}", model_type)))?;
+
+        let model = local_file(model_path, "model")?;
+        let config = local_file(config_path, "config")?;
+        let vocab = local_file(vocab_path, "vocab")?;
+        let merges = if merges_path.is_null() {
+            None
+        } else {
+            Some(local_file(merges_path, "merges")?)
+        };
+        if merges.is_none() && requires_merges(model_type) {
+            return Err(FfiError::invalid_input(format!(
+                "model type {:?} requires a merges file",
+                model_type
+            )));
+        }
+
+        check_config_model_type(&config, model_type)?;
+
+        Ok(LocalModelFiles {
+            model_type,
+            tokenizer: TokenizerFlags::from_files(&vocab, Some(config.as_path()), model_type),
+            model: LocalResource { local_path: model },
+            config: LocalResource { local_path: config },
+            vocab: LocalResource { local_path: vocab },
+            merges: merges.map(|local_path| LocalResource { local_path }),
+        })
+    }
+
+    fn model_resource(&self) -> ModelResource {
+        ModelResource::Torch(Box::new(self.model.clone()))
+    }
+}
+
+fn local_file(path: *const c_char, what: &str) -> Result<PathBuf, FfiError> {
+    let path = PathBuf::from(input_string(path, &format!("{} path", what))?);
+    if !path.is_file() {
+        return Err(FfiError::model_load(format!("{} file not found: {}", what, path.display())));
+    }
+    Ok(path)
+}
+
+fn check_config_model_type(config_path: &Path, model_type: ModelType) -> Result<(), FfiError> {
+    let data = fs::read_to_string(config_path)
+        .map_err(|e| FfiError::model_load(format!("failed to read {}: {}", config_path.display(), e)))?;
+    let config: serde_json::Value = serde_json::from_str(&data)
+        .map_err(|e| FfiError::model_load(format!("failed to parse {}: {}", config_path.display(), e)))?;
+
+    let declared = match config.get("model_type").and_then(|v| v.as_str()) {
+        Some(declared) => declared,
+        None => return Ok(()),
+    };
+    let expected = config_model_type_names(model_type);
+    if expected.is_empty() || expected.contains(&declared) {
+        Ok(())
+    } else {
+        Err(FfiError::model_load(format!(
+            "config declares model_type \"{}\" which is incompatible with requested model type {:?}",
+            declared, model_type
+        )))
+    }
+}
+
+/// Free a string returned by the library
+#[no_mangle]
+pub extern "C" fn free_string(s: *mut c_char) {
+    free_cstr(s);
+}
+
+/// Free a string array returned by the library
+#[no_mangle]
+pub extern "C" fn free_string_array(array: *mut StringArray) {
+    if !array.is_null() {
+        unsafe {
+            let a = Box::from_raw(array);
+            for s in from_raw_parts(a.items, a.count) {
+                free_cstr(s);
+            }
+        }
     }
 }
 
+fn string_array(strings: &[String]) -> *mut StringArray {
+    let (items, count) = into_raw_parts(strings.iter().map(|s| string_to_cstr(s)).collect());
+    Box::into_raw(Box::new(StringArray { items, count }))
+}
+
 // ============================================================================
 // Sentiment Analysis FFI Functions
 // ============================================================================
 
 /// Create a new sentiment model with default configuration (DistilBERT SST-2)
 #[no_mangle]
-pub extern "C" fn new_sentiment_model() -> *mut SentimentModelWrapper {
-    match SentimentModel::new(SentimentConfig::default()) {
-        Ok(model) => {
-            let wrapper = SentimentModelWrapper {
-                model: Box::into_raw(Box::new(model)),
-            };
-            Box::into_raw(Box::new(wrapper))
-        }
-        Err(e) => {
-            eprintln!("Failed to create sentiment model: {:?}", e);
-            ptr::null_mut()
-        }
-    }
+pub extern "C" fn new_sentiment_model(device: i32) -> *mut SentimentModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        Ok(Box::into_raw(Box::new(sentiment_wrapper(SentimentConfig::default(), device)?)))
+    })
 }
 
 /// Create a sentiment model from custom files
 #[no_mangle]
 pub extern "C" fn new_sentiment_model_from_files(
-    _model_path: *const c_char,
-    _config_path: *const c_char,
-    _vocab_path: *const c_char,
-    _merges_path: *const c_char,
-    _model_type: i32,
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    device: i32,
 ) -> *mut SentimentModelWrapper {
-    // Custom model loading requires more complex config setup
-    // For now, fall back to default model
-    new_sentiment_model()
+    ffi_call(ptr::null_mut(), || {
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        let config = SentimentConfig::new(
+            files.model_type,
+            files.model_resource(),
+            files.config,
+            files.vocab,
+            files.merges,
+            files.tokenizer.lower_case,
+            files.tokenizer.strip_accents,
+            files.tokenizer.add_prefix_space,
+        );
+        Ok(Box::into_raw(Box::new(sentiment_wrapper(config, device)?)))
+    })
+}
+
+/// Loads the sentiment model of `config` along with its tokenizer for long inputs.
+fn sentiment_wrapper(mut config: SentimentConfig, device: i32) -> Result<SentimentModelWrapper, FfiError> {
+    config.device = device_from_code(device)?;
+    let windower = Windower::new(
+        config.model_type,
+        &*config.vocab_resource,
+        config.merges_resource.as_deref(),
+        config.lower_case,
+        config.strip_accents,
+        config.add_prefix_space,
+    )
+    .map_err(load_error)?;
+    let model = SentimentModel::new(config).map_err(load_error)?;
+    Ok(SentimentModelWrapper {
+        model: Box::into_raw(Box::new(model)),
+        windower: Box::into_raw(Box::new(windower)),
+    })
 }
 
 /// Predict sentiment for the given text
@@ -218,31 +855,70 @@ pub extern "C" fn predict_sentiment(
     wrapper: *mut SentimentModelWrapper,
     text: *const c_char,
 ) -> *mut SentimentResult {
-    if wrapper.is_null() || text.is_null() {
-        return ptr::null_mut();
-    }
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let text_str = input_string(text, "text")?;
+
+        let sentiment = model
+            .predict(&[text_str.as_str()])
+            .into_iter()
+            .next()
+            .ok_or_else(|| FfiError::new(ErrorCode::Inference, "model returned no prediction"))?;
+        Ok(Box::into_raw(Box::new(sentiment_result(&sentiment))))
+    })
+}
 
-    let text_str = match cstr_to_string(text) {
-        Some(s) => s,
-        None => return ptr::null_mut(),
-    };
+/// Predict sentiment for a batch of texts in a single forward pass
+#[no_mangle]
+pub extern "C" fn predict_sentiment_batch(
+    wrapper: *mut SentimentModelWrapper,
+    texts: *const *const c_char,
+    texts_count: size_t,
+    cancel: *const CancelToken,
+) -> *mut SentimentBatchResult {
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let texts_vec = input_strings(texts, texts_count, "text")?;
+        let texts_refs: Vec<&str> = texts_vec.iter().map(|s| s.as_str()).collect();
+
+        let sentiments = run_batched(cancel, &texts_refs, |chunk| Ok(model.predict(chunk)))?;
+        let (results, count) = into_raw_parts(sentiments.iter().map(sentiment_result).collect());
+        Ok(Box::into_raw(Box::new(SentimentBatchResult { results, count })))
+    })
+}
 
-    unsafe {
-        let model = &*(*wrapper).model;
-        match model.predict(&[text_str.as_str()]).first() {
-            Some(sentiment) => {
-                let label = match sentiment.polarity {
-                    SentimentPolarity::Positive => "POSITIVE",
-                    SentimentPolarity::Negative => "NEGATIVE",
-                };
-                let result = SentimentResult {
-                    label: string_to_cstr(label),
-                    score: sentiment.score as f32,
-                };
-                Box::into_raw(Box::new(result))
-            }
-            None => ptr::null_mut(),
-        }
+/// Predict the sentiment of a text longer than the model accepts by running it in
+/// overlapping windows and aggregating their scores
+#[no_mangle]
+pub extern "C" fn predict_sentiment_long(
+    wrapper: *mut SentimentModelWrapper,
+    text: *const c_char,
+    options: *const WindowOptions,
+    cancel: *const CancelToken,
+) -> *mut SentimentResult {
+    ffi_call(ptr::null_mut(), || {
+        let wrapper = handle(wrapper)?;
+        let (model, windower) = unsafe { (&*wrapper.model, &*wrapper.windower) };
+        let text_str = input_string(text, "text")?;
+        let options = WindowOptions::from_ptr(options)?;
+
+        let windows = windower.split(&text_str, &options);
+        let texts: Vec<&str> = windows.iter().map(|w| w.text.as_str()).collect();
+        let sentiments = run_batched(cancel, &texts, |chunk| Ok(model.predict(chunk)))?;
+        let sentiment = aggregate_sentiments(sentiments, options.aggregation)
+            .ok_or_else(|| FfiError::new(ErrorCode::Inference, "model returned no prediction"))?;
+        Ok(Box::into_raw(Box::new(sentiment_result(&sentiment))))
+    })
+}
+
+fn sentiment_result(sentiment: &Sentiment) -> SentimentResult {
+    let label = match sentiment.polarity {
+        SentimentPolarity::Positive => "POSITIVE",
+        SentimentPolarity::Negative => "NEGATIVE",
+    };
+    SentimentResult {
+        label: string_to_cstr(label),
+        score: sentiment.score as f32,
     }
 }
 
@@ -255,6 +931,9 @@ pub extern "C" fn free_sentiment_model(wrapper: *mut SentimentModelWrapper) {
             if !w.model.is_null() {
                 drop(Box::from_raw(w.model));
             }
+            if !w.windower.is_null() {
+                drop(Box::from_raw(w.windower));
+            }
         }
     }
 }
@@ -265,8 +944,19 @@ pub extern "C" fn free_sentiment_result(result: *mut SentimentResult) {
     if !result.is_null() {
         unsafe {
             let r = Box::from_raw(result);
-            if !r.label.is_null() {
-                drop(CString::from_raw(r.label));
+            free_cstr(r.label);
+        }
+    }
+}
+
+/// Free a batched sentiment result
+#[no_mangle]
+pub extern "C" fn free_sentiment_batch_result(result: *mut SentimentBatchResult) {
+    if !result.is_null() {
+        unsafe {
+            let r = Box::from_raw(result);
+            for item in from_raw_parts(r.results, r.count) {
+                free_cstr(item.label);
             }
         }
     }
@@ -278,19 +968,18 @@ pub extern "C" fn free_sentiment_result(result: *mut SentimentResult) {
 
 /// Create a new POS model with default configuration
 #[no_mangle]
-pub extern "C" fn new_pos_model() -> *mut POSModelWrapper {
-    match POSModel::new(POSConfig::default()) {
-        Ok(model) => {
-            let wrapper = POSModelWrapper {
-                model: Box::into_raw(Box::new(model)),
-            };
-            Box::into_raw(Box::new(wrapper))
-        }
-        Err(e) => {
-            eprintln!("Failed to create POS model: {:?}", e);
-            ptr::null_mut()
-        }
-    }
+pub extern "C" fn new_pos_model(device: i32) -> *mut POSModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let config = POSConfig {
+            device: device_from_code(device)?,
+            ..Default::default()
+        };
+        let model = POSModel::new(config).map_err(load_error)?;
+        let wrapper = POSModelWrapper {
+            model: Box::into_raw(Box::new(model)),
+        };
+        Ok(Box::into_raw(Box::new(wrapper)))
+    })
 }
 
 /// Predict POS tags for the given text
@@ -299,50 +988,1381 @@ pub extern "C" fn predict_pos(
     wrapper: *mut POSModelWrapper,
     text: *const c_char,
 ) -> *mut POSResult {
-    if wrapper.is_null() || text.is_null() {
-        return ptr::null_mut();
-    }
-
-    let text_str = match cstr_to_string(text) {
-        Some(s) => s,
-        None => return ptr::null_mut(),
-    };
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let text_str = input_string(text, "text")?;
 
-    unsafe {
-        let model = &*(*wrapper).model;
         let results = model.predict(&[text_str.as_str()]);
+        let tags = results.first().map(|tags| tags.as_slice()).unwrap_or_default();
+        Ok(Box::into_raw(Box::new(pos_result(tags))))
+    })
+}
 
-        if results.is_empty() || results[0].is_empty() {
-            let result = POSResult {
-                tags: ptr::null_mut(),
-                count: 0,
-            };
-            return Box::into_raw(Box::new(result));
+/// Predict POS tags for a batch of texts in a single forward pass
+#[no_mangle]
+pub extern "C" fn predict_pos_batch(
+    wrapper: *mut POSModelWrapper,
+    texts: *const *const c_char,
+    texts_count: size_t,
+    cancel: *const CancelToken,
+) -> *mut POSBatchResult {
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let texts_vec = input_strings(texts, texts_count, "text")?;
+        let texts_refs: Vec<&str> = texts_vec.iter().map(|s| s.as_str()).collect();
+
+        let tags = run_batched(cancel, &texts_refs, |chunk| Ok(model.predict(chunk)))?;
+        let (results, count) = into_raw_parts(tags.iter().map(|t| pos_result(t)).collect());
+        Ok(Box::into_raw(Box::new(POSBatchResult { results, count })))
+    })
+}
+
+fn pos_result(tags: &[PosTag]) -> POSResult {
+    let (tags, count) = into_raw_parts(
+        tags.iter()
+            .map(|tag| POSTag {
+                word: string_to_cstr(&tag.word),
+                score: tag.score as f32,
+                label: string_to_cstr(&tag.label),
+            })
+            .collect(),
+    );
+    POSResult { tags, count }
+}
+
+/// Free a POS model
+#[no_mangle]
+pub extern "C" fn free_pos_model(wrapper: *mut POSModelWrapper) {
+    if !wrapper.is_null() {
+        unsafe {
+            let w = Box::from_raw(wrapper);
+            if !w.model.is_null() {
+                drop(Box::from_raw(w.model));
+            }
+        }
+    }
+}
+
+/// Free a POS result
+#[no_mangle]
+pub extern "C" fn free_pos_result(result: *mut POSResult) {
+    if !result.is_null() {
+        unsafe {
+            free_pos_tags(&Box::from_raw(result));
+        }
+    }
+}
+
+/// Free a batched POS result
+#[no_mangle]
+pub extern "C" fn free_pos_batch_result(result: *mut POSBatchResult) {
+    if !result.is_null() {
+        unsafe {
+            let r = Box::from_raw(result);
+            for item in from_raw_parts(r.results, r.count) {
+                free_pos_tags(&item);
+            }
+        }
+    }
+}
+
+unsafe fn free_pos_tags(result: &POSResult) {
+    for tag in from_raw_parts(result.tags, result.count) {
+        free_cstr(tag.word);
+        free_cstr(tag.label);
+    }
+}
+
+// ============================================================================
+// NER FFI Functions
+// ============================================================================
+
+/// Create a new NER model with default configuration
+#[no_mangle]
+pub extern "C" fn new_ner_model(device: i32) -> *mut NERModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        Ok(Box::into_raw(Box::new(ner_wrapper(TokenClassificationConfig::default(), device)?)))
+    })
+}
+
+/// Create a NER model from custom files
+#[no_mangle]
+pub extern "C" fn new_ner_model_from_files(
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    device: i32,
+) -> *mut NERModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        let config = TokenClassificationConfig::new(
+            files.model_type,
+            files.model_resource(),
+            files.config,
+            files.vocab,
+            files.merges,
+            files.tokenizer.lower_case,
+            files.tokenizer.strip_accents,
+            files.tokenizer.add_prefix_space,
+            LabelAggregationOption::First,
+        );
+        Ok(Box::into_raw(Box::new(ner_wrapper(config, device)?)))
+    })
+}
+
+/// Loads the NER model of `config` along with its tokenizer for long inputs.
+fn ner_wrapper(mut config: TokenClassificationConfig, device: i32) -> Result<NERModelWrapper, FfiError> {
+    config.device = device_from_code(device)?;
+    let windower = Windower::new(
+        config.model_type,
+        &*config.vocab_resource,
+        config.merges_resource.as_deref(),
+        config.lower_case,
+        config.strip_accents,
+        config.add_prefix_space,
+    )
+    .map_err(load_error)?;
+    let model = NERModel::new(config).map_err(load_error)?;
+    Ok(NERModelWrapper {
+        model: Box::into_raw(Box::new(model)),
+        windower: Box::into_raw(Box::new(windower)),
+    })
+}
+
+/// Predict NER entities for the given text
+#[no_mangle]
+pub extern "C" fn predict_ner(
+    wrapper: *mut NERModelWrapper,
+    text: *const c_char,
+) -> *mut NERResult {
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let text_str = input_string(text, "text")?;
+
+        let results = model.predict(&[text_str.as_str()]);
+        let entities = results.first().map(|e| e.as_slice()).unwrap_or_default();
+        Ok(Box::into_raw(Box::new(ner_result(entities))))
+    })
+}
+
+/// Predict NER entities for a batch of texts in a single forward pass
+#[no_mangle]
+pub extern "C" fn predict_ner_batch(
+    wrapper: *mut NERModelWrapper,
+    texts: *const *const c_char,
+    texts_count: size_t,
+    cancel: *const CancelToken,
+) -> *mut NERBatchResult {
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let texts_vec = input_strings(texts, texts_count, "text")?;
+        let texts_refs: Vec<&str> = texts_vec.iter().map(|s| s.as_str()).collect();
+
+        let entities = run_batched(cancel, &texts_refs, |chunk| Ok(model.predict(chunk)))?;
+        let (results, count) = into_raw_parts(entities.iter().map(|e| ner_result(e)).collect());
+        Ok(Box::into_raw(Box::new(NERBatchResult { results, count })))
+    })
+}
+
+/// Predict the NER entities of a text longer than the model accepts by running it in
+/// overlapping windows, with offsets relative to the whole text
+#[no_mangle]
+pub extern "C" fn predict_ner_long(
+    wrapper: *mut NERModelWrapper,
+    text: *const c_char,
+    options: *const WindowOptions,
+    cancel: *const CancelToken,
+) -> *mut NERResult {
+    ffi_call(ptr::null_mut(), || {
+        let wrapper = handle(wrapper)?;
+        let (model, windower) = unsafe { (&*wrapper.model, &*wrapper.windower) };
+        let text_str = input_string(text, "text")?;
+        let options = WindowOptions::from_ptr(options)?;
+
+        let windows = windower.split(&text_str, &options);
+        let texts: Vec<&str> = windows.iter().map(|w| w.text.as_str()).collect();
+        let entities = run_batched(cancel, &texts, |chunk| Ok(model.predict(chunk)))?;
+        Ok(Box::into_raw(Box::new(ner_result(&merge_entities(&windows, entities)))))
+    })
+}
+
+fn ner_result(entities: &[NerEntity]) -> NERResult {
+    let (entities, count) = into_raw_parts(
+        entities
+            .iter()
+            .map(|ent| Entity {
+                word: string_to_cstr(&ent.word),
+                score: ent.score as f32,
+                label: string_to_cstr(&ent.label),
+                offset_begin: ent.offset.begin as usize,
+                offset_end: ent.offset.end as usize,
+            })
+            .collect(),
+    );
+    NERResult { entities, count }
+}
+
+/// Free a NER model
+#[no_mangle]
+pub extern "C" fn free_ner_model(wrapper: *mut NERModelWrapper) {
+    if !wrapper.is_null() {
+        unsafe {
+            let w = Box::from_raw(wrapper);
+            if !w.model.is_null() {
+                drop(Box::from_raw(w.model));
+            }
+            if !w.windower.is_null() {
+                drop(Box::from_raw(w.windower));
+            }
+        }
+    }
+}
+
+/// Free a NER result
+#[no_mangle]
+pub extern "C" fn free_ner_result(result: *mut NERResult) {
+    if !result.is_null() {
+        unsafe {
+            free_ner_entities(&Box::from_raw(result));
+        }
+    }
+}
+
+/// Free a batched NER result
+#[no_mangle]
+pub extern "C" fn free_ner_batch_result(result: *mut NERBatchResult) {
+    if !result.is_null() {
+        unsafe {
+            let r = Box::from_raw(result);
+            for item in from_raw_parts(r.results, r.count) {
+                free_ner_entities(&item);
+            }
+        }
+    }
+}
+
+unsafe fn free_ner_entities(result: &NERResult) {
+    for ent in from_raw_parts(result.entities, result.count) {
+        free_cstr(ent.word);
+        free_cstr(ent.label);
+    }
+}
+
+// ============================================================================
+// Question Answering FFI Functions
+// ============================================================================
+
+/// Create a new QA model with default configuration
+#[no_mangle]
+pub extern "C" fn new_qa_model(device: i32) -> *mut QAModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        qa_wrapper(QuestionAnsweringConfig::default(), QAOptions::default(), device)
+    })
+}
+
+/// Create a QA model with the default checkpoint and the given options
+#[no_mangle]
+pub extern "C" fn new_qa_model_with_options(options: *const QAModelOptions, device: i32) -> *mut QAModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let mut config = QuestionAnsweringConfig::default();
+        let defaults = QAModelOptions::apply(options, &mut config)?;
+        qa_wrapper(config, defaults, device)
+    })
+}
+
+/// Create a QA model from custom files
+#[no_mangle]
+pub extern "C" fn new_qa_model_from_files(
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    device: i32,
+) -> *mut QAModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        qa_wrapper(qa_config(files), QAOptions::default(), device)
+    })
+}
+
+/// Create a QA model from custom files with the given options
+#[no_mangle]
+pub extern "C" fn new_qa_model_from_files_with_options(
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    options: *const QAModelOptions,
+    device: i32,
+) -> *mut QAModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        let mut config = qa_config(files);
+        let defaults = QAModelOptions::apply(options, &mut config)?;
+        qa_wrapper(config, defaults, device)
+    })
+}
+
+fn qa_config(files: LocalModelFiles) -> QuestionAnsweringConfig {
+    QuestionAnsweringConfig::new(
+        files.model_type,
+        files.model_resource(),
+        files.config,
+        files.vocab,
+        files.merges,
+        files.tokenizer.lower_case,
+        files.tokenizer.strip_accents,
+        files.tokenizer.add_prefix_space,
+    )
+}
+
+fn qa_wrapper(mut config: QuestionAnsweringConfig, options: QAOptions, device: i32) -> Result<*mut QAModelWrapper, FfiError> {
+    config.device = device_from_code(device)?;
+    let model = QuestionAnsweringModel::new(config).map_err(load_error)?;
+    let wrapper = QAModelWrapper {
+        model: Box::into_raw(Box::new(model)),
+        options,
+    };
+    Ok(Box::into_raw(Box::new(wrapper)))
+}
+
+/// Predict answers for the given question and context
+#[no_mangle]
+pub extern "C" fn predict_qa(
+    wrapper: *mut QAModelWrapper,
+    question: *const c_char,
+    context: *const c_char,
+) -> *mut QAResult {
+    ffi_call(ptr::null_mut(), || {
+        let wrapper = handle(wrapper)?;
+        let model = unsafe { &*wrapper.model };
+        let qa_input = QaInput {
+            question: input_string(question, "question")?,
+            context: input_string(context, "context")?,
+        };
+
+        let results = question_answering::answer(model, &[qa_input], &wrapper.options);
+        let answers = results.first().map(|a| a.as_slice()).unwrap_or_default();
+        Ok(Box::into_raw(Box::new(qa_result(answers))))
+    })
+}
+
+/// Predict answers for a batch of question/context pairs.
+/// `questions` and `contexts` are parallel arrays of `count` elements.
+#[no_mangle]
+pub extern "C" fn predict_qa_batch(
+    wrapper: *mut QAModelWrapper,
+    questions: *const *const c_char,
+    contexts: *const *const c_char,
+    count: size_t,
+    cancel: *const CancelToken,
+) -> *mut QABatchResult {
+    predict_qa_batch_with_options(wrapper, questions, contexts, count, ptr::null(), cancel)
+}
+
+/// Predict answers for a batch of question/context pairs with per-call options.
+/// `options` may be NULL to use the options the model was created with.
+#[no_mangle]
+pub extern "C" fn predict_qa_batch_with_options(
+    wrapper: *mut QAModelWrapper,
+    questions: *const *const c_char,
+    contexts: *const *const c_char,
+    count: size_t,
+    options: *const QAOptions,
+    cancel: *const CancelToken,
+) -> *mut QABatchResult {
+    ffi_call(ptr::null_mut(), || {
+        let wrapper = handle(wrapper)?;
+        let model = unsafe { &*wrapper.model };
+        let options = QAOptions::from_ptr(options, wrapper.options)?;
+        let qa_inputs: Vec<QaInput> = input_strings(questions, count, "question")?
+            .into_iter()
+            .zip(input_strings(contexts, count, "context")?)
+            .map(|(question, context)| QaInput { question, context })
+            .collect();
+
+        let answers = run_in_batches(cancel, &qa_inputs, question_answering::BATCH_SIZE, |batch| {
+            Ok(question_answering::answer(model, batch, &options))
+        })?;
+        let (results, count) = into_raw_parts(answers.iter().map(|a| qa_result(a)).collect());
+        Ok(Box::into_raw(Box::new(QABatchResult { results, count })))
+    })
+}
+
+fn qa_result(answers: &[Answer]) -> QAResult {
+    let (answers, count) = into_raw_parts(
+        answers
+            .iter()
+            .map(|ans| QAAnswer {
+                score: ans.score as f32,
+                start: ans.start,
+                end: ans.end,
+                answer: string_to_cstr(&ans.answer),
+            })
+            .collect(),
+    );
+    QAResult { answers, count }
+}
+
+/// Free a QA model
+#[no_mangle]
+pub extern "C" fn free_qa_model(wrapper: *mut QAModelWrapper) {
+    if !wrapper.is_null() {
+        unsafe {
+            let w = Box::from_raw(wrapper);
+            if !w.model.is_null() {
+                drop(Box::from_raw(w.model));
+            }
+        }
+    }
+}
+
+/// Free a QA result
+#[no_mangle]
+pub extern "C" fn free_qa_result(result: *mut QAResult) {
+    if !result.is_null() {
+        unsafe {
+            free_qa_answers(&Box::from_raw(result));
+        }
+    }
+}
+
+/// Free a batched QA result
+#[no_mangle]
+pub extern "C" fn free_qa_batch_result(result: *mut QABatchResult) {
+    if !result.is_null() {
+        unsafe {
+            let r = Box::from_raw(result);
+            for item in from_raw_parts(r.results, r.count) {
+                free_qa_answers(&item);
+            }
+        }
+    }
+}
+
+unsafe fn free_qa_answers(result: &QAResult) {
+    for ans in from_raw_parts(result.answers, result.count) {
+        free_cstr(ans.answer);
+    }
+}
+
+// ============================================================================
+// Summarization FFI Functions
+// ============================================================================
+
+/// Create a new summarization model with default configuration
+#[no_mangle]
+pub extern "C" fn new_summarization_model(device: i32) -> *mut SummarizationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        summarization_wrapper(SummarizationConfig::default(), -1, device)
+    })
+}
+
+/// Create a summarization model for one of the pretrained checkpoints selectable from Go
+/// (`kind`), generating with the given options
+#[no_mangle]
+pub extern "C" fn new_summarization_model_with_config(
+    kind: i32,
+    options: *const GenerateOptions,
+    device: i32,
+) -> *mut SummarizationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let options = GenerateOptions::from_ptr(options)?;
+        let mut config = preset_config(kind)?;
+        options.apply_to_summarization(&mut config);
+        summarization_wrapper(config, options.seed, device)
+    })
+}
+
+/// Create a summarization model from custom files
+#[no_mangle]
+pub extern "C" fn new_summarization_model_from_files(
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    device: i32,
+) -> *mut SummarizationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        let config = SummarizationConfig::new(
+            files.model_type,
+            files.model_resource(),
+            files.config,
+            files.vocab,
+            files.merges,
+        );
+        summarization_wrapper(config, -1, device)
+    })
+}
+
+/// Create a summarization model from custom files with the given generation options
+#[no_mangle]
+pub extern "C" fn new_summarization_model_from_files_with_config(
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    options: *const GenerateOptions,
+    device: i32,
+) -> *mut SummarizationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let options = GenerateOptions::from_ptr(options)?;
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        let mut config = SummarizationConfig::new(
+            files.model_type,
+            files.model_resource(),
+            files.config,
+            files.vocab,
+            files.merges,
+        );
+        options.apply_to_summarization(&mut config);
+        summarization_wrapper(config, options.seed, device)
+    })
+}
+
+fn summarization_wrapper(
+    mut config: SummarizationConfig,
+    seed: i64,
+    device: i32,
+) -> Result<*mut SummarizationModelWrapper, FfiError> {
+    config.device = device_from_code(device)?;
+    let model = Summarizer::new(config)?;
+    let wrapper = SummarizationModelWrapper {
+        model: Box::into_raw(Box::new(model)),
+        seed,
+    };
+    Ok(Box::into_raw(Box::new(wrapper)))
+}
+
+/// Summarize the given text
+#[no_mangle]
+pub extern "C" fn summarize(
+    wrapper: *mut SummarizationModelWrapper,
+    text: *const c_char,
+) -> *mut SummarizationResult {
+    ffi_call(ptr::null_mut(), || {
+        let wrapper = handle(wrapper)?;
+        let model = unsafe { &*wrapper.model };
+        let text_str = input_string(text, "text")?;
+
+        let summaries = seeded(wrapper.seed, || model.summarize(&[text_str.as_str()], None, ptr::null()))?;
+        let (summaries, count) = into_raw_parts(summaries.iter().map(|s| string_to_cstr(s)).collect());
+        Ok(Box::into_raw(Box::new(SummarizationResult { summaries, count })))
+    })
+}
+
+/// Summarize a batch of texts, producing one summary per input (`num_return_sequences` per
+/// input if set). `overrides` may be NULL to use the model's generation settings.
+#[no_mangle]
+pub extern "C" fn summarize_batch(
+    wrapper: *mut SummarizationModelWrapper,
+    texts: *const *const c_char,
+    texts_count: size_t,
+    overrides: *const SummarizeOverrides,
+    cancel: *const CancelToken,
+) -> *mut SummarizationResult {
+    ffi_call(ptr::null_mut(), || {
+        let wrapper = handle(wrapper)?;
+        let model = unsafe { &*wrapper.model };
+        let texts_vec = input_strings(texts, texts_count, "text")?;
+        let overrides = SummarizeOverrides::from_ptr(overrides)?;
+
+        let summaries = seeded(wrapper.seed, || {
+            model.summarize(&texts_vec, overrides.map(|o| o.call_options()), cancel)
+        })?;
+        let (summaries, count) = into_raw_parts(summaries.iter().map(|s| string_to_cstr(s)).collect());
+        Ok(Box::into_raw(Box::new(SummarizationResult { summaries, count })))
+    })
+}
+
+/// Summarize a text longer than the model input by summarizing its chunks, then the
+/// concatenation of their summaries, until the summary fits `options.target_length`
+/// tokens. `overrides` may be NULL to use the model's generation settings.
+#[no_mangle]
+pub extern "C" fn summarize_long(
+    wrapper: *mut SummarizationModelWrapper,
+    text: *const c_char,
+    options: *const LongSummarizeOptions,
+    overrides: *const SummarizeOverrides,
+    cancel: *const CancelToken,
+) -> *mut LongSummaryResult {
+    ffi_call(ptr::null_mut(), || {
+        let wrapper = handle(wrapper)?;
+        let model = unsafe { &*wrapper.model };
+        let text_str = input_string(text, "text")?;
+        let options = LongSummarizeOptions::from_ptr(options)?;
+        let overrides = SummarizeOverrides::from_ptr(overrides)?;
+
+        let summary = seeded(wrapper.seed, || model.summarize_long(&text_str, &options, overrides, cancel))?;
+        let (chunk_summaries, chunk_count) =
+            into_raw_parts(summary.chunk_summaries.iter().map(|s| string_to_cstr(s)).collect());
+        Ok(Box::into_raw(Box::new(LongSummaryResult {
+            summary: string_to_cstr(&summary.summary),
+            chunk_summaries,
+            chunk_count,
+        })))
+    })
+}
+
+/// Free a summarization model
+#[no_mangle]
+pub extern "C" fn free_summarization_model(wrapper: *mut SummarizationModelWrapper) {
+    if !wrapper.is_null() {
+        unsafe {
+            let w = Box::from_raw(wrapper);
+            if !w.model.is_null() {
+                drop(Box::from_raw(w.model));
+            }
+        }
+    }
+}
+
+/// Free a summarization result
+#[no_mangle]
+pub extern "C" fn free_summarization_result(result: *mut SummarizationResult) {
+    if !result.is_null() {
+        unsafe {
+            let r = Box::from_raw(result);
+            for s in from_raw_parts(r.summaries, r.count) {
+                free_cstr(s);
+            }
+        }
+    }
+}
+
+/// Free a hierarchical summarization result
+#[no_mangle]
+pub extern "C" fn free_long_summary_result(result: *mut LongSummaryResult) {
+    if !result.is_null() {
+        unsafe {
+            let r = Box::from_raw(result);
+            free_cstr(r.summary);
+            for s in from_raw_parts(r.chunk_summaries, r.chunk_count) {
+                free_cstr(s);
+            }
+        }
+    }
+}
+
+// ============================================================================
+// Zero-Shot Classification FFI Functions
+// ============================================================================
+
+/// Create a new zero-shot classification model with default configuration
+#[no_mangle]
+pub extern "C" fn new_zero_shot_model(device: i32) -> *mut ZeroShotClassificationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let config = ZeroShotClassificationConfig {
+            device: device_from_code(device)?,
+            ..Default::default()
+        };
+        let model = ZeroShotClassificationModel::new(config).map_err(load_error)?;
+        let wrapper = ZeroShotClassificationModelWrapper {
+            model: Box::into_raw(Box::new(model)),
+        };
+        Ok(Box::into_raw(Box::new(wrapper)))
+    })
+}
+
+/// Create a zero-shot model from custom files
+#[no_mangle]
+pub extern "C" fn new_zero_shot_model_from_files(
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    device: i32,
+) -> *mut ZeroShotClassificationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        let mut config = ZeroShotClassificationConfig::new(
+            files.model_type,
+            files.model_resource(),
+            files.config,
+            files.vocab,
+            files.merges,
+            files.tokenizer.lower_case,
+            files.tokenizer.strip_accents,
+            files.tokenizer.add_prefix_space,
+        );
+        config.device = device_from_code(device)?;
+        let model = ZeroShotClassificationModel::new(config).map_err(load_error)?;
+        let wrapper = ZeroShotClassificationModelWrapper {
+            model: Box::into_raw(Box::new(model)),
+        };
+        Ok(Box::into_raw(Box::new(wrapper)))
+    })
+}
+
+/// Predict zero-shot classification for the given text and labels
+#[no_mangle]
+pub extern "C" fn predict_zero_shot(
+    wrapper: *mut ZeroShotClassificationModelWrapper,
+    text: *const c_char,
+    labels: *const *const c_char,
+    labels_count: size_t,
+) -> *mut ZeroShotResult {
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let text_str = input_string(text, "text")?;
+        let labels_vec = zero_shot_labels(labels, labels_count)?;
+        let labels_refs: Vec<&str> = labels_vec.iter().map(|s| s.as_str()).collect();
+
+        let results = model
+            .predict(&[text_str.as_str()], labels_refs.as_slice(), None, zero_shot::MAX_LENGTH)
+            .map_err(inference_error)?;
+        Ok(Box::into_raw(Box::new(zero_shot_result(&results))))
+    })
+}
+
+/// Predict zero-shot classification for a batch of texts against the same labels.
+/// Each result holds the best label for the corresponding text.
+#[no_mangle]
+pub extern "C" fn predict_zero_shot_batch(
+    wrapper: *mut ZeroShotClassificationModelWrapper,
+    texts: *const *const c_char,
+    texts_count: size_t,
+    labels: *const *const c_char,
+    labels_count: size_t,
+    cancel: *const CancelToken,
+) -> *mut ZeroShotBatchResult {
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let texts_vec = input_strings(texts, texts_count, "text")?;
+        let texts_refs: Vec<&str> = texts_vec.iter().map(|s| s.as_str()).collect();
+        let labels_vec = zero_shot_labels(labels, labels_count)?;
+        let labels_refs: Vec<&str> = labels_vec.iter().map(|s| s.as_str()).collect();
+
+        let best = run_batched(cancel, &texts_refs, |chunk| {
+            model
+                .predict(chunk, labels_refs.as_slice(), None, zero_shot::MAX_LENGTH)
+                .map_err(inference_error)
+        })?;
+        let (results, count) = into_raw_parts(
+            best.iter()
+                .map(|label| zero_shot_result(std::slice::from_ref(label)))
+                .collect(),
+        );
+        Ok(Box::into_raw(Box::new(ZeroShotBatchResult { results, count })))
+    })
+}
+
+/// Classify a batch of texts against the same labels with a custom hypothesis template,
+/// max length and label mode. Each result holds the best label of its text, or every
+/// label best first when `options.multi_label` is set.
+#[no_mangle]
+pub extern "C" fn predict_zero_shot_with_options(
+    wrapper: *mut ZeroShotClassificationModelWrapper,
+    texts: *const *const c_char,
+    texts_count: size_t,
+    labels: *const *const c_char,
+    labels_count: size_t,
+    options: *const ZeroShotOptions,
+    cancel: *const CancelToken,
+) -> *mut ZeroShotBatchResult {
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let texts_vec = input_strings(texts, texts_count, "text")?;
+        let texts_refs: Vec<&str> = texts_vec.iter().map(|s| s.as_str()).collect();
+        let labels_vec = zero_shot_labels(labels, labels_count)?;
+        let labels_refs: Vec<&str> = labels_vec.iter().map(|s| s.as_str()).collect();
+        let settings = ZeroShotSettings::from_ptr(options)?;
+
+        let scores = run_batched(cancel, &texts_refs, |chunk| {
+            settings
+                .classify(model, chunk, &labels_refs)
+                .map_err(inference_error)
+        })?;
+        let (results, count) = into_raw_parts(scores.iter().map(|labels| zero_shot_result(labels)).collect());
+        Ok(Box::into_raw(Box::new(ZeroShotBatchResult { results, count })))
+    })
+}
+
+fn zero_shot_labels(labels: *const *const c_char, labels_count: size_t) -> Result<Vec<String>, FfiError> {
+    if labels.is_null() || labels_count == 0 {
+        return Err(FfiError::invalid_input("labels cannot be empty"));
+    }
+    input_strings(labels, labels_count, "label")
+}
+
+fn zero_shot_result(labels: &[Label]) -> ZeroShotResult {
+    let (labels, count) = into_raw_parts(
+        labels
+            .iter()
+            .map(|label| ZeroShotLabel {
+                text: string_to_cstr(&label.text),
+                score: label.score,
+            })
+            .collect(),
+    );
+    ZeroShotResult { labels, count }
+}
+
+/// Free a zero-shot model
+#[no_mangle]
+pub extern "C" fn free_zero_shot_model(wrapper: *mut ZeroShotClassificationModelWrapper) {
+    if !wrapper.is_null() {
+        unsafe {
+            let w = Box::from_raw(wrapper);
+            if !w.model.is_null() {
+                drop(Box::from_raw(w.model));
+            }
+        }
+    }
+}
+
+/// Free a zero-shot result
+#[no_mangle]
+pub extern "C" fn free_zero_shot_result(result: *mut ZeroShotResult) {
+    if !result.is_null() {
+        unsafe {
+            free_zero_shot_labels(&Box::from_raw(result));
+        }
+    }
+}
+
+/// Free a batched zero-shot result
+#[no_mangle]
+pub extern "C" fn free_zero_shot_batch_result(result: *mut ZeroShotBatchResult) {
+    if !result.is_null() {
+        unsafe {
+            let r = Box::from_raw(result);
+            for item in from_raw_parts(r.results, r.count) {
+                free_zero_shot_labels(&item);
+            }
+        }
+    }
+}
+
+unsafe fn free_zero_shot_labels(result: &ZeroShotResult) {
+    for label in from_raw_parts(result.labels, result.count) {
+        free_cstr(label.text);
+    }
+}
+
+// ============================================================================
+// Translation FFI Functions
+// ============================================================================
+
+/// Create a new translation model with default configuration (English to French, Spanish,
+/// Italian and Portuguese)
+#[no_mangle]
+pub extern "C" fn new_translation_model(device: i32) -> *mut TranslationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let sources = vec![Language::English];
+        let targets = vec![Language::French, Language::Spanish, Language::Italian, Language::Portuguese];
+        let model = TranslationModelBuilder::new()
+            .with_source_languages(sources.clone())
+            .with_target_languages(targets.clone())
+            .with_device(device_from_code(device)?)
+            .create_model()
+            .map_err(load_error)?;
+        translation_wrapper(model, sources, targets, false)
+    })
+}
+
+/// Create a translation model through rust-bert's `TranslationModelBuilder`.
+/// `model_type` is -1 to let the builder pick (Marian when a checkpoint covers all pairs,
+/// M2M100 otherwise). Languages are names or ISO codes; an empty list leaves them to the
+/// builder. `device` is decoded by `device_from_code`.
+#[no_mangle]
+pub extern "C" fn new_translation_model_with_builder(
+    model_type: i32,
+    source_languages: *const *const c_char,
+    source_count: size_t,
+    target_languages: *const *const c_char,
+    target_count: size_t,
+    device: i32,
+) -> *mut TranslationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let sources = input_languages(source_languages, source_count, "source language")?;
+        let targets = input_languages(target_languages, target_count, "target language")?;
+
+        let mut builder = TranslationModelBuilder::new();
+        builder.with_device(device_from_code(device)?);
+        if model_type >= 0 {
+            match model_type_from_int(model_type) {
+                Some(t @ (ModelType::Marian | ModelType::M2M100 | ModelType::MBart | ModelType::NLLB)) => {
+                    builder.with_model_type(t);
+                }
+                _ => {
+                    return Err(FfiError::invalid_input(format!(
+                        "model type {} cannot be used for translation (expected Marian, M2M100, MBart or NLLB)",
+                        model_type
+                    )))
+                }
+            }
+        }
+        if !sources.is_empty() {
+            builder.with_source_languages(sources.clone());
+        }
+        if !targets.is_empty() {
+            builder.with_target_languages(targets.clone());
+        }
+        let model = builder.create_model().map_err(load_error)?;
+        translation_wrapper(model, sources, targets, false)
+    })
+}
+
+/// Create a translation model from custom files.
+/// The language pair is implied by the checkpoint, so no source or target language is registered.
+#[no_mangle]
+pub extern "C" fn new_translation_model_from_files(
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    device: i32,
+) -> *mut TranslationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        let config = TranslationConfig::new(
+            files.model_type,
+            files.model_resource(),
+            files.config,
+            files.vocab,
+            files.merges,
+            Vec::<Language>::new(),
+            Vec::<Language>::new(),
+            device_from_code(device)?,
+        );
+        let model = TranslationModel::new(config).map_err(load_error)?;
+        translation_wrapper(model, Vec::new(), Vec::new(), true)
+    })
+}
+
+fn input_languages(items: *const *const c_char, count: size_t, name: &str) -> Result<Vec<Language>, FfiError> {
+    input_strings(items, count, name)?
+        .iter()
+        .map(|language| parse_language(language))
+        .collect()
+}
+
+fn translation_wrapper(
+    model: TranslationModel,
+    source_languages: Vec<Language>,
+    target_languages: Vec<Language>,
+    fixed_pair: bool,
+) -> Result<*mut TranslationModelWrapper, FfiError> {
+    let translator = Translator {
+        model,
+        source_languages,
+        target_languages,
+        fixed_pair,
+    };
+    let wrapper = TranslationModelWrapper {
+        model: Box::into_raw(Box::new(translator)),
+    };
+    Ok(Box::into_raw(Box::new(wrapper)))
+}
+
+/// Translate the given text between the given languages (names or ISO codes; NULL or empty
+/// for the model default). The returned string must be released with `free_string`.
+#[no_mangle]
+pub extern "C" fn translate(
+    wrapper: *mut TranslationModelWrapper,
+    text: *const c_char,
+    source_lang: *const c_char,
+    target_lang: *const c_char,
+) -> *mut c_char {
+    ffi_call(ptr::null_mut(), || {
+        let translator = unsafe { &*handle(wrapper)?.model };
+        let text_str = input_string(text, "text")?;
+        let source = cstr_to_string(source_lang);
+        let target = cstr_to_string(target_lang);
+
+        let results = translator.translate(&[text_str.as_str()], source.as_deref(), target.as_deref())?;
+        match results.first() {
+            Some(translation) => Ok(string_to_cstr(translation)),
+            None => Err(FfiError::new(ErrorCode::Inference, "model returned no translation")),
+        }
+    })
+}
+
+/// Translate a batch of texts. The result must be released with `free_string_array`.
+/// The token is checked before translation starts; rust-bert's `TranslationModel` cannot
+/// resume a partial output, so a started batch cannot be interrupted.
+#[no_mangle]
+pub extern "C" fn translate_batch(
+    wrapper: *mut TranslationModelWrapper,
+    texts: *const *const c_char,
+    texts_count: size_t,
+    source_lang: *const c_char,
+    target_lang: *const c_char,
+    cancel: *const CancelToken,
+) -> *mut StringArray {
+    ffi_call(ptr::null_mut(), || {
+        let translator = unsafe { &*handle(wrapper)?.model };
+        let texts_vec = input_strings(texts, texts_count, "text")?;
+        let source = cstr_to_string(source_lang);
+        let target = cstr_to_string(target_lang);
+
+        let translations = run_batched(cancel, &texts_vec, |chunk| {
+            translator.translate(chunk, source.as_deref(), target.as_deref())
+        })?;
+        Ok(string_array(&translations))
+    })
+}
+
+/// Free a translation model
+#[no_mangle]
+pub extern "C" fn free_translation_model(wrapper: *mut TranslationModelWrapper) {
+    if !wrapper.is_null() {
+        unsafe {
+            let w = Box::from_raw(wrapper);
+            if !w.model.is_null() {
+                drop(Box::from_raw(w.model));
+            }
+        }
+    }
+}
+
+// ============================================================================
+// Text Generation FFI Functions
+// ============================================================================
+
+/// Create a new text generation model with default configuration (GPT-2)
+#[no_mangle]
+pub extern "C" fn new_text_generation_model(device: i32) -> *mut TextGenerationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        text_generation_wrapper(TextGenerationConfig::default(), -1, device)
+    })
+}
+
+/// Create a default text generation model (GPT-2) with the given generation options
+#[no_mangle]
+pub extern "C" fn new_text_generation_model_with_options(
+    options: *const GenerateOptions,
+    device: i32,
+) -> *mut TextGenerationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let options = GenerateOptions::from_ptr(options)?;
+        let mut config = TextGenerationConfig::default();
+        options.apply_to_text_generation(&mut config);
+        text_generation_wrapper(config, options.seed, device)
+    })
+}
+
+/// Create a text generation model from custom files
+#[no_mangle]
+pub extern "C" fn new_text_generation_model_from_files(
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    device: i32,
+) -> *mut TextGenerationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        let config = TextGenerationConfig::new(
+            files.model_type,
+            files.model_resource(),
+            files.config,
+            files.vocab,
+            files.merges,
+        );
+        text_generation_wrapper(config, -1, device)
+    })
+}
+
+/// Create a text generation model from custom files with the given generation options
+#[no_mangle]
+pub extern "C" fn new_text_generation_model_from_files_with_options(
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    options: *const GenerateOptions,
+    device: i32,
+) -> *mut TextGenerationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let options = GenerateOptions::from_ptr(options)?;
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        let mut config = TextGenerationConfig::new(
+            files.model_type,
+            files.model_resource(),
+            files.config,
+            files.vocab,
+            files.merges,
+        );
+        options.apply_to_text_generation(&mut config);
+        text_generation_wrapper(config, options.seed, device)
+    })
+}
+
+fn text_generation_wrapper(
+    mut config: TextGenerationConfig,
+    seed: i64,
+    device: i32,
+) -> Result<*mut TextGenerationModelWrapper, FfiError> {
+    config.device = device_from_code(device)?;
+    let model = TextGenerator::new(config)?;
+    let wrapper = TextGenerationModelWrapper {
+        model: Box::into_raw(Box::new(model)),
+        seed,
+    };
+    Ok(Box::into_raw(Box::new(wrapper)))
+}
+
+/// Generate text from the given prompt. The returned string must be released with `free_string`.
+#[no_mangle]
+pub extern "C" fn generate_text(
+    wrapper: *mut TextGenerationModelWrapper,
+    prompt: *const c_char,
+    prefix: *const c_char,
+) -> *mut c_char {
+    ffi_call(ptr::null_mut(), || {
+        let wrapper = handle(wrapper)?;
+        let model = unsafe { &*wrapper.model };
+        let prompt_str = input_string(prompt, "prompt")?;
+        let prefix_opt = cstr_to_string(prefix);
+
+        let results = seeded(wrapper.seed, || {
+            model.generate(&[prompt_str.as_str()], prefix_opt.as_deref(), ptr::null())
+        })?;
+        match results.first() {
+            Some(generated) => Ok(string_to_cstr(generated)),
+            None => Err(FfiError::new(ErrorCode::Inference, "model returned no text")),
+        }
+    })
+}
+
+/// Generate text for a batch of prompts. With `num_return_sequences` = n the result holds n
+/// sequences per prompt, prompt-major. It must be released with `free_string_array`.
+#[no_mangle]
+pub extern "C" fn generate_text_batch(
+    wrapper: *mut TextGenerationModelWrapper,
+    prompts: *const *const c_char,
+    prompts_count: size_t,
+    prefix: *const c_char,
+    cancel: *const CancelToken,
+) -> *mut StringArray {
+    ffi_call(ptr::null_mut(), || {
+        let wrapper = handle(wrapper)?;
+        let model = unsafe { &*wrapper.model };
+        let prompts_vec = input_strings(prompts, prompts_count, "prompt")?;
+        let prefix_opt = cstr_to_string(prefix);
+
+        let generated = seeded(wrapper.seed, || model.generate(&prompts_vec, prefix_opt.as_deref(), cancel))?;
+        Ok(string_array(&generated))
+    })
+}
+
+/// Called by `generate_text_stream` with each piece of generated text, valid for the
+/// duration of the call. Returning false stops generation.
+pub type TextCallback = extern "C" fn(user_data: usize, text: *const c_char) -> bool;
+
+/// Generate text from the given prompt token by token, passing the text added by each
+/// step to `callback` along with `user_data`. `options` override the sampling settings
+/// of the model for this call; beam search and T5 models cannot be streamed. The token is
+/// checked on every step.
+#[no_mangle]
+pub extern "C" fn generate_text_stream(
+    wrapper: *mut TextGenerationModelWrapper,
+    prompt: *const c_char,
+    options: *const GenerateOptions,
+    callback: Option<TextCallback>,
+    user_data: usize,
+    cancel: *const CancelToken,
+) -> bool {
+    ffi_call(false, || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let prompt_str = input_string(prompt, "prompt")?;
+        let options = GenerateOptions::from_ptr(options)?;
+        if options.num_beams != 1 || options.num_return_sequences != 1 {
+            return Err(FfiError::invalid_input(
+                "streaming generates a single sequence without beam search, num_beams and num_return_sequences must be 1",
+            ));
+        }
+        let callback = callback.ok_or_else(|| FfiError::invalid_input("stream callback is NULL"))?;
+
+        seeded(options.seed, || {
+            model.stream(&prompt_str, &options, cancel, |text| {
+                let text = CString::new(text.replace('\0', "")).unwrap_or_default();
+                callback(user_data, text.as_ptr())
+            })
+        })?;
+        Ok(true)
+    })
+}
+
+/// Free a text generation model
+#[no_mangle]
+pub extern "C" fn free_text_generation_model(wrapper: *mut TextGenerationModelWrapper) {
+    if !wrapper.is_null() {
+        unsafe {
+            let w = Box::from_raw(wrapper);
+            if !w.model.is_null() {
+                drop(Box::from_raw(w.model));
+            }
+        }
+    }
+}
+
+// ============================================================================
+// Sentence Embeddings FFI Functions
+// ============================================================================
+
+/// Create a sentence embeddings model for one of the pretrained models selectable from Go
+/// (`kind`)
+#[no_mangle]
+pub extern "C" fn new_sentence_embeddings_model(
+    kind: i32,
+    options: *const EmbeddingsOptions,
+) -> *mut SentenceEmbeddingsModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let options = EmbeddingsOptions::from_ptr(options)?;
+        let model_type = sentence_embeddings::model_type(kind)?;
+        let device = device_from_code(options.device)?;
+        sentence_embeddings_wrapper(SentenceEmbedder::remote(model_type, options, device)?)
+    })
+}
+
+/// Create a sentence embeddings model from a local sentence-transformers directory
+#[no_mangle]
+pub extern "C" fn new_sentence_embeddings_model_from_dir(
+    dir: *const c_char,
+    options: *const EmbeddingsOptions,
+) -> *mut SentenceEmbeddingsModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let dir = input_string(dir, "model directory")?;
+        let options = EmbeddingsOptions::from_ptr(options)?;
+        let device = device_from_code(options.device)?;
+        sentence_embeddings_wrapper(SentenceEmbedder::local(Path::new(&dir), options, device)?)
+    })
+}
+
+fn sentence_embeddings_wrapper(
+    model: SentenceEmbedder,
+) -> Result<*mut SentenceEmbeddingsModelWrapper, FfiError> {
+    let wrapper = SentenceEmbeddingsModelWrapper {
+        model: Box::into_raw(Box::new(model)),
+    };
+    Ok(Box::into_raw(Box::new(wrapper)))
+}
+
+/// Encode a batch of sentences. The result must be released with `free_embeddings_result`.
+#[no_mangle]
+pub extern "C" fn encode_sentences(
+    wrapper: *mut SentenceEmbeddingsModelWrapper,
+    texts: *const *const c_char,
+    texts_count: size_t,
+    cancel: *const CancelToken,
+) -> *mut EmbeddingsResult {
+    ffi_call(ptr::null_mut(), || {
+        let wrapper = handle(wrapper)?;
+        let model = unsafe { &*wrapper.model };
+        let texts_vec = input_strings(texts, texts_count, "text")?;
+
+        let embeddings = run_batched(cancel, &texts_vec, |chunk| model.encode(chunk))?;
+        let dim = embeddings.first().map_or(0, |e| e.len());
+        let count = embeddings.len();
+        let (data, _) = into_raw_parts(embeddings.concat());
+        Ok(Box::into_raw(Box::new(EmbeddingsResult { data, count, dim })))
+    })
+}
+
+/// Free a sentence embeddings model
+#[no_mangle]
+pub extern "C" fn free_sentence_embeddings_model(wrapper: *mut SentenceEmbeddingsModelWrapper) {
+    if !wrapper.is_null() {
+        unsafe {
+            let w = Box::from_raw(wrapper);
+            if !w.model.is_null() {
+                drop(Box::from_raw(w.model));
+            }
+        }
+    }
+}
+
+/// Free an embeddings result
+#[no_mangle]
+pub extern "C" fn free_embeddings_result(result: *mut EmbeddingsResult) {
+    if !result.is_null() {
+        unsafe {
+            let r = Box::from_raw(result);
+            drop(from_raw_parts(r.data, r.count * r.dim));
         }
+    }
+}
+
+// ============================================================================
+// Keyword Extraction FFI Functions
+// ============================================================================
+
+/// Create a keyword extraction model
+#[no_mangle]
+pub extern "C" fn new_keyword_extraction_model(
+    options: *const KeywordOptions,
+) -> *mut KeywordExtractionModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let model = KeywordExtractor::new(options)?;
+        let wrapper = KeywordExtractionModelWrapper {
+            model: Box::into_raw(Box::new(model)),
+        };
+        Ok(Box::into_raw(Box::new(wrapper)))
+    })
+}
+
+/// Extract the keywords of a batch of texts
+#[no_mangle]
+pub extern "C" fn extract_keywords_batch(
+    wrapper: *mut KeywordExtractionModelWrapper,
+    texts: *const *const c_char,
+    texts_count: size_t,
+    cancel: *const CancelToken,
+) -> *mut KeywordBatchResult {
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let texts_vec = input_strings(texts, texts_count, "text")?;
+
+        let keywords = run_batched(cancel, &texts_vec, |chunk| model.extract(chunk))?;
+        let (results, count) = into_raw_parts(keywords.iter().map(|k| keyword_result(k)).collect());
+        Ok(Box::into_raw(Box::new(KeywordBatchResult { results, count })))
+    })
+}
 
-        let tags: Vec<POSTag> = results[0]
+fn keyword_result(keywords: &[Keyword]) -> KeywordResult {
+    let (keywords, count) = into_raw_parts(
+        keywords
             .iter()
-            .map(|tag| POSTag {
-                word: string_to_cstr(&tag.word),
-                score: tag.score as f32,
-                label: string_to_cstr(&tag.label),
+            .map(|keyword| {
+                let (offsets, offsets_count) = into_raw_parts(
+                    keyword
+                        .offsets
+                        .iter()
+                        .map(|offset| KeywordOffset {
+                            begin: offset.begin as usize,
+                            end: offset.end as usize,
+                        })
+                        .collect(),
+                );
+                KeywordItem {
+                    text: string_to_cstr(&keyword.text),
+                    score: keyword.score,
+                    offsets,
+                    offsets_count,
+                }
             })
-            .collect();
-
-        let count = tags.len();
-        let tags_ptr = Box::into_raw(tags.into_boxed_slice()) as *mut POSTag;
-
-        let result = POSResult {
-            tags: tags_ptr,
-            count,
-        };
-        Box::into_raw(Box::new(result))
-    }
+            .collect(),
+    );
+    KeywordResult { keywords, count }
 }
 
-/// Free a POS model
+/// Free a keyword extraction model
 #[no_mangle]
-pub extern "C" fn free_pos_model(wrapper: *mut POSModelWrapper) {
+pub extern "C" fn free_keyword_extraction_model(wrapper: *mut KeywordExtractionModelWrapper) {
     if !wrapper.is_null() {
         unsafe {
             let w = Box::from_raw(wrapper);
@@ -353,21 +2373,16 @@ pub extern "C" fn free_pos_model(wrapper: *mut POSModelWrapper) {
     }
 }
 
-/// Free a POS result
+/// Free a batched keyword extraction result
 #[no_mangle]
-pub extern "C" fn free_pos_result(result: *mut POSResult) {
+pub extern "C" fn free_keyword_batch_result(result: *mut KeywordBatchResult) {
     if !result.is_null() {
         unsafe {
             let r = Box::from_raw(result);
-            if !r.tags.is_null() && r.count > 0 {
-                let tags = Vec::from_raw_parts(r.tags, r.count, r.count);
-                for tag in tags {
-                    if !tag.word.is_null() {
-                        drop(CString::from_raw(tag.word));
-                    }
-                    if !tag.label.is_null() {
-                        drop(CString::from_raw(tag.label));
-                    }
+            for item in from_raw_parts(r.results, r.count) {
+                for keyword in from_raw_parts(item.keywords, item.count) {
+                    free_cstr(keyword.text);
+                    drop(from_raw_parts(keyword.offsets, keyword.offsets_count));
                 }
             }
         }
@@ -375,90 +2390,102 @@ pub extern "C" fn free_pos_result(result: *mut POSResult) {
 }
 
 // ============================================================================
-// NER FFI Functions
+// Fill-Mask FFI Functions
 // ============================================================================
 
-/// Create a new NER model with default configuration
+/// Create a fill-mask model with default configuration (BERT base, uncased)
 #[no_mangle]
-pub extern "C" fn new_ner_model() -> *mut NERModelWrapper {
-    match NERModel::new(TokenClassificationConfig::default()) {
-        Ok(model) => {
-            let wrapper = NERModelWrapper {
-                model: Box::into_raw(Box::new(model)),
-            };
-            Box::into_raw(Box::new(wrapper))
-        }
-        Err(e) => {
-            eprintln!("Failed to create NER model: {:?}", e);
-            ptr::null_mut()
-        }
-    }
+pub extern "C" fn new_masked_language_model(device: i32) -> *mut MaskedLanguageModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        masked_language_wrapper(MaskedLanguageConfig::default(), device)
+    })
 }
 
-/// Create a NER model from custom files
+/// Create a fill-mask model from custom files
 #[no_mangle]
-pub extern "C" fn new_ner_model_from_files(
-    _model_path: *const c_char,
-    _config_path: *const c_char,
-    _vocab_path: *const c_char,
-    _merges_path: *const c_char,
-    _model_type: i32,
-) -> *mut NERModelWrapper {
-    new_ner_model()
+pub extern "C" fn new_masked_language_model_from_files(
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    device: i32,
+) -> *mut MaskedLanguageModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        let config = MaskedLanguageConfig::new(
+            files.model_type,
+            files.model_resource(),
+            files.config,
+            files.vocab,
+            files.merges,
+            files.tokenizer.lower_case,
+            files.tokenizer.strip_accents,
+            files.tokenizer.add_prefix_space,
+            None::<String>,
+        );
+        masked_language_wrapper(config, device)
+    })
 }
 
-/// Predict NER entities for the given text
-#[no_mangle]
-pub extern "C" fn predict_ner(
-    wrapper: *mut NERModelWrapper,
-    text: *const c_char,
-) -> *mut NERResult {
-    if wrapper.is_null() || text.is_null() {
-        return ptr::null_mut();
-    }
-
-    let text_str = match cstr_to_string(text) {
-        Some(s) => s,
-        None => return ptr::null_mut(),
+fn masked_language_wrapper(mut config: MaskedLanguageConfig, device: i32) -> Result<*mut MaskedLanguageModelWrapper, FfiError> {
+    config.device = device_from_code(device)?;
+    let model = FillMask::new(config).map_err(load_error)?;
+    let wrapper = MaskedLanguageModelWrapper {
+        model: Box::into_raw(Box::new(model)),
     };
+    Ok(Box::into_raw(Box::new(wrapper)))
+}
 
-    unsafe {
-        let model = &*(*wrapper).model;
-        let results = model.predict(&[text_str.as_str()]);
-
-        if results.is_empty() || results[0].is_empty() {
-            let result = NERResult {
-                entities: ptr::null_mut(),
-                count: 0,
-            };
-            return Box::into_raw(Box::new(result));
+/// Predict the `top_k` most likely tokens of every mask of a batch of texts
+#[no_mangle]
+pub extern "C" fn fill_mask_batch(
+    wrapper: *mut MaskedLanguageModelWrapper,
+    texts: *const *const c_char,
+    texts_count: size_t,
+    top_k: size_t,
+    cancel: *const CancelToken,
+) -> *mut FillMaskBatchResult {
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let texts_vec = input_strings(texts, texts_count, "text")?;
+        if top_k == 0 {
+            return Err(FfiError::invalid_input("top_k must be at least 1"));
         }
 
-        let entities: Vec<Entity> = results[0]
+        let predictions = run_batched(cancel, &texts_vec, |chunk| {
+            model.predict(chunk, top_k).map_err(inference_error)
+        })?;
+        let (results, count) = into_raw_parts(predictions.iter().map(|p| fill_mask_result(p)).collect());
+        Ok(Box::into_raw(Box::new(FillMaskBatchResult { results, count })))
+    })
+}
+
+fn fill_mask_result(masks: &[Vec<MaskCandidate>]) -> FillMaskResult {
+    let (masks, count) = into_raw_parts(
+        masks
             .iter()
-            .map(|ent| Entity {
-                word: string_to_cstr(&ent.word),
-                score: ent.score as f32,
-                label: string_to_cstr(&ent.label),
-                offset_begin: ent.offset.begin as usize,
-                offset_end: ent.offset.end as usize,
+            .map(|candidates| {
+                let (candidates, count) = into_raw_parts(
+                    candidates
+                        .iter()
+                        .map(|c| MaskCandidateItem {
+                            token: string_to_cstr(&c.token),
+                            id: c.id,
+                            score: c.score,
+                        })
+                        .collect(),
+                );
+                MaskPrediction { candidates, count }
             })
-            .collect();
-
-        let count = entities.len();
-        let entities_ptr = Box::into_raw(entities.into_boxed_slice()) as *mut Entity;
-
-        let result = NERResult {
-            entities: entities_ptr,
-            count,
-        };
-        Box::into_raw(Box::new(result))
-    }
+            .collect(),
+    );
+    FillMaskResult { masks, count }
 }
 
-/// Free a NER model
+/// Free a fill-mask model
 #[no_mangle]
-pub extern "C" fn free_ner_model(wrapper: *mut NERModelWrapper) {
+pub extern "C" fn free_masked_language_model(wrapper: *mut MaskedLanguageModelWrapper) {
     if !wrapper.is_null() {
         unsafe {
             let w = Box::from_raw(wrapper);
@@ -469,20 +2496,16 @@ pub extern "C" fn free_ner_model(wrapper: *mut NERModelWrapper) {
     }
 }
 
-/// Free a NER result
+/// Free a batched fill-mask result
 #[no_mangle]
-pub extern "C" fn free_ner_result(result: *mut NERResult) {
+pub extern "C" fn free_fill_mask_batch_result(result: *mut FillMaskBatchResult) {
     if !result.is_null() {
         unsafe {
             let r = Box::from_raw(result);
-            if !r.entities.is_null() && r.count > 0 {
-                let entities = Vec::from_raw_parts(r.entities, r.count, r.count);
-                for ent in entities {
-                    if !ent.word.is_null() {
-                        drop(CString::from_raw(ent.word));
-                    }
-                    if !ent.label.is_null() {
-                        drop(CString::from_raw(ent.label));
+            for item in from_raw_parts(r.results, r.count) {
+                for mask in from_raw_parts(item.masks, item.count) {
+                    for candidate in from_raw_parts(mask.candidates, mask.count) {
+                        free_cstr(candidate.token);
                     }
                 }
             }
@@ -491,100 +2514,96 @@ pub extern "C" fn free_ner_result(result: *mut NERResult) {
 }
 
 // ============================================================================
-// Question Answering FFI Functions
+// Sequence Classification FFI Functions
 // ============================================================================
 
-/// Create a new QA model with default configuration
+/// Create a sequence classification model with default configuration (DistilBERT
+/// fine-tuned on SST-2)
 #[no_mangle]
-pub extern "C" fn new_qa_model() -> *mut QAModelWrapper {
-    match QuestionAnsweringModel::new(QuestionAnsweringConfig::default()) {
-        Ok(model) => {
-            let wrapper = QAModelWrapper {
-                model: Box::into_raw(Box::new(model)),
-            };
-            Box::into_raw(Box::new(wrapper))
-        }
-        Err(e) => {
-            eprintln!("Failed to create QA model: {:?}", e);
-            ptr::null_mut()
-        }
-    }
+pub extern "C" fn new_sequence_classification_model(device: i32) -> *mut SequenceClassificationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        sequence_classification_wrapper(SequenceClassificationConfig::default(), device)
+    })
 }
 
-/// Create a QA model from custom files
+/// Create a sequence classification model from custom files. Labels are read from the
+/// `id2label` map of the config file.
 #[no_mangle]
-pub extern "C" fn new_qa_model_from_files(
-    _model_path: *const c_char,
-    _config_path: *const c_char,
-    _vocab_path: *const c_char,
-    _merges_path: *const c_char,
-    _model_type: i32,
-) -> *mut QAModelWrapper {
-    new_qa_model()
+pub extern "C" fn new_sequence_classification_model_from_files(
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    device: i32,
+) -> *mut SequenceClassificationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        let config = SequenceClassificationConfig::new(
+            files.model_type,
+            files.model_resource(),
+            files.config,
+            files.vocab,
+            files.merges,
+            files.tokenizer.lower_case,
+            files.tokenizer.strip_accents,
+            files.tokenizer.add_prefix_space,
+        );
+        sequence_classification_wrapper(config, device)
+    })
 }
 
-/// Predict answers for the given question and context
-#[no_mangle]
-pub extern "C" fn predict_qa(
-    wrapper: *mut QAModelWrapper,
-    question: *const c_char,
-    context: *const c_char,
-) -> *mut QAResult {
-    if wrapper.is_null() || question.is_null() || context.is_null() {
-        return ptr::null_mut();
-    }
-
-    let question_str = match cstr_to_string(question) {
-        Some(s) => s,
-        None => return ptr::null_mut(),
-    };
-
-    let context_str = match cstr_to_string(context) {
-        Some(s) => s,
-        None => return ptr::null_mut(),
+fn sequence_classification_wrapper(
+    mut config: SequenceClassificationConfig,
+    device: i32,
+) -> Result<*mut SequenceClassificationModelWrapper, FfiError> {
+    config.device = device_from_code(device)?;
+    let model = Classifier::new(config).map_err(load_error)?;
+    let wrapper = SequenceClassificationModelWrapper {
+        model: Box::into_raw(Box::new(model)),
     };
+    Ok(Box::into_raw(Box::new(wrapper)))
+}
 
-    unsafe {
-        let model = &*(*wrapper).model;
-        let qa_input = QaInput {
-            question: question_str,
-            context: context_str,
-        };
-
-        let results = model.predict(&[qa_input], 1, 32);
-
-        if results.is_empty() || results[0].is_empty() {
-            let result = QAResult {
-                answers: ptr::null_mut(),
-                count: 0,
-            };
-            return Box::into_raw(Box::new(result));
-        }
+/// Score every label for a batch of texts, with a softmax over the labels or, with
+/// `multi_label`, an independent sigmoid per label
+#[no_mangle]
+pub extern "C" fn classify_batch(
+    wrapper: *mut SequenceClassificationModelWrapper,
+    texts: *const *const c_char,
+    texts_count: size_t,
+    multi_label: bool,
+    cancel: *const CancelToken,
+) -> *mut ClassificationBatchResult {
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let texts_vec = input_strings(texts, texts_count, "text")?;
+
+        let labels = run_batched(cancel, &texts_vec, |chunk| {
+            model.predict(chunk, multi_label).map_err(inference_error)
+        })?;
+        let (results, count) = into_raw_parts(labels.iter().map(|l| classification_result(l)).collect());
+        Ok(Box::into_raw(Box::new(ClassificationBatchResult { results, count })))
+    })
+}
 
-        let answers: Vec<QAAnswer> = results[0]
+fn classification_result(labels: &[LabelScore]) -> ClassificationResult {
+    let (labels, count) = into_raw_parts(
+        labels
             .iter()
-            .map(|ans| QAAnswer {
-                score: ans.score as f32,
-                start: ans.start,
-                end: ans.end,
-                answer: string_to_cstr(&ans.answer),
+            .map(|label| ClassificationLabel {
+                label: string_to_cstr(&label.label),
+                id: label.id,
+                score: label.score,
             })
-            .collect();
-
-        let count = answers.len();
-        let answers_ptr = Box::into_raw(answers.into_boxed_slice()) as *mut QAAnswer;
-
-        let result = QAResult {
-            answers: answers_ptr,
-            count,
-        };
-        Box::into_raw(Box::new(result))
-    }
+            .collect(),
+    );
+    ClassificationResult { labels, count }
 }
 
-/// Free a QA model
+/// Free a sequence classification model
 #[no_mangle]
-pub extern "C" fn free_qa_model(wrapper: *mut QAModelWrapper) {
+pub extern "C" fn free_sequence_classification_model(wrapper: *mut SequenceClassificationModelWrapper) {
     if !wrapper.is_null() {
         unsafe {
             let w = Box::from_raw(wrapper);
@@ -595,18 +2614,15 @@ pub extern "C" fn free_qa_model(wrapper: *mut QAModelWrapper) {
     }
 }
 
-/// Free a QA result
+/// Free a batched sequence classification result
 #[no_mangle]
-pub extern "C" fn free_qa_result(result: *mut QAResult) {
+pub extern "C" fn free_classification_batch_result(result: *mut ClassificationBatchResult) {
     if !result.is_null() {
         unsafe {
             let r = Box::from_raw(result);
-            if !r.answers.is_null() && r.count > 0 {
-                let answers = Vec::from_raw_parts(r.answers, r.count, r.count);
-                for ans in answers {
-                    if !ans.answer.is_null() {
-                        drop(CString::from_raw(ans.answer));
-                    }
+            for item in from_raw_parts(r.results, r.count) {
+                for label in from_raw_parts(item.labels, item.count) {
+                    free_cstr(label.label);
                 }
             }
         }
@@ -614,82 +2630,104 @@ pub extern "C" fn free_qa_result(result: *mut QAResult) {
 }
 
 // ============================================================================
-// Summarization FFI Functions
+// Token Classification FFI Functions
 // ============================================================================
 
-/// Create a new summarization model with default configuration
+/// Create a token classification model with the default checkpoint (BERT fine-tuned
+/// on CoNLL-03 NER)
 #[no_mangle]
-pub extern "C" fn new_summarization_model() -> *mut SummarizationModelWrapper {
-    match SummarizationModel::new(SummarizationConfig::default()) {
-        Ok(model) => {
-            let wrapper = SummarizationModelWrapper {
-                model: Box::into_raw(Box::new(model)),
-            };
-            Box::into_raw(Box::new(wrapper))
-        }
-        Err(e) => {
-            eprintln!("Failed to create summarization model: {:?}", e);
-            ptr::null_mut()
-        }
-    }
+pub extern "C" fn new_token_classification_model(
+    options: *const TokenClassificationOptions,
+) -> *mut TokenClassificationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        token_classification_wrapper(TokenClassificationConfig::default(), options)
+    })
 }
 
-/// Create a summarization model from custom files
+/// Create a token classification model from custom files. Labels are read from the
+/// `id2label` map of the config file.
 #[no_mangle]
-pub extern "C" fn new_summarization_model_from_files(
-    _model_path: *const c_char,
-    _config_path: *const c_char,
-    _vocab_path: *const c_char,
-    _merges_path: *const c_char,
-    _model_type: i32,
-) -> *mut SummarizationModelWrapper {
-    new_summarization_model()
+pub extern "C" fn new_token_classification_model_from_files(
+    model_path: *const c_char,
+    config_path: *const c_char,
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+    options: *const TokenClassificationOptions,
+) -> *mut TokenClassificationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let files = LocalModelFiles::from_ffi(model_path, config_path, vocab_path, merges_path, model_type)?;
+        let config = TokenClassificationConfig::new(
+            files.model_type,
+            files.model_resource(),
+            files.config,
+            files.vocab,
+            files.merges,
+            files.tokenizer.lower_case,
+            files.tokenizer.strip_accents,
+            files.tokenizer.add_prefix_space,
+            LabelAggregationOption::First,
+        );
+        token_classification_wrapper(config, options)
+    })
 }
 
-/// Summarize the given text
-#[no_mangle]
-pub extern "C" fn summarize(
-    wrapper: *mut SummarizationModelWrapper,
-    text: *const c_char,
-) -> *mut SummarizationResult {
-    if wrapper.is_null() || text.is_null() {
-        return ptr::null_mut();
-    }
-
-    let text_str = match cstr_to_string(text) {
-        Some(s) => s,
-        None => return ptr::null_mut(),
+fn token_classification_wrapper(
+    config: TokenClassificationConfig,
+    options: *const TokenClassificationOptions,
+) -> Result<*mut TokenClassificationModelWrapper, FfiError> {
+    let model = TokenTagger::new(config, options)?;
+    let wrapper = TokenClassificationModelWrapper {
+        model: Box::into_raw(Box::new(model)),
     };
+    Ok(Box::into_raw(Box::new(wrapper)))
+}
 
-    unsafe {
-        let model = &*(*wrapper).model;
-        match model.summarize(&[text_str.as_str()]) {
-            Ok(summaries) => {
-                let cstr_summaries: Vec<*mut c_char> = summaries
-                    .iter()
-                    .map(|s| string_to_cstr(s))
-                    .collect();
-
-                let count = cstr_summaries.len();
-                let summaries_ptr = Box::into_raw(cstr_summaries.into_boxed_slice()) as *mut *mut c_char;
+/// Label the tokens of a batch of texts
+#[no_mangle]
+pub extern "C" fn classify_tokens_batch(
+    wrapper: *mut TokenClassificationModelWrapper,
+    texts: *const *const c_char,
+    texts_count: size_t,
+    cancel: *const CancelToken,
+) -> *mut TokenClassificationBatchResult {
+    ffi_call(ptr::null_mut(), || {
+        let model = unsafe { &*handle(wrapper)?.model };
+        let texts_vec = input_strings(texts, texts_count, "text")?;
+
+        let tokens = run_batched(cancel, &texts_vec, |chunk| Ok(model.predict(chunk)))?;
+        let (results, count) = into_raw_parts(tokens.iter().map(|t| token_classification_result(t)).collect());
+        Ok(Box::into_raw(Box::new(TokenClassificationBatchResult { results, count })))
+    })
+}
 
-                let result = SummarizationResult {
-                    summaries: summaries_ptr,
-                    count,
-                };
-                Box::into_raw(Box::new(result))
-            }
-            Err(e) => {
-                eprintln!("Summarization failed: {:?}", e);
-                ptr::null_mut()
-            }
-        }
-    }
+fn token_classification_result(tokens: &[Token]) -> TokenClassificationResult {
+    let (tokens, count) = into_raw_parts(
+        tokens
+            .iter()
+            .map(|token| {
+                let (offset_begin, offset_end) = token
+                    .offset
+                    .map(|offset| (offset.begin as size_t, offset.end as size_t))
+                    .unwrap_or((0, 0));
+                TokenItem {
+                    text: string_to_cstr(&token.text),
+                    label: string_to_cstr(&token.label),
+                    label_index: token.label_index,
+                    score: token.score as f32,
+                    sentence: token.sentence,
+                    offset_begin,
+                    offset_end,
+                }
+            })
+            .collect(),
+    );
+    TokenClassificationResult { tokens, count }
 }
 
-/// Free a summarization model
+/// Free a token classification model
 #[no_mangle]
-pub extern "C" fn free_summarization_model(wrapper: *mut SummarizationModelWrapper) {
+pub extern "C" fn free_token_classification_model(wrapper: *mut TokenClassificationModelWrapper) {
     if !wrapper.is_null() {
         unsafe {
             let w = Box::from_raw(wrapper);
@@ -700,18 +2738,16 @@ pub extern "C" fn free_summarization_model(wrapper: *mut SummarizationModelWrapp
     }
 }
 
-/// Free a summarization result
+/// Free a batched token classification result
 #[no_mangle]
-pub extern "C" fn free_summarization_result(result: *mut SummarizationResult) {
+pub extern "C" fn free_token_classification_batch_result(result: *mut TokenClassificationBatchResult) {
     if !result.is_null() {
         unsafe {
             let r = Box::from_raw(result);
-            if !r.summaries.is_null() && r.count > 0 {
-                let summaries = Vec::from_raw_parts(r.summaries, r.count, r.count);
-                for s in summaries {
-                    if !s.is_null() {
-                        drop(CString::from_raw(s));
-                    }
+            for item in from_raw_parts(r.results, r.count) {
+                for token in from_raw_parts(item.tokens, item.count) {
+                    free_cstr(token.text);
+                    free_cstr(token.label);
                 }
             }
         }
@@ -719,108 +2755,78 @@ pub extern "C" fn free_summarization_result(result: *mut SummarizationResult) {
 }
 
 // ============================================================================
-// Zero-Shot Classification FFI Functions
+// Conversation FFI Functions
 // ============================================================================
 
-/// Create a new zero-shot classification model with default configuration
+/// Create a conversation model with default configuration (DialoGPT medium)
 #[no_mangle]
-pub extern "C" fn new_zero_shot_model() -> *mut ZeroShotClassificationModelWrapper {
-    match ZeroShotClassificationModel::new(ZeroShotClassificationConfig::default()) {
-        Ok(model) => {
-            let wrapper = ZeroShotClassificationModelWrapper {
-                model: Box::into_raw(Box::new(model)),
-            };
-            Box::into_raw(Box::new(wrapper))
-        }
-        Err(e) => {
-            eprintln!("Failed to create zero-shot model: {:?}", e);
-            ptr::null_mut()
-        }
-    }
+pub extern "C" fn new_conversation_model(device: i32) -> *mut ConversationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        conversation_wrapper(ConversationConfig::default(), -1, device)
+    })
 }
 
-/// Create a zero-shot model from custom files
+/// Create the default conversation model (DialoGPT medium) responding with custom
+/// generation options
 #[no_mangle]
-pub extern "C" fn new_zero_shot_model_from_files(
-    _model_path: *const c_char,
-    _config_path: *const c_char,
-    _vocab_path: *const c_char,
-    _merges_path: *const c_char,
-    _model_type: i32,
-) -> *mut ZeroShotClassificationModelWrapper {
-    new_zero_shot_model()
+pub extern "C" fn new_conversation_model_with_options(
+    options: *const GenerateOptions,
+    device: i32,
+) -> *mut ConversationModelWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let options = GenerateOptions::from_ptr(options)?;
+        if options.num_return_sequences != 1 {
+            return Err(FfiError::invalid_input("conversations generate a single response, num_return_sequences must be 1"));
+        }
+        let mut config = ConversationConfig::default();
+        options.apply_to_conversation(&mut config);
+        conversation_wrapper(config, options.seed, device)
+    })
 }
 
-/// Predict zero-shot classification for the given text and labels
-#[no_mangle]
-pub extern "C" fn predict_zero_shot(
-    wrapper: *mut ZeroShotClassificationModelWrapper,
-    text: *const c_char,
-    labels: *const *const c_char,
-    labels_count: size_t,
-) -> *mut ZeroShotResult {
-    if wrapper.is_null() || text.is_null() || labels.is_null() || labels_count == 0 {
-        return ptr::null_mut();
-    }
-
-    let text_str = match cstr_to_string(text) {
-        Some(s) => s,
-        None => return ptr::null_mut(),
-    };
-
-    let labels_vec: Vec<String> = unsafe {
-        (0..labels_count)
-            .filter_map(|i| cstr_to_string(*labels.add(i)))
-            .collect()
+fn conversation_wrapper(mut config: ConversationConfig, seed: i64, device: i32) -> Result<*mut ConversationModelWrapper, FfiError> {
+    config.device = device_from_code(device)?;
+    let model = ConversationModel::new(config).map_err(load_error)?;
+    let wrapper = ConversationModelWrapper {
+        model: Box::into_raw(Box::new(model)),
+        seed,
     };
+    Ok(Box::into_raw(Box::new(wrapper)))
+}
 
-    if labels_vec.is_empty() {
-        return ptr::null_mut();
-    }
-
-    let labels_refs: Vec<&str> = labels_vec.iter().map(|s| s.as_str()).collect();
-
-    unsafe {
-        let model = &*(*wrapper).model;
-        match model.predict(&[text_str.as_str()], labels_refs.as_slice(), None, 128) {
-            Ok(results) => {
-                if results.is_empty() {
-                    let result = ZeroShotResult {
-                        labels: ptr::null_mut(),
-                        count: 0,
-                    };
-                    return Box::into_raw(Box::new(result));
-                }
-
-                // Each result is a Label with text and score
-                let zs_labels: Vec<ZeroShotLabel> = results
-                    .iter()
-                    .map(|label| ZeroShotLabel {
-                        text: string_to_cstr(&label.text),
-                        score: label.score,
-                    })
-                    .collect();
-
-                let count = zs_labels.len();
-                let labels_ptr = Box::into_raw(zs_labels.into_boxed_slice()) as *mut ZeroShotLabel;
-
-                let result = ZeroShotResult {
-                    labels: labels_ptr,
-                    count,
-                };
-                Box::into_raw(Box::new(result))
-            }
-            Err(e) => {
-                eprintln!("Zero-shot prediction failed: {:?}", e);
-                ptr::null_mut()
-            }
-        }
-    }
+/// Generate a response for every conversation of the manager with a pending user input,
+/// in a single batch. The token is checked before generation starts; rust-bert's
+/// `ConversationModel` cannot resume a partial output, so a started round cannot be
+/// interrupted.
+#[no_mangle]
+pub extern "C" fn generate_responses(
+    wrapper: *mut ConversationModelWrapper,
+    manager: *mut ConversationManagerWrapper,
+    cancel: *const CancelToken,
+) -> *mut ConversationResponses {
+    ffi_call(ptr::null_mut(), || {
+        let wrapper = handle(wrapper)?;
+        let model = unsafe { &*wrapper.model };
+        let manager = unsafe { &mut *handle(manager)?.manager };
+
+        check_cancelled(cancel)?;
+        let generated = seeded(wrapper.seed, || model.generate_responses(manager)).map_err(inference_error)?;
+        let (responses, count) = into_raw_parts(
+            generated
+                .iter()
+                .map(|(id, text)| ConversationResponse {
+                    id: string_to_cstr(&id.to_string()),
+                    text: string_to_cstr(text),
+                })
+                .collect(),
+        );
+        Ok(Box::into_raw(Box::new(ConversationResponses { responses, count })))
+    })
 }
 
-/// Free a zero-shot model
+/// Free a conversation model
 #[no_mangle]
-pub extern "C" fn free_zero_shot_model(wrapper: *mut ZeroShotClassificationModelWrapper) {
+pub extern "C" fn free_conversation_model(wrapper: *mut ConversationModelWrapper) {
     if !wrapper.is_null() {
         unsafe {
             let w = Box::from_raw(wrapper);
@@ -831,185 +2837,318 @@ pub extern "C" fn free_zero_shot_model(wrapper: *mut ZeroShotClassificationModel
     }
 }
 
-/// Free a zero-shot result
+/// Free the responses returned by `generate_responses`
 #[no_mangle]
-pub extern "C" fn free_zero_shot_result(result: *mut ZeroShotResult) {
+pub extern "C" fn free_conversation_responses(result: *mut ConversationResponses) {
     if !result.is_null() {
         unsafe {
             let r = Box::from_raw(result);
-            if !r.labels.is_null() && r.count > 0 {
-                let labels = Vec::from_raw_parts(r.labels, r.count, r.count);
-                for label in labels {
-                    if !label.text.is_null() {
-                        drop(CString::from_raw(label.text));
-                    }
-                }
+            for response in from_raw_parts(r.responses, r.count) {
+                free_cstr(response.id);
+                free_cstr(response.text);
             }
         }
     }
 }
 
-// ============================================================================
-// Translation FFI Functions
-// ============================================================================
+/// Create an empty conversation manager
+#[no_mangle]
+pub extern "C" fn new_conversation_manager() -> *mut ConversationManagerWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let wrapper = ConversationManagerWrapper {
+            manager: Box::into_raw(Box::new(ConversationManager::new())),
+        };
+        Ok(Box::into_raw(Box::new(wrapper)))
+    })
+}
 
-/// Create a new translation model with default configuration (English to French)
+/// Start a conversation, with `text` as its first user input unless it is NULL. Returns
+/// the UUID of the conversation, to be released with `free_string`.
 #[no_mangle]
-pub extern "C" fn new_translation_model() -> *mut TranslationModelWrapper {
-    match TranslationModelBuilder::new()
-        .with_source_languages(vec![Language::English])
-        .with_target_languages(vec![Language::French])
-        .create_model()
-    {
-        Ok(model) => {
-            let wrapper = TranslationModelWrapper {
-                model: Box::into_raw(Box::new(model)),
-            };
-            Box::into_raw(Box::new(wrapper))
-        }
-        Err(e) => {
-            eprintln!("Failed to create translation model: {:?}", e);
-            ptr::null_mut()
+pub extern "C" fn conversation_create(
+    manager: *mut ConversationManagerWrapper,
+    text: *const c_char,
+) -> *mut c_char {
+    ffi_call(ptr::null_mut(), || {
+        let manager = unsafe { &mut *handle(manager)?.manager };
+        let id = match cstr_to_string(text) {
+            Some(text) => manager.create(&text),
+            None => manager.create_empty(),
+        };
+        Ok(string_to_cstr(&id.to_string()))
+    })
+}
+
+/// Add a user input to a conversation. Fails if the previous input has no response yet.
+#[no_mangle]
+pub extern "C" fn conversation_add_user_input(
+    manager: *mut ConversationManagerWrapper,
+    id: *const c_char,
+    text: *const c_char,
+) -> bool {
+    ffi_call(false, || {
+        let manager = unsafe { &mut *handle(manager)?.manager };
+        let id = input_string(id, "conversation id")?;
+        let text = input_string(text, "text")?;
+        conversation::find(manager, &id)?
+            .add_user_input(&text)
+            .map_err(|err| FfiError::invalid_input(err.to_string()))?;
+        Ok(true)
+    })
+}
+
+/// Remove a conversation from the manager
+#[no_mangle]
+pub extern "C" fn conversation_remove(
+    manager: *mut ConversationManagerWrapper,
+    id: *const c_char,
+) -> bool {
+    ffi_call(false, || {
+        let manager = unsafe { &mut *handle(manager)?.manager };
+        let id = input_string(id, "conversation id")?;
+        match manager.remove(&conversation::parse_id(&id)?) {
+            Some(_) => Ok(true),
+            None => Err(FfiError::invalid_input(format!("unknown conversation {}", id))),
         }
-    }
+    })
 }
 
-/// Create a translation model from custom files
+/// List the UUIDs of the conversations of the manager. The result must be released
+/// with `free_string_array`.
 #[no_mangle]
-pub extern "C" fn new_translation_model_from_files(
-    _model_path: *const c_char,
-    _config_path: *const c_char,
-    _vocab_path: *const c_char,
-    _merges_path: *const c_char,
-    _model_type: i32,
-) -> *mut TranslationModelWrapper {
-    new_translation_model()
+pub extern "C" fn conversation_ids(manager: *mut ConversationManagerWrapper) -> *mut StringArray {
+    ffi_call(ptr::null_mut(), || {
+        let manager = unsafe { &mut *handle(manager)?.manager };
+        let ids: Vec<String> = manager.get_all().keys().map(|id| id.to_string()).collect();
+        Ok(string_array(&ids))
+    })
 }
 
-/// Translate the given text
+/// Get the exchanges of a conversation
 #[no_mangle]
-pub extern "C" fn translate(
-    wrapper: *mut TranslationModelWrapper,
-    text: *const c_char,
-    _source_lang: *const c_char,
-    _target_lang: *const c_char,
-) -> *mut c_char {
-    if wrapper.is_null() || text.is_null() {
-        return ptr::null_mut();
-    }
+pub extern "C" fn conversation_history(
+    manager: *mut ConversationManagerWrapper,
+    id: *const c_char,
+) -> *mut ConversationHistory {
+    ffi_call(ptr::null_mut(), || {
+        let manager = unsafe { &mut *handle(manager)?.manager };
+        let id = input_string(id, "conversation id")?;
+        let conversation = conversation::find(manager, &id)?;
+
+        let count = conversation
+            .past_user_inputs
+            .len()
+            .min(conversation.generated_responses.len());
+        let (user_inputs, _) = into_raw_parts(
+            conversation.past_user_inputs[..count]
+                .iter()
+                .map(|s| string_to_cstr(s))
+                .collect(),
+        );
+        let (responses, _) = into_raw_parts(
+            conversation.generated_responses[..count]
+                .iter()
+                .map(|s| string_to_cstr(s))
+                .collect(),
+        );
+        let pending_input = match &conversation.new_user_input {
+            Some(text) => string_to_cstr(text),
+            None => ptr::null_mut(),
+        };
+        Ok(Box::into_raw(Box::new(ConversationHistory {
+            user_inputs,
+            responses,
+            count,
+            pending_input,
+        })))
+    })
+}
 
-    let text_str = match cstr_to_string(text) {
-        Some(s) => s,
-        None => return ptr::null_mut(),
-    };
+/// Keep only the last `turns` exchanges of a conversation
+#[no_mangle]
+pub extern "C" fn conversation_trim(
+    manager: *mut ConversationManagerWrapper,
+    id: *const c_char,
+    turns: size_t,
+) -> bool {
+    ffi_call(false, || {
+        let manager = unsafe { &mut *handle(manager)?.manager };
+        let id = input_string(id, "conversation id")?;
+        conversation::trim(conversation::find(manager, &id)?, turns);
+        Ok(true)
+    })
+}
 
-    unsafe {
-        let model = &*(*wrapper).model;
-        match model.translate(&[text_str.as_str()], None, None) {
-            Ok(results) => {
-                match results.first() {
-                    Some(translation) => string_to_cstr(translation),
-                    None => ptr::null_mut(),
-                }
-            }
-            Err(e) => {
-                eprintln!("Translation failed: {:?}", e);
-                ptr::null_mut()
+/// Free a conversation manager and its conversations
+#[no_mangle]
+pub extern "C" fn free_conversation_manager(wrapper: *mut ConversationManagerWrapper) {
+    if !wrapper.is_null() {
+        unsafe {
+            let w = Box::from_raw(wrapper);
+            if !w.manager.is_null() {
+                drop(Box::from_raw(w.manager));
             }
         }
     }
 }
 
-/// Free a translation model
+/// Free a conversation history
 #[no_mangle]
-pub extern "C" fn free_translation_model(wrapper: *mut TranslationModelWrapper) {
-    if !wrapper.is_null() {
+pub extern "C" fn free_conversation_history(history: *mut ConversationHistory) {
+    if !history.is_null() {
         unsafe {
-            let w = Box::from_raw(wrapper);
-            if !w.model.is_null() {
-                drop(Box::from_raw(w.model));
+            let h = Box::from_raw(history);
+            for s in from_raw_parts(h.user_inputs, h.count) {
+                free_cstr(s);
+            }
+            for s in from_raw_parts(h.responses, h.count) {
+                free_cstr(s);
             }
+            free_cstr(h.pending_input);
         }
     }
 }
 
 // ============================================================================
-// Text Generation FFI Functions
+// Tokenizer FFI Functions
 // ============================================================================
 
-/// Create a new text generation model with default configuration (GPT-2)
+/// Create a tokenizer from the vocabulary (and merges) files of a checkpoint. Flags such
+/// as lower casing are read from the `tokenizer_config.json` next to the vocabulary, or
+/// the `config.json` there when the model type does not settle it.
 #[no_mangle]
-pub extern "C" fn new_text_generation_model() -> *mut TextGenerationModelWrapper {
-    match TextGenerationModel::new(TextGenerationConfig::default()) {
-        Ok(model) => {
-            let wrapper = TextGenerationModelWrapper {
-                model: Box::into_raw(Box::new(model)),
-            };
-            Box::into_raw(Box::new(wrapper))
-        }
-        Err(e) => {
-            eprintln!("Failed to create text generation model: {:?}", e);
-            ptr::null_mut()
+pub extern "C" fn new_tokenizer_from_files(
+    vocab_path: *const c_char,
+    merges_path: *const c_char,
+    model_type: i32,
+) -> *mut TokenizerWrapper {
+    ffi_call(ptr::null_mut(), || {
+        let model_type = model_type_from_int(model_type)
+            .ok_or_else(|| FfiError::invalid_input(format!("unknown model type {}", model_type)))?;
+        let vocab = local_file(vocab_path, "vocab")?;
+        let merges = if merges_path.is_null() {
+            None
+        } else {
+            Some(local_file(merges_path, "merges")?)
+        };
+        if merges.is_none() && requires_merges(model_type) {
+            return Err(FfiError::invalid_input(format!(
+                "model type {:?} requires a merges file",
+                model_type
+            )));
         }
-    }
+
+        let config = vocab.with_file_name("config.json");
+        let flags = TokenizerFlags::from_files(&vocab, Some(config.as_path()), model_type);
+        let tokenizer = Tokenizer::from_files(
+            model_type,
+            &vocab,
+            merges.as_deref(),
+            flags.lower_case,
+            flags.strip_accents,
+            flags.add_prefix_space,
+        )
+        .map_err(load_error)?;
+        let wrapper = TokenizerWrapper {
+            tokenizer: Box::into_raw(Box::new(tokenizer)),
+        };
+        Ok(Box::into_raw(Box::new(wrapper)))
+    })
 }
 
-/// Create a text generation model from custom files
+/// Encode a text, or the pair of `first` and `second` when `second` is not NULL
 #[no_mangle]
-pub extern "C" fn new_text_generation_model_from_files(
-    _model_path: *const c_char,
-    _config_path: *const c_char,
-    _vocab_path: *const c_char,
-    _merges_path: *const c_char,
-    _model_type: i32,
-) -> *mut TextGenerationModelWrapper {
-    new_text_generation_model()
+pub extern "C" fn encode_text(
+    wrapper: *mut TokenizerWrapper,
+    first: *const c_char,
+    second: *const c_char,
+    max_length: size_t,
+    truncation: i32,
+    stride: size_t,
+) -> *mut EncodingResult {
+    ffi_call(ptr::null_mut(), || {
+        let tokenizer = unsafe { &*handle(wrapper)?.tokenizer };
+        let first = input_string(first, "text")?;
+        let second = cstr_to_string(second);
+        let truncation = truncation_strategy(truncation)?;
+
+        let encoding = tokenizer.encode(&first, second.as_deref(), max_length, truncation, stride);
+        Ok(Box::into_raw(Box::new(encoding_result(encoding))))
+    })
 }
 
-/// Generate text from the given prompt
-#[no_mangle]
-pub extern "C" fn generate_text(
-    wrapper: *mut TextGenerationModelWrapper,
-    prompt: *const c_char,
-    prefix: *const c_char,
-) -> *mut c_char {
-    if wrapper.is_null() || prompt.is_null() {
-        return ptr::null_mut();
+fn encoding_result(encoding: Encoding) -> EncodingResult {
+    let (tokens, count) = into_raw_parts(
+        encoding
+            .tokens
+            .iter()
+            .map(|token| {
+                let (offset_begin, offset_end) = token.offset.unwrap_or((0, 0));
+                EncodedTokenItem {
+                    id: token.id,
+                    token: string_to_cstr(&token.token),
+                    offset_begin: offset_begin as size_t,
+                    offset_end: offset_end as size_t,
+                    special: token.special,
+                    segment: token.segment,
+                }
+            })
+            .collect(),
+    );
+    let (overflowing, overflowing_count) = into_raw_parts(encoding.overflowing);
+    EncodingResult {
+        tokens,
+        count,
+        num_truncated: encoding.num_truncated,
+        overflowing,
+        overflowing_count,
     }
+}
 
-    let prompt_str = match cstr_to_string(prompt) {
-        Some(s) => s,
-        None => return ptr::null_mut(),
-    };
-
-    let prefix_opt = cstr_to_string(prefix);
+/// Decode token ids back to text. The returned string must be released with `free_string`.
+#[no_mangle]
+pub extern "C" fn decode_ids(
+    wrapper: *mut TokenizerWrapper,
+    ids: *const i64,
+    ids_count: size_t,
+    skip_special_tokens: bool,
+) -> *mut c_char {
+    ffi_call(ptr::null_mut(), || {
+        let tokenizer = unsafe { &*handle(wrapper)?.tokenizer };
+        let ids = if ids_count == 0 {
+            &[][..]
+        } else if ids.is_null() {
+            return Err(FfiError::invalid_input("ids are NULL"));
+        } else {
+            unsafe { std::slice::from_raw_parts(ids, ids_count) }
+        };
+        Ok(string_to_cstr(&tokenizer.decode(ids, skip_special_tokens)))
+    })
+}
 
-    unsafe {
-        let model = &*(*wrapper).model;
-        match model.generate(&[prompt_str.as_str()], prefix_opt.as_deref()) {
-            Ok(results) => {
-                match results.first() {
-                    Some(generated) => string_to_cstr(generated),
-                    None => ptr::null_mut(),
-                }
-            }
-            Err(e) => {
-                eprintln!("Text generation failed: {:?}", e);
-                ptr::null_mut()
+/// Free a tokenizer
+#[no_mangle]
+pub extern "C" fn free_tokenizer(wrapper: *mut TokenizerWrapper) {
+    if !wrapper.is_null() {
+        unsafe {
+            let w = Box::from_raw(wrapper);
+            if !w.tokenizer.is_null() {
+                drop(Box::from_raw(w.tokenizer));
             }
         }
     }
 }
 
-/// Free a text generation model
+/// Free an encoding returned by `encode_text`
 #[no_mangle]
-pub extern "C" fn free_text_generation_model(wrapper: *mut TextGenerationModelWrapper) {
-    if !wrapper.is_null() {
+pub extern "C" fn free_encoding_result(result: *mut EncodingResult) {
+    if !result.is_null() {
         unsafe {
-            let w = Box::from_raw(wrapper);
-            if !w.model.is_null() {
-                drop(Box::from_raw(w.model));
+            let r = Box::from_raw(result);
+            for token in from_raw_parts(r.tokens, r.count) {
+                free_cstr(token.token);
             }
+            drop(from_raw_parts(r.overflowing, r.overflowing_count));
         }
     }
 }
@@ -0,0 +1,130 @@
+//! Fill-mask with the top k candidates per mask.
+//!
+//! rust-bert's `MaskedLanguageModel` only reports the best token for each mask, so this
+//! module runs the masked language model itself and keeps the k most likely tokens.
+
+use rust_bert::pipelines::common::{ConfigOption, TokenizerOption};
+use rust_bert::pipelines::masked_language::{MaskedLanguageConfig, MaskedLanguageOption};
+use rust_bert::resources::ResourceProvider;
+use rust_bert::RustBertError;
+use tch::nn::VarStore;
+use tch::{no_grad, Device, Kind};
+
+use crate::encoding::{encode_batch, load_tokenizer, load_weights};
+
+/// Mask marker accepted in inputs whatever the model, unless the config sets another one.
+const GENERIC_MASK: &str = "[MASK]";
+
+/// Candidate token for a mask
+pub struct MaskCandidate {
+    pub token: String,
+    pub id: i64,
+    pub score: f32,
+}
+
+pub struct FillMask {
+    tokenizer: TokenizerOption,
+    model: MaskedLanguageOption,
+    /// Mask written in inputs, replaced by `mask_token` before tokenization
+    marker: String,
+    mask_token: String,
+    mask_id: i64,
+    device: Device,
+    _var_store: VarStore,
+}
+
+impl FillMask {
+    pub fn new(config: MaskedLanguageConfig) -> Result<FillMask, RustBertError> {
+        let tokenizer = load_tokenizer(
+            config.model_type,
+            &*config.vocab_resource,
+            config.merges_resource.as_deref(),
+            config.lower_case,
+            config.strip_accents,
+            config.add_prefix_space,
+        )?;
+        let (mask_token, mask_id) = match (tokenizer.get_mask_value(), tokenizer.get_mask_id()) {
+            (Some(token), Some(id)) => (token.to_string(), id),
+            _ => {
+                return Err(RustBertError::InvalidConfigurationError(
+                    "the tokenizer has no mask token".to_string(),
+                ))
+            }
+        };
+        let marker = config.mask_token.clone().unwrap_or_else(|| GENERIC_MASK.to_string());
+
+        let config_path = config.config_resource.get_local_path()?;
+        let mut var_store = VarStore::new(config.device);
+        let model_config = ConfigOption::from_file(config.model_type, config_path);
+        let model = MaskedLanguageOption::new(config.model_type, var_store.root(), &model_config)?;
+        load_weights(&mut var_store, &config.model_resource, config.kind)?;
+
+        Ok(FillMask {
+            tokenizer,
+            model,
+            marker,
+            mask_token,
+            mask_id,
+            device: config.device,
+            _var_store: var_store,
+        })
+    }
+
+    /// Predicts, for each text, the `top_k` most likely tokens of every mask, in order of
+    /// appearance of the masks. Texts without a mask get no predictions.
+    pub fn predict<S>(&self, texts: &[S], top_k: usize) -> Result<Vec<Vec<Vec<MaskCandidate>>>, RustBertError>
+    where
+        S: AsRef<str>,
+    {
+        if texts.is_empty() {
+            return Ok(Vec::new());
+        }
+
+        let texts: Vec<String> = texts
+            .iter()
+            .map(|text| text.as_ref().replace(&self.marker, &self.mask_token))
+            .collect();
+        let batch = encode_batch(&self.tokenizer, &texts, self.device);
+
+        let logits = no_grad(|| {
+            self.model.forward_t(
+                Some(&batch.input_ids),
+                Some(&batch.attention_mask),
+                None,
+                None,
+                None,
+                None,
+                None,
+                false,
+            )
+        });
+
+        let top_k = (top_k as i64).min(logits.size()[2]);
+        let mut predictions = Vec::with_capacity(batch.inputs.len());
+        for (i, input) in batch.inputs.iter().enumerate() {
+            let mut per_mask = Vec::new();
+            for (position, _) in input.token_ids.iter().enumerate().filter(|(_, id)| **id == self.mask_id) {
+                let scores = logits
+                    .get(i as i64)
+                    .get(position as i64)
+                    .softmax(-1, Kind::Float);
+                let (values, indices) = scores.topk(top_k, -1, true, true);
+                let values = Vec::<f32>::try_from(values)?;
+                let indices = Vec::<i64>::try_from(indices)?;
+                per_mask.push(
+                    indices
+                        .into_iter()
+                        .zip(values)
+                        .map(|(id, score)| MaskCandidate {
+                            token: self.tokenizer.decode(&[id], true, true).trim().to_string(),
+                            id,
+                            score,
+                        })
+                        .collect(),
+                );
+            }
+            predictions.push(per_mask);
+        }
+        Ok(predictions)
+    }
+}
@@ -0,0 +1,102 @@
+//! Question answering settings.
+//!
+//! The lengths the contexts are split with are fixed when the model is loaded; the
+//! number of answers and the minimum answer score can change with every call.
+
+use rust_bert::pipelines::question_answering::{
+    Answer, QaInput, QuestionAnsweringConfig, QuestionAnsweringModel,
+};
+
+use crate::error::FfiError;
+
+/// Question/context pairs run in one forward pass by the model
+pub const BATCH_SIZE: usize = 32;
+
+/// Per-call options, mirrored by the Go `QAOptions`.
+#[repr(C)]
+#[derive(Clone, Copy)]
+pub struct QAOptions {
+    /// Answers returned per question, best first
+    pub top_k: i64,
+    /// Answers scoring below are dropped, 0 keeps them all
+    pub min_score: f64,
+}
+
+impl Default for QAOptions {
+    fn default() -> Self {
+        QAOptions {
+            top_k: 1,
+            min_score: 0.0,
+        }
+    }
+}
+
+impl QAOptions {
+    /// Reads options passed by pointer. NULL means `default`.
+    pub fn from_ptr(options: *const QAOptions, default: QAOptions) -> Result<QAOptions, FfiError> {
+        let options = match unsafe { options.as_ref() } {
+            Some(options) => *options,
+            None => return Ok(default),
+        };
+        if options.top_k < 1 {
+            return Err(FfiError::invalid_input(format!("top_k must be at least 1, got {}", options.top_k)));
+        }
+        if !(0.0..=1.0).contains(&options.min_score) {
+            return Err(FfiError::invalid_input(format!(
+                "min_score must be between 0 and 1, got {}",
+                options.min_score
+            )));
+        }
+        Ok(options)
+    }
+}
+
+/// Options for the QA constructors, mirrored by the Go `QAConfig`.
+#[repr(C)]
+pub struct QAModelOptions {
+    /// Longest question and context span fed to the model, in tokens
+    pub max_seq_length: usize,
+    /// Tokens shared by consecutive spans of a long context
+    pub doc_stride: usize,
+    /// Longest question, in tokens
+    pub max_query_length: usize,
+    /// Longest answer, in tokens
+    pub max_answer_length: usize,
+    /// Options of the calls that do not pass their own
+    pub defaults: QAOptions,
+}
+
+impl QAModelOptions {
+    /// Validates the options and applies their lengths to `config`, returning the default
+    /// call options.
+    pub fn apply(options: *const QAModelOptions, config: &mut QuestionAnsweringConfig) -> Result<QAOptions, FfiError> {
+        let options = unsafe { options.as_ref() }.ok_or_else(|| FfiError::invalid_input("QA options are NULL"))?;
+        if options.max_seq_length == 0 || options.max_query_length == 0 || options.max_answer_length == 0 {
+            return Err(FfiError::invalid_input("QA lengths must be at least 1"));
+        }
+        if options.doc_stride >= options.max_seq_length || options.max_query_length >= options.max_seq_length {
+            return Err(FfiError::invalid_input(format!(
+                "doc stride ({}) and max query length ({}) must be smaller than max sequence length ({})",
+                options.doc_stride, options.max_query_length, options.max_seq_length
+            )));
+        }
+        let defaults = QAOptions::from_ptr(&options.defaults, QAOptions::default())?;
+
+        config.max_seq_length = options.max_seq_length;
+        config.doc_stride = options.doc_stride;
+        config.max_query_length = options.max_query_length;
+        config.max_answer_length = options.max_answer_length;
+        Ok(defaults)
+    }
+}
+
+/// Extracts the `options.top_k` best answers of each input, without those scoring below
+/// `options.min_score`. No null span is scored: a question the context does not answer
+/// only gets no answers when all of its spans score below the minimum.
+pub fn answer(model: &QuestionAnsweringModel, inputs: &[QaInput], options: &QAOptions) -> Vec<Vec<Answer>> {
+    let mut answers = model.predict(inputs, options.top_k, BATCH_SIZE);
+    for answers in answers.iter_mut() {
+        answers.retain(|answer| answer.score >= options.min_score);
+    }
+    answers
+}
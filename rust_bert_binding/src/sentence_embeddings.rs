@@ -0,0 +1,251 @@
+//! Sentence embeddings with a selectable pooling strategy.
+//!
+//! rust-bert reads the pooling strategy from the `config.json` of the sentence-transformers
+//! pooling module. Overriding it means handing the model a rewritten copy of that file:
+//! for pretrained models the pooling resource is swapped for a local one, for model
+//! directories the model is loaded from an overlay of the directory that links every
+//! file but the pooling config.
+
+use rust_bert::pipelines::sentence_embeddings::{
+    SentenceEmbeddingsBuilder, SentenceEmbeddingsConfig, SentenceEmbeddingsModel,
+    SentenceEmbeddingsModelType,
+};
+use rust_bert::resources::{LocalResource, ResourceProvider};
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::sync::atomic::{AtomicUsize, Ordering};
+use tch::Device;
+
+use crate::error::FfiError;
+use crate::{inference_error, load_error};
+
+/// Options for the sentence embeddings constructors. Must match the Go
+/// `SentenceEmbeddingsConfig` mirror struct.
+#[repr(C)]
+#[derive(Clone, Copy, Debug)]
+pub struct EmbeddingsOptions {
+    /// 0 keeps the model's pooling, 1 mean, 2 CLS token, 3 max, 4 mean divided by sqrt(length)
+    pub pooling: i32,
+    /// L2-normalize the embeddings
+    pub normalize: bool,
+    /// Device code, see `device_from_code`
+    pub device: i32,
+}
+
+impl EmbeddingsOptions {
+    pub fn from_ptr(options: *const EmbeddingsOptions) -> Result<EmbeddingsOptions, FfiError> {
+        let options = match unsafe { options.as_ref() } {
+            Some(options) => *options,
+            None => return Err(FfiError::invalid_input("embeddings options are NULL")),
+        };
+        pooling_mode(options.pooling)?;
+        Ok(options)
+    }
+}
+
+/// Pooling config key enabled by a pooling code, `None` to keep the model's pooling.
+fn pooling_mode(pooling: i32) -> Result<Option<&'static str>, FfiError> {
+    match pooling {
+        0 => Ok(None),
+        1 => Ok(Some("pooling_mode_mean_tokens")),
+        2 => Ok(Some("pooling_mode_cls_token")),
+        3 => Ok(Some("pooling_mode_max_tokens")),
+        4 => Ok(Some("pooling_mode_mean_sqrt_len_tokens")),
+        _ => Err(FfiError::invalid_input(format!("unknown pooling {}", pooling))),
+    }
+}
+
+const POOLING_MODES: &[&str] = &[
+    "pooling_mode_cls_token",
+    "pooling_mode_mean_tokens",
+    "pooling_mode_max_tokens",
+    "pooling_mode_mean_sqrt_len_tokens",
+];
+
+/// Pretrained model selected by the Go `SentenceEmbeddingsModelKind`.
+pub fn model_type(kind: i32) -> Result<SentenceEmbeddingsModelType, FfiError> {
+    match kind {
+        0 => Ok(SentenceEmbeddingsModelType::AllMiniLmL12V2),
+        1 => Ok(SentenceEmbeddingsModelType::AllMiniLmL6V2),
+        2 => Ok(SentenceEmbeddingsModelType::AllDistilrobertaV1),
+        3 => Ok(SentenceEmbeddingsModelType::BertBaseNliMeanTokens),
+        4 => Ok(SentenceEmbeddingsModelType::DistiluseBaseMultilingualCased),
+        5 => Ok(SentenceEmbeddingsModelType::ParaphraseAlbertSmallV2),
+        6 => Ok(SentenceEmbeddingsModelType::SentenceT5Base),
+        _ => Err(FfiError::invalid_input(format!(
+            "unknown sentence embeddings model kind {}",
+            kind
+        ))),
+    }
+}
+
+pub struct SentenceEmbedder {
+    model: SentenceEmbeddingsModel,
+    normalize: bool,
+}
+
+impl SentenceEmbedder {
+    /// Loads a pretrained model.
+    pub fn remote(
+        model_type: SentenceEmbeddingsModelType,
+        options: EmbeddingsOptions,
+        device: Device,
+    ) -> Result<SentenceEmbedder, FfiError> {
+        let model = match pooling_mode(options.pooling)? {
+            None => SentenceEmbeddingsBuilder::remote(model_type)
+                .with_device(device)
+                .create_model()
+                .map_err(load_error)?,
+            Some(mode) => {
+                let mut config = SentenceEmbeddingsConfig::from(model_type);
+                config.device = device;
+                let original = config.pooling_config_resource.get_local_path().map_err(load_error)?;
+                let scratch = ScratchDir::new()?;
+                let pooling_config = scratch.path.join("pooling_config.json");
+                write_pooling_config(&original, &pooling_config, mode)?;
+                config.pooling_config_resource = Box::new(LocalResource::from(pooling_config));
+                SentenceEmbeddingsModel::new(config).map_err(load_error)?
+            }
+        };
+        Ok(SentenceEmbedder {
+            model,
+            normalize: options.normalize,
+        })
+    }
+
+    /// Loads a sentence-transformers model directory converted for rust-bert
+    /// (`rust_model.ot` next to `modules.json`).
+    pub fn local(dir: &Path, options: EmbeddingsOptions, device: Device) -> Result<SentenceEmbedder, FfiError> {
+        if !dir.join("modules.json").is_file() {
+            return Err(FfiError::model_load(format!(
+                "{} is not a sentence-transformers model directory (modules.json not found)",
+                dir.display()
+            )));
+        }
+        let model = match pooling_mode(options.pooling)? {
+            None => SentenceEmbeddingsBuilder::local(dir)
+                .with_device(device)
+                .create_model()
+                .map_err(load_error)?,
+            Some(mode) => {
+                let scratch = ScratchDir::new()?;
+                overlay_model_dir(dir, &scratch.path, mode)?;
+                SentenceEmbeddingsBuilder::local(&scratch.path)
+                    .with_device(device)
+                    .create_model()
+                    .map_err(load_error)?
+            }
+        };
+        Ok(SentenceEmbedder {
+            model,
+            normalize: options.normalize,
+        })
+    }
+
+    /// Encodes `texts` into one embedding each, L2-normalized if requested.
+    pub fn encode<S>(&self, texts: &[S]) -> Result<Vec<Vec<f32>>, FfiError>
+    where
+        S: AsRef<str> + Sync,
+    {
+        let mut embeddings = self.model.encode(texts).map_err(inference_error)?;
+        if self.normalize {
+            for embedding in embeddings.iter_mut() {
+                let norm = embedding.iter().map(|x| x * x).sum::<f32>().sqrt();
+                if norm > 0.0 {
+                    embedding.iter_mut().for_each(|x| *x /= norm);
+                }
+            }
+        }
+        Ok(embeddings)
+    }
+}
+
+fn write_pooling_config(original: &Path, target: &Path, mode: &str) -> Result<(), FfiError> {
+    let data = fs::read_to_string(original)
+        .map_err(|e| FfiError::model_load(format!("failed to read {}: {}", original.display(), e)))?;
+    let mut config: serde_json::Value = serde_json::from_str(&data)
+        .map_err(|e| FfiError::model_load(format!("failed to parse {}: {}", original.display(), e)))?;
+    let fields = config
+        .as_object_mut()
+        .ok_or_else(|| FfiError::model_load(format!("{} is not a JSON object", original.display())))?;
+    for key in POOLING_MODES {
+        fields.insert(key.to_string(), serde_json::Value::Bool(*key == mode));
+    }
+    fs::write(target, config.to_string())
+        .map_err(|e| FfiError::model_load(format!("failed to write {}: {}", target.display(), e)))
+}
+
+/// Path of the pooling module declared in `modules.json`, relative to the model directory.
+fn pooling_module_path(dir: &Path) -> Result<PathBuf, FfiError> {
+    let path = dir.join("modules.json");
+    let data = fs::read_to_string(&path)
+        .map_err(|e| FfiError::model_load(format!("failed to read {}: {}", path.display(), e)))?;
+    let modules: serde_json::Value = serde_json::from_str(&data)
+        .map_err(|e| FfiError::model_load(format!("failed to parse {}: {}", path.display(), e)))?;
+    modules
+        .as_array()
+        .into_iter()
+        .flatten()
+        .find(|module| {
+            module
+                .get("type")
+                .and_then(|t| t.as_str())
+                .is_some_and(|t| t.ends_with("Pooling"))
+        })
+        .and_then(|module| module.get("path").and_then(|p| p.as_str()))
+        .map(PathBuf::from)
+        .ok_or_else(|| FfiError::model_load(format!("no pooling module declared in {}", path.display())))
+}
+
+/// Fills `overlay` with links to the entries of `dir`, except for the pooling config
+/// which is rewritten to use `mode`.
+fn overlay_model_dir(dir: &Path, overlay: &Path, mode: &str) -> Result<(), FfiError> {
+    let pooling = pooling_module_path(dir)?;
+    link_entries(dir, overlay, |name| name != pooling.as_os_str())?;
+
+    let pooling_dir = overlay.join(&pooling);
+    fs::create_dir_all(&pooling_dir).map_err(overlay_error)?;
+    link_entries(&dir.join(&pooling), &pooling_dir, |name| name != "config.json")?;
+    write_pooling_config(&dir.join(&pooling).join("config.json"), &pooling_dir.join("config.json"), mode)
+}
+
+fn link_entries(from: &Path, to: &Path, keep: impl Fn(&std::ffi::OsStr) -> bool) -> Result<(), FfiError> {
+    for entry in fs::read_dir(from).map_err(overlay_error)? {
+        let entry = entry.map_err(overlay_error)?;
+        let name = entry.file_name();
+        if keep(&name) {
+            let source = fs::canonicalize(entry.path()).map_err(overlay_error)?;
+            std::os::unix::fs::symlink(source, to.join(&name)).map_err(overlay_error)?;
+        }
+    }
+    Ok(())
+}
+
+fn overlay_error(err: std::io::Error) -> FfiError {
+    FfiError::model_load(format!("failed to prepare model directory: {}", err))
+}
+
+/// Temporary directory removed on drop. Models are fully loaded by the time it goes,
+/// so the files written there are only needed during construction.
+struct ScratchDir {
+    path: PathBuf,
+}
+
+impl ScratchDir {
+    fn new() -> Result<ScratchDir, FfiError> {
+        static COUNTER: AtomicUsize = AtomicUsize::new(0);
+        let path = std::env::temp_dir().join(format!(
+            "rust_bert_binding_{}_{}",
+            std::process::id(),
+            COUNTER.fetch_add(1, Ordering::Relaxed)
+        ));
+        fs::create_dir_all(&path).map_err(overlay_error)?;
+        Ok(ScratchDir { path })
+    }
+}
+
+impl Drop for ScratchDir {
+    fn drop(&mut self) {
+        let _ = fs::remove_dir_all(&self.path);
+    }
+}
@@ -0,0 +1,119 @@
+//! Sequence classification reporting every label of the model.
+//!
+//! rust-bert's `SequenceClassificationModel` returns the best label with `predict`, and
+//! only the labels above a threshold with `predict_multilabel`. This module runs the
+//! classifier itself so that callers get the score of every label from `id2label`.
+
+use rust_bert::pipelines::common::{ConfigOption, TokenizerOption};
+use rust_bert::pipelines::sequence_classification::{
+    SequenceClassificationConfig, SequenceClassificationOption,
+};
+use rust_bert::resources::ResourceProvider;
+use rust_bert::RustBertError;
+use tch::nn::VarStore;
+use tch::{no_grad, Device, Kind};
+
+use crate::encoding::{encode_batch, load_tokenizer, load_weights};
+
+/// Score of one label for one input
+pub struct LabelScore {
+    pub label: String,
+    pub id: i64,
+    pub score: f32,
+}
+
+pub struct Classifier {
+    tokenizer: TokenizerOption,
+    model: SequenceClassificationOption,
+    /// Label names indexed by id
+    labels: Vec<String>,
+    device: Device,
+    _var_store: VarStore,
+}
+
+impl Classifier {
+    pub fn new(config: SequenceClassificationConfig) -> Result<Classifier, RustBertError> {
+        let tokenizer = load_tokenizer(
+            config.model_type,
+            &*config.vocab_resource,
+            config.merges_resource.as_deref(),
+            config.lower_case,
+            config.strip_accents,
+            config.add_prefix_space,
+        )?;
+
+        let config_path = config.config_resource.get_local_path()?;
+        let mut var_store = VarStore::new(config.device);
+        let model_config = ConfigOption::from_file(config.model_type, config_path);
+        let model = SequenceClassificationOption::new(config.model_type, var_store.root(), &model_config)?;
+        load_weights(&mut var_store, &config.model_resource, config.kind)?;
+
+        let mapping = model_config.get_label_mapping();
+        let labels = (0..mapping.len() as i64)
+            .map(|id| {
+                mapping
+                    .get(&id)
+                    .cloned()
+                    .unwrap_or_else(|| format!("LABEL_{}", id))
+            })
+            .collect();
+
+        Ok(Classifier {
+            tokenizer,
+            model,
+            labels,
+            device: config.device,
+            _var_store: var_store,
+        })
+    }
+
+    /// Scores every label for each text, best first. Scores are a softmax over the labels,
+    /// or independent sigmoids with `multi_label`.
+    pub fn predict<S>(&self, texts: &[S], multi_label: bool) -> Result<Vec<Vec<LabelScore>>, RustBertError>
+    where
+        S: AsRef<str> + Sync,
+    {
+        if texts.is_empty() {
+            return Ok(Vec::new());
+        }
+
+        let batch = encode_batch(&self.tokenizer, texts, self.device);
+        let logits = no_grad(|| {
+            self.model.forward_t(
+                Some(&batch.input_ids),
+                Some(&batch.attention_mask),
+                None,
+                None,
+                None,
+                false,
+            )
+        });
+        let scores = if multi_label {
+            logits.sigmoid()
+        } else {
+            logits.softmax(-1, Kind::Float)
+        };
+        let scores = scores.to_kind(Kind::Float).to(Device::Cpu);
+
+        let mut results = Vec::with_capacity(texts.len());
+        for i in 0..texts.len() {
+            let row = Vec::<f32>::try_from(scores.get(i as i64))?;
+            let mut labels: Vec<LabelScore> = row
+                .into_iter()
+                .enumerate()
+                .map(|(id, score)| LabelScore {
+                    label: self
+                        .labels
+                        .get(id)
+                        .cloned()
+                        .unwrap_or_else(|| format!("LABEL_{}", id)),
+                    id: id as i64,
+                    score,
+                })
+                .collect();
+            labels.sort_by(|a, b| b.score.total_cmp(&a.score));
+            results.push(labels);
+        }
+        Ok(results)
+    }
+}
@@ -0,0 +1,405 @@
+//! Summarization on top of the rust-bert generators.
+//!
+//! The `SummarizationModel` pipeline fixes its generation settings at construction. Going
+//! through `SummarizationOption` directly lets each call override lengths, beams and
+//! length penalty, which is what strict output bounds need.
+//!
+//! Documents longer than the model input are summarized hierarchically: the text is cut
+//! into chunks on sentence and paragraph boundaries, the chunks are summarized as a batch
+//! and the concatenation of their summaries is summarized again until it fits the target
+//! length.
+
+use rust_bert::bart::{BartConfigResources, BartMergesResources, BartModelResources, BartVocabResources};
+use rust_bert::longt5::{LongT5ConfigResources, LongT5ModelResources, LongT5VocabResources};
+use rust_bert::pegasus::{PegasusConfigResources, PegasusModelResources, PegasusVocabResources};
+use rust_bert::pipelines::common::{ModelResource, ModelType, TokenizerOption};
+use rust_bert::pipelines::generation_utils::{GenerateOptions as CallOptions, LanguageGenerator};
+use rust_bert::pipelines::summarization::{SummarizationConfig, SummarizationOption};
+use rust_bert::prophetnet::{
+    ProphetNetConfigResources, ProphetNetModelResources, ProphetNetVocabResources,
+};
+use rust_bert::resources::RemoteResource;
+use rust_bert::t5::{T5ConfigResources, T5ModelResources, T5VocabResources};
+
+use crate::cancel::{check_cancelled, CancelToken};
+use crate::error::{ErrorCode, FfiError};
+use crate::{inference_error, load_error};
+
+/// Prefix T5 checkpoints expect in front of the text to summarize
+const T5_PREFIX: &str = "summarize: ";
+
+pub struct Summarizer {
+    model: SummarizationOption,
+}
+
+impl Summarizer {
+    pub fn new(config: SummarizationConfig) -> Result<Summarizer, FfiError> {
+        Ok(Summarizer {
+            model: SummarizationOption::new(config).map_err(load_error)?,
+        })
+    }
+
+    /// Summarizes `texts` once `cancel` has been checked. The decoder cannot be resumed
+    /// from a partial output, so a started batch runs to completion. With
+    /// `num_return_sequences` = n the output holds n summaries per text, text-major.
+    pub fn summarize<S>(
+        &self,
+        texts: &[S],
+        options: Option<CallOptions>,
+        cancel: *const CancelToken,
+    ) -> Result<Vec<String>, FfiError>
+    where
+        S: AsRef<str> + Send + Sync,
+    {
+        check_cancelled(cancel)?;
+        let outputs = match &self.model {
+            SummarizationOption::Bart(model) => model.generate(Some(texts), options),
+            SummarizationOption::T5(model) => {
+                let prefixed: Vec<String> = texts
+                    .iter()
+                    .map(|text| format!("{}{}", T5_PREFIX, text.as_ref()))
+                    .collect();
+                model.generate(Some(prefixed.as_slice()), options)
+            }
+            SummarizationOption::LongT5(model) => model.generate(Some(texts), options),
+            SummarizationOption::ProphetNet(model) => model.generate(Some(texts), options),
+            SummarizationOption::Pegasus(model) => model.generate(Some(texts), options),
+        }
+        .map_err(inference_error)?;
+        Ok(outputs.into_iter().map(|output| output.text).collect())
+    }
+
+    fn tokenizer(&self) -> &TokenizerOption {
+        self.model.get_tokenizer()
+    }
+
+    fn count_tokens(&self, text: &str) -> usize {
+        self.tokenizer().tokenize(text).len()
+    }
+
+    /// Summarizes a text of any length. Chunks of at most `options.chunk_size` tokens are
+    /// summarized with `overrides`, then their concatenation is summarized again, chunked
+    /// if it still does not fit, until the summary is at most `options.target_length`
+    /// tokens long.
+    pub fn summarize_long(
+        &self,
+        text: &str,
+        options: &LongSummarizeOptions,
+        overrides: Option<SummarizeOverrides>,
+        cancel: *const CancelToken,
+    ) -> Result<LongSummary, FfiError> {
+        // A single summary per chunk, whatever the model was created with.
+        let call_options = |max_length: Option<i64>| {
+            let mut call_options = overrides.map(|o| o.call_options()).unwrap_or_default();
+            call_options.num_return_sequences = Some(1);
+            if let Some(max_length) = max_length {
+                call_options.max_length = Some(max_length);
+                call_options.min_length = call_options.min_length.map(|min| min.min(max_length));
+            }
+            call_options
+        };
+
+        let mut current = text.to_string();
+        let mut chunk_summaries: Option<Vec<String>> = None;
+        loop {
+            let tokens = self.count_tokens(&current);
+            if chunk_summaries.is_some() && tokens <= options.target_length {
+                break;
+            }
+
+            let chunks = self.chunks(&current, options.chunk_size);
+            if chunks.len() <= 1 {
+                let summary = self
+                    .summarize(&[current.as_str()], Some(call_options(Some(options.target_length as i64))), cancel)?
+                    .pop()
+                    .unwrap_or_default();
+                let chunk_summaries = chunk_summaries.unwrap_or_else(|| vec![summary.clone()]);
+                return Ok(LongSummary {
+                    summary,
+                    chunk_summaries,
+                });
+            }
+
+            let summaries = self.summarize(&chunks, Some(call_options(None)), cancel)?;
+            let joined = summaries.join("\n");
+            if self.count_tokens(&joined) >= tokens {
+                return Err(FfiError::new(
+                    ErrorCode::Inference,
+                    "chunk summaries are not shorter than their input, lower the summary max length",
+                ));
+            }
+            chunk_summaries.get_or_insert(summaries);
+            current = joined;
+        }
+
+        Ok(LongSummary {
+            summary: current,
+            chunk_summaries: chunk_summaries.unwrap_or_default(),
+        })
+    }
+
+    /// Cuts `text` into chunks of at most `size` tokens, ending chunks between sentences
+    /// and, when one falls in the second half of a chunk, between paragraphs. Sentences
+    /// longer than a chunk are cut between words.
+    fn chunks(&self, text: &str, size: usize) -> Vec<String> {
+        let mut chunks = Vec::new();
+        let mut current: Vec<(Segment, usize)> = Vec::new();
+        let mut tokens = 0;
+        let flush = |chunk: &[(Segment, usize)], chunks: &mut Vec<String>| {
+            if let (Some((first, _)), Some((last, _))) = (chunk.first(), chunk.last()) {
+                chunks.push(text[first.start..last.end].to_string());
+            }
+        };
+
+        for segment in sentences(text) {
+            for segment in self.fit(text, segment, size) {
+                let length = self.count_tokens(&text[segment.start..segment.end]);
+                if tokens + length > size && !current.is_empty() {
+                    let mut cut = current.len();
+                    let mut before = 0;
+                    for (i, (segment, segment_length)) in current.iter().enumerate() {
+                        if i > 0 && segment.paragraph && before >= size / 2 && tokens - before + length <= size {
+                            cut = i;
+                        }
+                        before += segment_length;
+                    }
+                    let rest = current.split_off(cut);
+                    flush(&current, &mut chunks);
+                    tokens = rest.iter().map(|(_, length)| length).sum();
+                    current = rest;
+                }
+                tokens += length;
+                current.push((segment, length));
+            }
+        }
+        flush(&current, &mut chunks);
+        chunks
+    }
+
+    /// Splits a sentence of more than `size` tokens between words.
+    fn fit(&self, text: &str, segment: Segment, size: usize) -> Vec<Segment> {
+        if self.count_tokens(&text[segment.start..segment.end]) <= size {
+            return vec![segment];
+        }
+        let mut pieces: Vec<Segment> = Vec::new();
+        let mut tokens = 0;
+        let sentence = &text[segment.start..segment.end];
+        for (offset, word) in words(sentence) {
+            let (start, end) = (segment.start + offset, segment.start + offset + word.len());
+            let length = self.count_tokens(word);
+            match pieces.last_mut() {
+                Some(piece) if tokens + length <= size => {
+                    piece.end = end;
+                    tokens += length;
+                }
+                _ => {
+                    pieces.push(Segment {
+                        start,
+                        end,
+                        paragraph: pieces.is_empty() && segment.paragraph,
+                    });
+                    tokens = length;
+                }
+            }
+        }
+        pieces
+    }
+}
+
+/// Options of `Summarizer::summarize_long`, mirrored by the Go `LongSummaryConfig`.
+#[repr(C)]
+#[derive(Clone, Copy)]
+pub struct LongSummarizeOptions {
+    /// Tokens per chunk
+    pub chunk_size: usize,
+    /// Longest final summary, in tokens
+    pub target_length: usize,
+}
+
+impl LongSummarizeOptions {
+    pub fn from_ptr(options: *const LongSummarizeOptions) -> Result<LongSummarizeOptions, FfiError> {
+        let options = *unsafe { options.as_ref() }
+            .ok_or_else(|| FfiError::invalid_input("long summarization options are NULL"))?;
+        if options.chunk_size == 0 || options.target_length == 0 {
+            return Err(FfiError::invalid_input("chunk size and target length must be at least 1"));
+        }
+        Ok(options)
+    }
+}
+
+pub struct LongSummary {
+    pub summary: String,
+    /// Summaries of the chunks of the original text, in order
+    pub chunk_summaries: Vec<String>,
+}
+
+/// Byte span of a sentence
+struct Segment {
+    start: usize,
+    end: usize,
+    /// The sentence starts a paragraph
+    paragraph: bool,
+}
+
+/// Splits `text` into sentences, ending one after '.', '!' or '?' followed by a space and
+/// at every line break. Paragraphs start after blank lines.
+fn sentences(text: &str) -> Vec<Segment> {
+    let mut segments = Vec::new();
+    let mut paragraph = true;
+    let mut line_start = 0;
+    for line in text.split_inclusive('\n') {
+        let content = line.trim_end();
+        if content.trim_start().is_empty() {
+            paragraph = true;
+        }
+        let mut start = None;
+        let mut after_terminal = false;
+        for (i, c) in content.char_indices() {
+            if c.is_whitespace() {
+                if after_terminal {
+                    if let Some(start) = start.take() {
+                        segments.push(Segment {
+                            start: line_start + start,
+                            end: line_start + i,
+                            paragraph,
+                        });
+                        paragraph = false;
+                    }
+                }
+                after_terminal = false;
+                continue;
+            }
+            start.get_or_insert(i);
+            after_terminal = matches!(c, '.' | '!' | '?');
+        }
+        if let Some(start) = start {
+            segments.push(Segment {
+                start: line_start + start,
+                end: line_start + content.len(),
+                paragraph,
+            });
+            paragraph = false;
+        }
+        line_start += line.len();
+    }
+    segments
+}
+
+/// Words of `text` with their byte offsets.
+fn words(text: &str) -> impl Iterator<Item = (usize, &str)> {
+    text.split_whitespace()
+        .map(move |word| (word.as_ptr() as usize - text.as_ptr() as usize, word))
+}
+
+/// Per-call overrides, mirrored by the Go `SummarizeOptions` struct. Zero values keep the
+/// setting the model was created with.
+#[repr(C)]
+#[derive(Clone, Copy, Debug)]
+pub struct SummarizeOverrides {
+    pub min_length: i64,
+    pub max_length: i64,
+    pub num_beams: i64,
+    pub length_penalty: f64,
+    pub no_repeat_ngram_size: i64,
+}
+
+impl SummarizeOverrides {
+    /// Reads overrides passed by pointer. NULL means no overrides.
+    pub fn from_ptr(overrides: *const SummarizeOverrides) -> Result<Option<SummarizeOverrides>, FfiError> {
+        let overrides = match unsafe { overrides.as_ref() } {
+            Some(overrides) => *overrides,
+            None => return Ok(None),
+        };
+        if overrides.min_length < 0
+            || overrides.max_length < 0
+            || overrides.num_beams < 0
+            || overrides.length_penalty < 0.0
+            || overrides.no_repeat_ngram_size < 0
+        {
+            return Err(FfiError::invalid_input("summarize options cannot be negative"));
+        }
+        if overrides.max_length > 0 && overrides.min_length > overrides.max_length {
+            return Err(FfiError::invalid_input(format!(
+                "min_length ({}) exceeds max_length ({})",
+                overrides.min_length, overrides.max_length
+            )));
+        }
+        Ok(Some(overrides))
+    }
+
+    pub fn call_options(&self) -> CallOptions<'static> {
+        let positive = |value: i64| (value > 0).then_some(value);
+        CallOptions {
+            min_length: positive(self.min_length),
+            max_length: positive(self.max_length),
+            num_beams: positive(self.num_beams),
+            length_penalty: (self.length_penalty > 0.0).then_some(self.length_penalty),
+            no_repeat_ngram_size: positive(self.no_repeat_ngram_size),
+            ..Default::default()
+        }
+    }
+}
+
+/// Configuration of the pretrained checkpoint selected by the Go `SummarizationModelKind`.
+pub fn preset_config(kind: i32) -> Result<SummarizationConfig, FfiError> {
+    let config = match kind {
+        0 => SummarizationConfig::default(),
+        1 => SummarizationConfig::new(
+            ModelType::Bart,
+            ModelResource::Torch(Box::new(RemoteResource::from_pretrained(
+                BartModelResources::DISTILBART_CNN_6_6,
+            ))),
+            RemoteResource::from_pretrained(BartConfigResources::DISTILBART_CNN_6_6),
+            RemoteResource::from_pretrained(BartVocabResources::DISTILBART_CNN_6_6),
+            Some(RemoteResource::from_pretrained(BartMergesResources::DISTILBART_CNN_6_6)),
+        ),
+        2 => SummarizationConfig::new(
+            ModelType::T5,
+            ModelResource::Torch(Box::new(RemoteResource::from_pretrained(T5ModelResources::T5_SMALL))),
+            RemoteResource::from_pretrained(T5ConfigResources::T5_SMALL),
+            RemoteResource::from_pretrained(T5VocabResources::T5_SMALL),
+            None::<RemoteResource>,
+        ),
+        3 => SummarizationConfig::new(
+            ModelType::T5,
+            ModelResource::Torch(Box::new(RemoteResource::from_pretrained(T5ModelResources::T5_BASE))),
+            RemoteResource::from_pretrained(T5ConfigResources::T5_BASE),
+            RemoteResource::from_pretrained(T5VocabResources::T5_BASE),
+            None::<RemoteResource>,
+        ),
+        4 => SummarizationConfig::new(
+            ModelType::Pegasus,
+            ModelResource::Torch(Box::new(RemoteResource::from_pretrained(
+                PegasusModelResources::CNN_DAILYMAIL,
+            ))),
+            RemoteResource::from_pretrained(PegasusConfigResources::CNN_DAILYMAIL),
+            RemoteResource::from_pretrained(PegasusVocabResources::CNN_DAILYMAIL),
+            None::<RemoteResource>,
+        ),
+        5 => SummarizationConfig::new(
+            ModelType::ProphetNet,
+            ModelResource::Torch(Box::new(RemoteResource::from_pretrained(
+                ProphetNetModelResources::PROPHETNET_LARGE_CNN_DM,
+            ))),
+            RemoteResource::from_pretrained(ProphetNetConfigResources::PROPHETNET_LARGE_CNN_DM),
+            RemoteResource::from_pretrained(ProphetNetVocabResources::PROPHETNET_LARGE_CNN_DM),
+            None::<RemoteResource>,
+        ),
+        6 => SummarizationConfig::new(
+            ModelType::LongT5,
+            ModelResource::Torch(Box::new(RemoteResource::from_pretrained(
+                LongT5ModelResources::TGLOBAL_BASE_BOOK_SUMMARY,
+            ))),
+            RemoteResource::from_pretrained(LongT5ConfigResources::TGLOBAL_BASE_BOOK_SUMMARY),
+            RemoteResource::from_pretrained(LongT5VocabResources::TGLOBAL_BASE_BOOK_SUMMARY),
+            None::<RemoteResource>,
+        ),
+        _ => {
+            return Err(FfiError::invalid_input(format!(
+                "unknown summarization model kind {}",
+                kind
+            )))
+        }
+    };
+    Ok(config)
+}
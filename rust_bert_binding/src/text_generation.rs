@@ -0,0 +1,293 @@
+//! Text generation on top of the rust-bert generators.
+//!
+//! The `TextGenerationModel` pipeline only returns finished sequences. Going through
+//! `TextGenerationOption` directly lets cancellable calls and streaming run the decoding
+//! loop a few steps at a time, continuing each time from the ids generated so far. The
+//! pipeline's default prefix for XLNet is kept: without a prefix of its own, a call to an
+//! XLNet model is conditioned on the same text, trimmed from the output.
+
+use rust_bert::pipelines::common::{ModelType, TokenizerOption};
+use rust_bert::pipelines::generation_utils::{GenerateOptions as CallOptions, LanguageGenerator};
+use rust_bert::pipelines::text_generation::{TextGenerationConfig, TextGenerationOption};
+use tch::{Device, Tensor};
+
+use crate::cancel::{check_cancelled, CancelToken};
+use crate::encoding::MAX_LENGTH;
+use crate::error::FfiError;
+use crate::generation::GenerateOptions;
+use crate::{inference_error, load_error};
+
+/// Decoding steps run between two checks of the cancellation token. Every resumption
+/// encodes the sequence again, so fewer checks are cheaper.
+const STEPS_PER_CHECK: i64 = 8;
+
+/// Prefix the rust-bert pipeline gives XLNet prompts when the call passes none: XLNet
+/// generates poorly from a short context.
+const XLNET_PREFIX: &str = "In 1991, the remains of Russian Tsar Nicholas II and his family \
+(except for Alexei and Maria) are discovered. \
+The voice of Nicholas's young son, Tsarevich Alexei Nikolaevich, narrates the \
+remainder of the story. 1883 Western Siberia, \
+a young Grigori Rasputin is asked by his father and a group of men to perform magic. \
+Rasputin has a vision and denounces one of the men as a horse thief. Although his \
+father initially slaps him for making such an accusation, Rasputin watches as the \
+man is chased outside and beaten. Twenty years later, Rasputin sees a vision of \
+the Virgin Mary, prompting him to become a priest. Rasputin quickly becomes famous, \
+with people, even a bishop, begging for his blessing. <eod> </s> <eos>";
+
+/// Runs the same generator method whatever the architecture of the model.
+macro_rules! with_generator {
+    ($model:expr, $generator:ident => $call:expr) => {
+        match $model {
+            TextGenerationOption::GPT($generator) => $call,
+            TextGenerationOption::GPT2($generator) => $call,
+            TextGenerationOption::GPTNeo($generator) => $call,
+            TextGenerationOption::GPTJ($generator) => $call,
+            TextGenerationOption::XLNet($generator) => $call,
+            TextGenerationOption::Reformer($generator) => $call,
+            TextGenerationOption::T5($generator) => $call,
+        }
+    };
+}
+
+pub struct TextGenerator {
+    model: TextGenerationOption,
+    min_length: i64,
+    max_length: Option<i64>,
+    num_beams: i64,
+    num_return_sequences: i64,
+    device: Device,
+    /// Prefix of the calls that pass none
+    prefix: Option<&'static str>,
+}
+
+impl TextGenerator {
+    pub fn new(config: TextGenerationConfig) -> Result<TextGenerator, FfiError> {
+        let (min_length, max_length, device) = (config.min_length, config.max_length, config.device);
+        let (num_beams, num_return_sequences) = (config.num_beams, config.num_return_sequences);
+        let prefix = match config.model_type {
+            ModelType::XLNet => Some(XLNET_PREFIX),
+            _ => None,
+        };
+        Ok(TextGenerator {
+            model: TextGenerationOption::new(config).map_err(load_error)?,
+            min_length,
+            max_length,
+            num_beams,
+            num_return_sequences,
+            device,
+            prefix,
+        })
+    }
+
+    fn tokenizer(&self) -> &TokenizerOption {
+        self.model.get_tokenizer()
+    }
+
+    /// Whether generation can be resumed from a partial output: the decoder-only models
+    /// can, encoder-decoder T5 cannot.
+    fn resumable(&self) -> bool {
+        !matches!(self.model, TextGenerationOption::T5(_))
+    }
+
+    /// Continues each prompt, returning the prompts with their continuation. `prefix`, or
+    /// the default prefix of the model when it is None, is prepended to every prompt to
+    /// condition the model and stripped from the outputs; the length limits apply to the
+    /// prompts without it. `cancel` is checked every
+    /// `STEPS_PER_CHECK` decoding steps; beam search and T5 run as a single batch once
+    /// it has been checked.
+    pub fn generate<S>(&self, prompts: &[S], prefix: Option<&str>, cancel: *const CancelToken) -> Result<Vec<String>, FfiError>
+    where
+        S: AsRef<str> + Send + Sync,
+    {
+        let (prompts, prefix_length): (Vec<String>, usize) = match prefix.or(self.prefix) {
+            Some(prefix) => (
+                prompts
+                    .iter()
+                    .map(|prompt| format!("{} {}", prefix, prompt.as_ref()))
+                    .collect(),
+                self.prefix_length(prefix),
+            ),
+            None => (prompts.iter().map(|prompt| prompt.as_ref().to_string()).collect(), 0),
+        };
+        let min_length = self.min_length + prefix_length as i64;
+        let max_length = self.max_length.map(|length| length + prefix_length as i64);
+
+        check_cancelled(cancel)?;
+        let sequences = if cancel.is_null() || self.num_beams > 1 || !self.resumable() {
+            let options = CallOptions {
+                min_length: Some(min_length),
+                max_length,
+                ..Default::default()
+            };
+            with_generator!(&self.model, model => model.generate_indices(Some(prompts.as_slice()), Some(options)))
+                .map_err(inference_error)?
+                .into_iter()
+                .map(|output| output.indices)
+                .collect()
+        } else {
+            // Without beams the sequences of a batch are independent: resume each alone.
+            let max_length = max_length.unwrap_or(MAX_LENGTH as i64);
+            let options = |max_length| CallOptions {
+                min_length: Some(min_length),
+                max_length: Some(max_length),
+                num_return_sequences: Some(1),
+                ..Default::default()
+            };
+            let mut sequences = Vec::with_capacity(prompts.len() * self.num_return_sequences as usize);
+            for prompt in &prompts {
+                for _ in 0..self.num_return_sequences {
+                    let ids = self.prompt_ids(prompt);
+                    sequences.push(self.decode_steps(ids, max_length, STEPS_PER_CHECK, cancel, options, &mut |_| true)?);
+                }
+            }
+            sequences
+        };
+        Ok(sequences
+            .iter()
+            .map(|indices| {
+                let start = prefix_length.min(indices.len());
+                self.tokenizer().decode(&indices[start..], true, true)
+            })
+            .collect())
+    }
+
+    /// Continues `prompt`, calling `on_text` with the text each decoding step adds until
+    /// the model emits its end of sequence token, `max_length` is reached, `on_text`
+    /// returns false or `cancel` is cancelled. Only greedy decoding and sampling can be
+    /// streamed. The default prefix of the model conditions the prompt as in `generate`.
+    pub fn stream<F>(
+        &self,
+        prompt: &str,
+        options: &GenerateOptions,
+        cancel: *const CancelToken,
+        mut on_text: F,
+    ) -> Result<(), FfiError>
+    where
+        F: FnMut(&str) -> bool,
+    {
+        if !self.resumable() {
+            return Err(FfiError::invalid_input("T5 models cannot be streamed"));
+        }
+        let (ids, prefix_length) = match self.prefix {
+            Some(prefix) => (self.prompt_ids(&format!("{} {}", prefix, prompt)), self.prefix_length(prefix) as i64),
+            None => (self.prompt_ids(prompt), 0),
+        };
+        let prompt_length = ids.len();
+        let tokenizer = self.tokenizer();
+        let continuation = |ids: &[i64]| tokenizer.decode(&ids[prompt_length.min(ids.len())..], true, false);
+
+        // A character can span several byte-level tokens: wait for the rest of it before
+        // sending the text.
+        let mut emitted = String::new();
+        let mut on_step = |ids: &[i64]| {
+            let text = continuation(ids);
+            text.ends_with(char::REPLACEMENT_CHARACTER) || send(&mut emitted, &text, &mut on_text)
+        };
+        let max_length = options.max_length().map_or(MAX_LENGTH as i64, |length| length + prefix_length);
+        let min_length = options.min_length + prefix_length;
+        let ids = self.decode_steps(
+            ids,
+            max_length,
+            1,
+            cancel,
+            |max_length| stream_options(options, min_length, max_length),
+            &mut on_step,
+        )?;
+
+        // The last step may have held back a partial character: send what is left.
+        send(&mut emitted, &continuation(&ids), &mut on_text);
+        Ok(())
+    }
+
+    /// Length of `prefix` in tokens, by which the length limits are offset
+    fn prefix_length(&self, prefix: &str) -> usize {
+        self.tokenizer().tokenize(prefix).len()
+    }
+
+    /// Token ids of `prompt` without special tokens, as the generators encode prompts,
+    /// or the beginning of sequence token for an empty prompt.
+    fn prompt_ids(&self, prompt: &str) -> Vec<i64> {
+        let tokenizer = self.tokenizer();
+        let mut ids = tokenizer.convert_tokens_to_ids(&tokenizer.tokenize(prompt));
+        if ids.is_empty() {
+            ids.extend(tokenizer.get_bos_id());
+        }
+        ids
+    }
+
+    /// Extends `ids` by up to `steps` decoding steps at a time until the sequence ends or
+    /// is `max_length` tokens long, checking `cancel` before and passing the ids to
+    /// `on_step` after every resumption. `options` builds the options of a resumption
+    /// from the length it stops at. Returns the ids, with the prompt, when generation
+    /// ends or `on_step` returns false.
+    fn decode_steps<'a, O>(
+        &self,
+        mut ids: Vec<i64>,
+        max_length: i64,
+        steps: i64,
+        cancel: *const CancelToken,
+        options: O,
+        on_step: &mut dyn FnMut(&[i64]) -> bool,
+    ) -> Result<Vec<i64>, FfiError>
+    where
+        O: Fn(i64) -> CallOptions<'a>,
+    {
+        let eos = self.tokenizer().get_eos_id();
+        loop {
+            let length = ids.len() as i64;
+            if length >= max_length {
+                return Ok(ids);
+            }
+            check_cancelled(cancel)?;
+            let target = max_length.min(length + steps);
+            let input_ids = Tensor::from_slice(&ids).unsqueeze(0).to(self.device);
+            let output = with_generator!(
+                &self.model,
+                model => model.generate_from_ids_and_past(input_ids, None, Some(options(target)))
+            )
+            .map_err(inference_error)?;
+            let next = output.into_iter().next().map(|output| output.indices).unwrap_or_default();
+            // A sequence stopping short of the target or on the end of sequence token is
+            // finished.
+            let ended = (next.len() as i64) < target || next.len() <= ids.len() || next.last().copied() == eos;
+            ids = next;
+            if !on_step(&ids) || ended {
+                return Ok(ids);
+            }
+        }
+    }
+}
+
+/// Passes the part of `text` past `emitted` to `on_text` when `text` extends it, and
+/// reports whether generation should go on.
+fn send<F>(emitted: &mut String, text: &str, on_text: &mut F) -> bool
+where
+    F: FnMut(&str) -> bool,
+{
+    match text.strip_prefix(emitted.as_str()) {
+        Some(delta) if !delta.is_empty() => {
+            let go_on = on_text(delta);
+            *emitted = text.to_string();
+            go_on
+        }
+        _ => true,
+    }
+}
+
+/// Options of a streamed resumption stopping at `max_length` tokens: the sampling
+/// settings of the call, `min_length` and a single sequence.
+fn stream_options<'a>(options: &GenerateOptions, min_length: i64, max_length: i64) -> CallOptions<'a> {
+    CallOptions {
+        min_length: Some(min_length),
+        max_length: Some(max_length),
+        do_sample: Some(options.do_sample),
+        temperature: Some(options.temperature),
+        top_k: Some(options.top_k),
+        top_p: Some(options.top_p),
+        repetition_penalty: Some(options.repetition_penalty),
+        no_repeat_ngram_size: Some(options.no_repeat_ngram_size),
+        num_beams: Some(1),
+        num_return_sequences: Some(1),
+        ..Default::default()
+    }
+}
@@ -0,0 +1,70 @@
+//! libtorch thread pools.
+//!
+//! The intra-op count is how many threads a single operator is split over. With OpenMP
+//! it is kept per OS thread, so the Go side sets it on the thread of every call once it
+//! has been configured. The inter-op pool runs independent operators concurrently and
+//! can only be sized before its first use.
+
+use std::panic::{self, AssertUnwindSafe};
+use std::sync::atomic::{AtomicBool, Ordering};
+
+use crate::error::{ffi_call, FfiError};
+
+/// Whether `set_num_interop_threads` has been called, successfully or not: libtorch
+/// sizes the pool once at most.
+static INTEROP_SET: AtomicBool = AtomicBool::new(false);
+
+// ============================================================================
+// Thread FFI Functions
+// ============================================================================
+
+/// Set the number of intra-op threads used by the calling thread and by the threads
+/// libtorch starts from now on.
+#[no_mangle]
+pub extern "C" fn set_num_threads(count: i32) -> bool {
+    ffi_call(false, || {
+        if count < 1 {
+            return Err(FfiError::invalid_input(format!(
+                "number of threads must be at least 1, got {}",
+                count
+            )));
+        }
+        tch::set_num_threads(count);
+        Ok(true)
+    })
+}
+
+/// Number of intra-op threads of the calling thread
+#[no_mangle]
+pub extern "C" fn get_num_threads() -> i32 {
+    tch::get_num_threads()
+}
+
+/// Set the number of inter-op threads. Calls after the first are rejected without
+/// reaching libtorch, which also refuses once the pool has started.
+#[no_mangle]
+pub extern "C" fn set_num_interop_threads(count: i32) -> bool {
+    ffi_call(false, || {
+        if count < 1 {
+            return Err(FfiError::invalid_input(format!(
+                "number of inter-op threads must be at least 1, got {}",
+                count
+            )));
+        }
+        if INTEROP_SET.swap(true, Ordering::SeqCst) {
+            return Err(FfiError::invalid_input("the number of inter-op threads has already been set"));
+        }
+        // tch panics when a model has already started the pool; that cannot be told
+        // beforehand.
+        panic::catch_unwind(AssertUnwindSafe(|| tch::set_num_interop_threads(count))).map_err(|_| {
+            FfiError::invalid_input("the number of inter-op threads can only be set before any model runs")
+        })?;
+        Ok(true)
+    })
+}
+
+/// Number of inter-op threads
+#[no_mangle]
+pub extern "C" fn get_num_interop_threads() -> i32 {
+    tch::get_num_interop_threads()
+}
@@ -0,0 +1,85 @@
+//! Token classification with any checkpoint and a choice of label aggregation.
+//!
+//! `NERModel` and `POSModel` keep only their default configs and labels; this module
+//! exposes rust-bert's `TokenClassificationModel` with every token it labels.
+
+use rust_bert::pipelines::token_classification::{
+    LabelAggregationOption, Token, TokenClassificationConfig, TokenClassificationModel,
+};
+
+use crate::device::device_from_code;
+use crate::error::FfiError;
+use crate::load_error;
+
+/// Label of the tokens outside of any entity in IOB tagging schemes
+const OUTSIDE_LABEL: &str = "O";
+
+/// Options for the token classification constructors, mirrored by the Go
+/// `TokenClassificationConfig`.
+#[repr(C)]
+pub struct TokenClassificationOptions {
+    /// 0 first, 1 last, 2 average, 3 max, 4 mode, 5 none (one result per sub-token)
+    pub aggregation: i32,
+    /// Drop the tokens labelled "O"
+    pub ignore_outside: bool,
+    /// Device code, see `device_from_code`
+    pub device: i32,
+}
+
+pub struct TokenTagger {
+    model: TokenClassificationModel,
+    consolidate_sub_tokens: bool,
+    ignore_outside: bool,
+}
+
+impl TokenTagger {
+    /// Loads the model of `config` with the aggregation and device of `options`.
+    pub fn new(
+        mut config: TokenClassificationConfig,
+        options: *const TokenClassificationOptions,
+    ) -> Result<TokenTagger, FfiError> {
+        let options = unsafe { options.as_ref() }
+            .ok_or_else(|| FfiError::invalid_input("token classification options are NULL"))?;
+
+        let (aggregation, consolidate_sub_tokens) = match options.aggregation {
+            0 => (LabelAggregationOption::First, true),
+            1 => (LabelAggregationOption::Last, true),
+            2 => (LabelAggregationOption::Mean, true),
+            3 => (LabelAggregationOption::Custom(Box::new(most_confident)), true),
+            4 => (LabelAggregationOption::Mode, true),
+            5 => (LabelAggregationOption::First, false),
+            other => return Err(FfiError::invalid_input(format!("unknown label aggregation {}", other))),
+        };
+        config.label_aggregation_function = aggregation;
+        config.device = device_from_code(options.device)?;
+
+        Ok(TokenTagger {
+            model: TokenClassificationModel::new(config).map_err(load_error)?,
+            consolidate_sub_tokens,
+            ignore_outside: options.ignore_outside,
+        })
+    }
+
+    /// Labels the words of each text, or its sub-tokens when they are not consolidated.
+    pub fn predict<S>(&self, texts: &[S]) -> Vec<Vec<Token>>
+    where
+        S: AsRef<str>,
+    {
+        let mut tokens = self.model.predict(texts, self.consolidate_sub_tokens, false);
+        if self.ignore_outside {
+            for tokens in tokens.iter_mut() {
+                tokens.retain(|token| token.label != OUTSIDE_LABEL);
+            }
+        }
+        tokens
+    }
+}
+
+/// Labels a word with the label of its most confident sub-token.
+fn most_confident(tokens: &[Token]) -> (i64, String) {
+    tokens
+        .iter()
+        .max_by(|a, b| a.score.total_cmp(&b.score))
+        .map(|token| (token.label_index, token.label.clone()))
+        .unwrap_or_else(|| (0, OUTSIDE_LABEL.to_string()))
+}
@@ -0,0 +1,143 @@
+//! Tokenization with the rust-tokenizers tokenizers the pipelines use.
+
+use rust_bert::pipelines::common::{ModelType, TokenizerOption};
+use rust_bert::RustBertError;
+use rust_tokenizers::tokenizer::TruncationStrategy;
+use rust_tokenizers::TokenizedInput;
+use std::collections::HashMap;
+use std::path::Path;
+
+use crate::error::FfiError;
+
+/// Token of an encoded input
+pub struct EncodedToken {
+    pub id: i64,
+    pub token: String,
+    /// Character span in the input, `None` for the special tokens added by the tokenizer
+    pub offset: Option<(u32, u32)>,
+    pub special: bool,
+    /// 0 for the tokens of the first text, 1 for those of the second text of a pair
+    pub segment: i8,
+}
+
+pub struct Encoding {
+    pub tokens: Vec<EncodedToken>,
+    /// Number of tokens dropped to fit the maximum length
+    pub num_truncated: usize,
+    /// Ids of the dropped tokens, preceded by `stride` tokens of overlap
+    pub overflowing: Vec<i64>,
+}
+
+/// Truncation selected by the Go `Truncation` type.
+pub fn truncation_strategy(code: i32) -> Result<TruncationStrategy, FfiError> {
+    match code {
+        0 => Ok(TruncationStrategy::LongestFirst),
+        1 => Ok(TruncationStrategy::OnlyFirst),
+        2 => Ok(TruncationStrategy::OnlySecond),
+        3 => Ok(TruncationStrategy::DoNotTruncate),
+        other => Err(FfiError::invalid_input(format!("unknown truncation strategy {}", other))),
+    }
+}
+
+pub struct Tokenizer {
+    tokenizer: TokenizerOption,
+}
+
+impl Tokenizer {
+    pub fn from_files(
+        model_type: ModelType,
+        vocab: &Path,
+        merges: Option<&Path>,
+        lower_case: bool,
+        strip_accents: Option<bool>,
+        add_prefix_space: Option<bool>,
+    ) -> Result<Tokenizer, RustBertError> {
+        let tokenizer = TokenizerOption::from_file(
+            model_type,
+            vocab.to_str().unwrap_or_default(),
+            merges.and_then(|path| path.to_str()),
+            lower_case,
+            strip_accents,
+            add_prefix_space,
+        )?;
+        Ok(Tokenizer { tokenizer })
+    }
+
+    /// Encodes `first`, or the pair of `first` and `second`, with the special tokens of the
+    /// model, truncated to `max_length` tokens. `DoNotTruncate` ignores `max_length`.
+    pub fn encode(
+        &self,
+        first: &str,
+        second: Option<&str>,
+        max_length: usize,
+        truncation: TruncationStrategy,
+        stride: usize,
+    ) -> Encoding {
+        let max_length = match truncation {
+            TruncationStrategy::DoNotTruncate => usize::MAX,
+            _ => max_length,
+        };
+        let texts = match second {
+            Some(second) => vec![first, second],
+            None => vec![first],
+        };
+        let input = match second {
+            Some(second) => self
+                .tokenizer
+                .encode_pair_list(&[(first, second)], max_length, &truncation, stride)
+                .pop(),
+            None => self
+                .tokenizer
+                .encode_list(&[first], max_length, &truncation, stride)
+                .pop(),
+        };
+        input.map(|input| self.encoding(input, &texts)).unwrap_or(Encoding {
+            tokens: Vec::new(),
+            num_truncated: 0,
+            overflowing: Vec::new(),
+        })
+    }
+
+    /// Vocabulary entries of the tokens of `texts` by id. Decoding ids one by one would
+    /// drop the subword markers such as `##` and `Ġ`.
+    fn vocabulary_tokens(&self, texts: &[&str]) -> HashMap<i64, String> {
+        let tokens: Vec<String> = texts.iter().flat_map(|text| self.tokenizer.tokenize(text)).collect();
+        self.tokenizer.convert_tokens_to_ids(&tokens).into_iter().zip(tokens).collect()
+    }
+
+    /// Builds the encoding of `input`, the encoded `texts`. The special tokens added by
+    /// the tokenizer are not in the tokens of `texts` and decode to themselves.
+    fn encoding(&self, input: TokenizedInput, texts: &[&str]) -> Encoding {
+        let vocabulary = self.vocabulary_tokens(texts);
+        let tokens = input
+            .token_ids
+            .iter()
+            .enumerate()
+            .map(|(i, &id)| EncodedToken {
+                id,
+                token: vocabulary
+                    .get(&id)
+                    .cloned()
+                    .unwrap_or_else(|| self.tokenizer.decode(&[id], false, false)),
+                offset: input
+                    .token_offsets
+                    .get(i)
+                    .copied()
+                    .flatten()
+                    .map(|offset| (offset.begin, offset.end)),
+                special: input.special_tokens_mask.get(i).is_some_and(|&mask| mask == 1),
+                segment: input.segment_ids.get(i).copied().unwrap_or(0),
+            })
+            .collect();
+        Encoding {
+            tokens,
+            num_truncated: input.num_truncated_tokens,
+            overflowing: input.overflowing_tokens,
+        }
+    }
+
+    /// Decodes `ids` back to text, dropping special tokens if `skip_special_tokens`.
+    pub fn decode(&self, ids: &[i64], skip_special_tokens: bool) -> String {
+        self.tokenizer.decode(ids, skip_special_tokens, true)
+    }
+}
@@ -0,0 +1,145 @@
+//! Language handling for the translation pipeline.
+//!
+//! Go passes languages as strings. They are matched case-insensitively against the
+//! English name ("German", "Chinese Mandarin"), the ISO 639-1 code ("de") or the
+//! ISO 639-3 code ("deu") of the languages below.
+
+use rust_bert::pipelines::translation::{Language, TranslationModel};
+
+use crate::error::FfiError;
+
+/// Languages accepted by name or code: (language, name, ISO 639-1, ISO 639-3)
+const LANGUAGES: &[(Language, &str, &str, &str)] = &[
+    (Language::Afrikaans, "Afrikaans", "af", "afr"),
+    (Language::Albanian, "Albanian", "sq", "sqi"),
+    (Language::Arabic, "Arabic", "ar", "ara"),
+    (Language::Armenian, "Armenian", "hy", "hye"),
+    (Language::Belarusian, "Belarusian", "be", "bel"),
+    (Language::Bengali, "Bengali", "bn", "ben"),
+    (Language::Bosnian, "Bosnian", "bs", "bos"),
+    (Language::Bulgarian, "Bulgarian", "bg", "bul"),
+    (Language::Catalan, "Catalan", "ca", "cat"),
+    (Language::ChineseMandarin, "Chinese Mandarin", "zh", "zho"),
+    (Language::Croatian, "Croatian", "hr", "hrv"),
+    (Language::Czech, "Czech", "cs", "ces"),
+    (Language::Danish, "Danish", "da", "dan"),
+    (Language::Dutch, "Dutch", "nl", "nld"),
+    (Language::English, "English", "en", "eng"),
+    (Language::Estonian, "Estonian", "et", "est"),
+    (Language::Finnish, "Finnish", "fi", "fin"),
+    (Language::French, "French", "fr", "fra"),
+    (Language::Galician, "Galician", "gl", "glg"),
+    (Language::Georgian, "Georgian", "ka", "kat"),
+    (Language::German, "German", "de", "deu"),
+    (Language::Greek, "Greek", "el", "ell"),
+    (Language::Hebrew, "Hebrew", "he", "heb"),
+    (Language::Hindi, "Hindi", "hi", "hin"),
+    (Language::Hungarian, "Hungarian", "hu", "hun"),
+    (Language::Icelandic, "Icelandic", "is", "isl"),
+    (Language::Indonesian, "Indonesian", "id", "ind"),
+    (Language::Irish, "Irish", "ga", "gle"),
+    (Language::Italian, "Italian", "it", "ita"),
+    (Language::Japanese, "Japanese", "ja", "jpn"),
+    (Language::Korean, "Korean", "ko", "kor"),
+    (Language::Latvian, "Latvian", "lv", "lav"),
+    (Language::Lithuanian, "Lithuanian", "lt", "lit"),
+    (Language::Macedonian, "Macedonian", "mk", "mkd"),
+    (Language::Malay, "Malay", "ms", "msa"),
+    (Language::Norwegian, "Norwegian", "no", "nor"),
+    (Language::Polish, "Polish", "pl", "pol"),
+    (Language::Portuguese, "Portuguese", "pt", "por"),
+    (Language::Romanian, "Romanian", "ro", "ron"),
+    (Language::Russian, "Russian", "ru", "rus"),
+    (Language::Serbian, "Serbian", "sr", "srp"),
+    (Language::Slovak, "Slovak", "sk", "slk"),
+    (Language::Slovenian, "Slovenian", "sl", "slv"),
+    (Language::Spanish, "Spanish", "es", "spa"),
+    (Language::Swahili, "Swahili", "sw", "swa"),
+    (Language::Swedish, "Swedish", "sv", "swe"),
+    (Language::Tagalog, "Tagalog", "tl", "tgl"),
+    (Language::Tamil, "Tamil", "ta", "tam"),
+    (Language::Thai, "Thai", "th", "tha"),
+    (Language::Turkish, "Turkish", "tr", "tur"),
+    (Language::Ukrainian, "Ukrainian", "uk", "ukr"),
+    (Language::Urdu, "Urdu", "ur", "urd"),
+    (Language::Vietnamese, "Vietnamese", "vi", "vie"),
+    (Language::Welsh, "Welsh", "cy", "cym"),
+];
+
+/// Parses a language name or ISO 639-1/639-3 code.
+pub fn parse_language(value: &str) -> Result<Language, FfiError> {
+    let value = value.trim();
+    LANGUAGES
+        .iter()
+        .find(|(_, name, iso1, iso3)| {
+            value.eq_ignore_ascii_case(name)
+                || value.eq_ignore_ascii_case(iso1)
+                || value.eq_ignore_ascii_case(iso3)
+        })
+        .map(|(language, ..)| *language)
+        .ok_or_else(|| FfiError::invalid_input(format!("unknown language '{}'", value)))
+}
+
+/// Name of a language for error messages
+fn language_name(language: Language) -> String {
+    LANGUAGES
+        .iter()
+        .find(|(l, ..)| *l == language)
+        .map(|(_, name, ..)| name.to_string())
+        .unwrap_or_else(|| format!("{:?}", language))
+}
+
+/// Checks that `language` is one of `supported`. An empty set is left to rust-bert.
+fn check_supported(language: Language, supported: &[Language], role: &str) -> Result<(), FfiError> {
+    if supported.is_empty() || supported.contains(&language) {
+        return Ok(());
+    }
+    let names: Vec<String> = supported.iter().map(|l| language_name(*l)).collect();
+    Err(FfiError::invalid_input(format!(
+        "{} language {} is not supported by this model (supported: {})",
+        role,
+        language_name(language),
+        names.join(", ")
+    )))
+}
+
+/// Translation model together with the languages it was built for
+pub struct Translator {
+    pub model: TranslationModel,
+    /// Languages requested at construction. Empty when the builder picked them, in which
+    /// case rust-bert validates the requested pair itself.
+    pub source_languages: Vec<Language>,
+    pub target_languages: Vec<Language>,
+    /// Models loaded from files translate the pair implied by the checkpoint and take no
+    /// language arguments.
+    pub fixed_pair: bool,
+}
+
+impl Translator {
+    /// Translates `texts` between the requested languages (names or codes, `None` or empty
+    /// for the model default). For models with a fixed pair the languages are only checked
+    /// for being valid languages.
+    pub fn translate<S>(&self, texts: &[S], source: Option<&str>, target: Option<&str>) -> Result<Vec<String>, FfiError>
+    where
+        S: AsRef<str> + Send + Sync,
+    {
+        let source = self.requested_language(source, &self.source_languages, "source")?;
+        let target = self.requested_language(target, &self.target_languages, "target")?;
+        self.model
+            .translate(texts, source, target)
+            .map_err(crate::inference_error)
+    }
+
+    fn requested_language(&self, value: Option<&str>, supported: &[Language], role: &str) -> Result<Option<Language>, FfiError> {
+        let value = match value {
+            Some(value) if !value.trim().is_empty() => value,
+            _ => return Ok(None),
+        };
+        let language = parse_language(value)?;
+        if self.fixed_pair {
+            return Ok(None);
+        }
+        check_supported(language, supported, role)?;
+        Ok(Some(language))
+    }
+}
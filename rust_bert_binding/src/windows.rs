@@ -0,0 +1,191 @@
+//! Long inputs split into overlapping token windows.
+//!
+//! The pipelines truncate their inputs to the length the model accepts. For longer
+//! documents the text is cut into windows of `size` tokens, each sharing `stride` tokens
+//! with the previous one, the windows are run as a batch and their outputs merged back
+//! into one result for the whole text.
+
+use rust_bert::pipelines::common::{ModelType, TokenizerOption};
+use rust_bert::pipelines::ner::Entity;
+use rust_bert::pipelines::sentiment::{Sentiment, SentimentPolarity};
+use rust_bert::resources::ResourceProvider;
+use rust_bert::RustBertError;
+
+use crate::encoding::{load_tokenizer, MAX_LENGTH};
+use crate::error::FfiError;
+
+/// Tokens added around each window by the tokenizer, at most a start and an end token.
+const SPECIAL_TOKENS: usize = 2;
+
+/// Options for the long-input functions, mirrored by the Go `WindowConfig`.
+#[repr(C)]
+#[derive(Clone, Copy)]
+pub struct WindowOptions {
+    /// Tokens per window
+    pub size: usize,
+    /// Tokens shared by consecutive windows
+    pub stride: usize,
+    /// 0 mean, 1 max, 2 first; how window scores are combined for classification
+    pub aggregation: i32,
+}
+
+impl WindowOptions {
+    pub fn from_ptr(options: *const WindowOptions) -> Result<WindowOptions, FfiError> {
+        let options = *unsafe { options.as_ref() }.ok_or_else(|| FfiError::invalid_input("window options are NULL"))?;
+        if options.size == 0 || options.size > MAX_LENGTH - SPECIAL_TOKENS {
+            return Err(FfiError::invalid_input(format!(
+                "window size must be between 1 and {}, got {}",
+                MAX_LENGTH - SPECIAL_TOKENS,
+                options.size
+            )));
+        }
+        if options.stride >= options.size {
+            return Err(FfiError::invalid_input(format!(
+                "stride {} must be smaller than the window size {}",
+                options.stride, options.size
+            )));
+        }
+        if !(0..=2).contains(&options.aggregation) {
+            return Err(FfiError::invalid_input(format!(
+                "unknown score aggregation {}",
+                options.aggregation
+            )));
+        }
+        Ok(options)
+    }
+}
+
+/// Slice of a long text
+pub struct Window {
+    pub text: String,
+    /// Character offset of the window in the text
+    pub start: u32,
+}
+
+/// Splits texts along the tokens of the model they are fed to.
+pub struct Windower {
+    tokenizer: TokenizerOption,
+}
+
+impl Windower {
+    /// Loads the tokenizer of the model described by these config resources.
+    pub fn new(
+        model_type: ModelType,
+        vocab: &(dyn ResourceProvider + Send),
+        merges: Option<&(dyn ResourceProvider + Send)>,
+        lower_case: bool,
+        strip_accents: Option<bool>,
+        add_prefix_space: Option<bool>,
+    ) -> Result<Windower, RustBertError> {
+        let tokenizer = load_tokenizer(model_type, vocab, merges, lower_case, strip_accents, add_prefix_space)?;
+        Ok(Windower { tokenizer })
+    }
+
+    /// Cuts `text` into windows of at most `size` tokens overlapping by `stride` tokens.
+    /// A text that fits in one window is returned whole.
+    pub fn split(&self, text: &str, options: &WindowOptions) -> Vec<Window> {
+        let offsets: Vec<_> = self
+            .tokenizer
+            .tokenize_with_offsets(text)
+            .offsets
+            .into_iter()
+            .flatten()
+            .collect();
+        if offsets.len() <= options.size {
+            return vec![Window {
+                text: text.to_string(),
+                start: 0,
+            }];
+        }
+
+        // Token offsets count characters, slicing needs bytes.
+        let bytes: Vec<usize> = text
+            .char_indices()
+            .map(|(i, _)| i)
+            .chain(std::iter::once(text.len()))
+            .collect();
+        let byte = |char_offset: u32| bytes[(char_offset as usize).min(bytes.len() - 1)];
+
+        let mut windows = Vec::new();
+        let mut first = 0;
+        loop {
+            let last = (first + options.size).min(offsets.len());
+            let (begin, end) = (offsets[first].begin, offsets[last - 1].end);
+            windows.push(Window {
+                text: text[byte(begin)..byte(end)].to_string(),
+                start: begin,
+            });
+            if last == offsets.len() {
+                return windows;
+            }
+            first = last - options.stride;
+        }
+    }
+}
+
+/// Moves the entities found in each window to the offsets of the whole text and drops
+/// the duplicates found in the overlaps. Of overlapping entities, the longest is kept so
+/// that an entity cut at the edge of a window gives way to its full span in the next
+/// one, then the most confident.
+pub fn merge_entities(windows: &[Window], entities: Vec<Vec<Entity>>) -> Vec<Entity> {
+    let mut candidates: Vec<Entity> = windows
+        .iter()
+        .zip(entities)
+        .flat_map(|(window, entities)| {
+            entities.into_iter().map(move |mut entity| {
+                entity.offset.begin += window.start;
+                entity.offset.end += window.start;
+                entity
+            })
+        })
+        .collect();
+    candidates.sort_by(|a, b| {
+        let length = |e: &Entity| e.offset.end - e.offset.begin;
+        length(b).cmp(&length(a)).then(b.score.total_cmp(&a.score))
+    });
+
+    let mut merged: Vec<Entity> = Vec::with_capacity(candidates.len());
+    for entity in candidates {
+        let overlaps = merged
+            .iter()
+            .any(|kept| kept.offset.begin < entity.offset.end && entity.offset.begin < kept.offset.end);
+        if !overlaps {
+            merged.push(entity);
+        }
+    }
+    merged.sort_by_key(|entity| entity.offset.begin);
+    merged
+}
+
+/// Combines the sentiments of the windows of a text: 0 averages the probability of the
+/// positive class, 1 keeps the most confident window and 2 the first one.
+pub fn aggregate_sentiments(sentiments: Vec<Sentiment>, aggregation: i32) -> Option<Sentiment> {
+    match aggregation {
+        0 => {
+            if sentiments.is_empty() {
+                return None;
+            }
+            let positive = sentiments.iter().map(positive_probability).sum::<f64>() / sentiments.len() as f64;
+            Some(if positive >= 0.5 {
+                Sentiment {
+                    polarity: SentimentPolarity::Positive,
+                    score: positive,
+                }
+            } else {
+                Sentiment {
+                    polarity: SentimentPolarity::Negative,
+                    score: 1.0 - positive,
+                }
+            })
+        }
+        1 => sentiments.into_iter().max_by(|a, b| a.score.total_cmp(&b.score)),
+        _ => sentiments.into_iter().next(),
+    }
+}
+
+fn positive_probability(sentiment: &Sentiment) -> f64 {
+    match sentiment.polarity {
+        SentimentPolarity::Positive => sentiment.score,
+        SentimentPolarity::Negative => 1.0 - sentiment.score,
+    }
+}
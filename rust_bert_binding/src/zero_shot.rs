@@ -0,0 +1,87 @@
+//! Zero-shot classification with a choice of hypothesis template and label mode.
+
+use libc::c_char;
+use rust_bert::pipelines::sequence_classification::Label;
+use rust_bert::pipelines::zero_shot_classification::{ZeroShotClassificationModel, ZeroShotTemplate};
+use rust_bert::RustBertError;
+
+use crate::error::FfiError;
+use crate::input_string;
+
+/// Placeholder replaced by the label in hypothesis templates
+const LABEL_PLACEHOLDER: &str = "{}";
+
+/// Longest premise and hypothesis pair of the calls without options, in tokens, as in
+/// the Go `DefaultZeroShotOptions`
+pub const MAX_LENGTH: usize = 128;
+
+/// Per-call options, mirrored by the Go `ZeroShotOptions`.
+#[repr(C)]
+pub struct ZeroShotOptions {
+    /// Hypothesis with a "{}" placeholder for the label, NULL for rust-bert's
+    /// "This example is {}."
+    pub template: *const c_char,
+    /// Score every label independently instead of picking the best one
+    pub multi_label: bool,
+    /// Longest premise and hypothesis pair, in tokens
+    pub max_length: usize,
+}
+
+/// Options read from a `ZeroShotOptions`.
+pub struct ZeroShotSettings {
+    template: Option<String>,
+    multi_label: bool,
+    max_length: usize,
+}
+
+impl ZeroShotSettings {
+    pub fn from_ptr(options: *const ZeroShotOptions) -> Result<ZeroShotSettings, FfiError> {
+        let options = unsafe { options.as_ref() }.ok_or_else(|| FfiError::invalid_input("zero-shot options are NULL"))?;
+        let template = if options.template.is_null() {
+            None
+        } else {
+            Some(input_string(options.template, "template")?)
+        };
+        if let Some(template) = &template {
+            if !template.contains(LABEL_PLACEHOLDER) {
+                return Err(FfiError::invalid_input(format!(
+                    "template {:?} has no {} placeholder for the label",
+                    template, LABEL_PLACEHOLDER
+                )));
+            }
+        }
+        if options.max_length == 0 {
+            return Err(FfiError::invalid_input("max length must be at least 1"));
+        }
+        Ok(ZeroShotSettings {
+            template,
+            multi_label: options.multi_label,
+            max_length: options.max_length,
+        })
+    }
+
+    fn template(&self) -> Option<ZeroShotTemplate> {
+        self.template.clone().map(|template| -> ZeroShotTemplate {
+            Box::new(move |label: &str| template.replace(LABEL_PLACEHOLDER, label))
+        })
+    }
+
+    /// Classifies `texts` against `labels`: the best label of each text, or every label
+    /// best first in multi-label mode.
+    pub fn classify(
+        &self,
+        model: &ZeroShotClassificationModel,
+        texts: &[&str],
+        labels: &[&str],
+    ) -> Result<Vec<Vec<Label>>, RustBertError> {
+        if !self.multi_label {
+            let best = model.predict(texts, labels, self.template(), self.max_length)?;
+            return Ok(best.into_iter().map(|label| vec![label]).collect());
+        }
+        let mut scores = model.predict_multilabel(texts, labels, self.template(), self.max_length)?;
+        for labels in scores.iter_mut() {
+            labels.sort_by(|a, b| b.score.total_cmp(&a.score));
+        }
+        Ok(scores)
+    }
+}